@@ -141,6 +141,10 @@ const (
 	// ConditionTypeAuthError describes an error condition related to Auth.
 	ConditionTypeAuthError = "AuthError"
 
+	// ConditionTypeBasicAuthError describes an error condition
+	// related to HTTP basic authentication.
+	ConditionTypeBasicAuthError = "BasicAuthError"
+
 	// ConditionTypeCORSError describes an error condition related to CORS.
 	ConditionTypeCORSError = "CORSError"
 
@@ -148,6 +152,14 @@ const (
 	// inclusion of another HTTPProxy resource.
 	ConditionTypeIncludeError = "IncludeError"
 
+	// ConditionTypeIPFilterError describes an error condition
+	// related to IP filtering.
+	ConditionTypeIPFilterError = "IPFilterError"
+
+	// ConditionTypeJWTVerificationError describes an error condition
+	// related to JWT verification.
+	ConditionTypeJWTVerificationError = "JWTVerificationError"
+
 	// ConditionTypeOrphanedError describes an error condition
 	// with an HTTPProxy resource which is not part of a delegation chain.
 	ConditionTypeOrphanedError = "Orphaned"
@@ -49,21 +49,37 @@ type Include struct {
 	// When applied, they are merged using AND, with one exception:
 	// There can be only one Prefix MatchCondition per Conditions slice.
 	// More than one Prefix, or contradictory Conditions, will make the
-	// include invalid.
+	// include invalid. Exact and Regex conditions are not allowed on
+	// includes.
 	// +optional
 	Conditions []MatchCondition `json:"conditions,omitempty"`
 }
 
 // MatchCondition are a general holder for matching rules for HTTPProxies.
-// One of Prefix or Header must be provided.
+// One of Prefix, Exact, Regex, Header or QueryParameter must be provided.
 type MatchCondition struct {
 	// Prefix defines a prefix match for a request.
 	// +optional
 	Prefix string `json:"prefix,omitempty"`
 
+	// Exact defines an exact match for a request.
+	// This field is not allowed in include match conditions.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Regex defines a regex match for a request.
+	// The regex must use RE2 syntax and match the entire path.
+	// This field is not allowed in include match conditions.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
 	// Header specifies the header condition to match.
 	// +optional
 	Header *HeaderMatchCondition `json:"header,omitempty"`
+
+	// QueryParameter specifies the query parameter condition to match.
+	// +optional
+	QueryParameter *QueryParameterMatchCondition `json:"queryParameter,omitempty"`
 }
 
 // HeaderMatchCondition specifies how to conditionally match against HTTP
@@ -99,6 +115,46 @@ type HeaderMatchCondition struct {
 	// equal to. The condition is true if the header has any other value.
 	// +optional
 	NotExact string `json:"notexact,omitempty"`
+
+	// Regex specifies a regular expression that the header value must
+	// match. The regex must use RE2 syntax and match the entire value.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// NotRegex specifies a regular expression that the header value must
+	// not match. The condition is true if the header has any other value.
+	// +optional
+	NotRegex string `json:"notregex,omitempty"`
+}
+
+// QueryParameterMatchCondition specifies how to conditionally match against
+// HTTP query parameters. The Name field is required, but only one of the
+// remaining fields should be provided.
+type QueryParameterMatchCondition struct {
+	// Name is the name of the query parameter to match against. Name is required.
+	// Query parameter names are case sensitive.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Exact specifies a string that the query parameter value must be equal to.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Prefix specifies a string that the query parameter value must start with.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Regex specifies a regular expression that the query parameter value
+	// must match. The regex must use RE2 syntax and match the entire value.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// Present specifies that condition is true when the named query parameter
+	// is present, regardless of its value. Note that setting Present
+	// to false does not make the condition true if the named query parameter
+	// is absent.
+	// +optional
+	Present bool `json:"present,omitempty"`
 }
 
 // ExtensionServiceReference names an ExtensionService resource.
@@ -159,6 +215,105 @@ type AuthorizationServer struct {
 	//
 	// +optional
 	FailOpen bool `json:"failOpen,omitempty"`
+
+	// WithRequestBody configures the proxy to buffer the body of client
+	// requests and send it to the authorization server in the check request.
+	// If not specified, the request body is not sent.
+	//
+	// +optional
+	WithRequestBody *AuthorizationServerBufferSettings `json:"withRequestBody,omitempty"`
+
+	// AllowedRequestHeaders lists the client request headers that are
+	// sent to the authorization server in the check request. Header
+	// names are matched case-insensitively. The Authorization, Host,
+	// Method, Path and Content-Length headers are always sent. This
+	// field is only supported by HTTP authorization servers, which
+	// are sent no other headers if it is not specified.
+	//
+	// +optional
+	AllowedRequestHeaders []string `json:"allowedRequestHeaders,omitempty"`
+
+	// AllowedUpstreamHeaders lists the headers of authorization server
+	// responses that are added to client requests that are allowed.
+	// Header names are matched case-insensitively. This field is only
+	// supported by HTTP authorization servers.
+	//
+	// +optional
+	AllowedUpstreamHeaders []string `json:"allowedUpstreamHeaders,omitempty"`
+
+	// AllowedClientHeaders lists the headers of authorization server
+	// responses that are sent to clients whose requests are denied.
+	// Header names are matched case-insensitively. This field is only
+	// supported by HTTP authorization servers, which return all their
+	// response headers to clients if it is not specified.
+	//
+	// +optional
+	AllowedClientHeaders []string `json:"allowedClientHeaders,omitempty"`
+
+	// StatusOnDeny is the HTTP status code that is returned to clients
+	// whose requests are denied, instead of 403 (Forbidden). It does not
+	// replace any other status code that the authorization server sets
+	// in its response, or the 403 status of requests that are rejected
+	// because the authorization server failed. Envoy cannot tell an
+	// explicit 403 from the authorization server from the default, so
+	// it is replaced too. Codes must be in the 400-599 range (inclusive).
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=400
+	// +kubebuilder:validation:Maximum=599
+	StatusOnDeny uint32 `json:"statusOnDeny,omitempty"`
+
+	// IncludeClientCertificate specifies whether the certificate that
+	// the client presented on the TLS connection is sent to the
+	// authorization server in the check request. Defaults to true.
+	// HTTP authorization servers are never sent the certificate.
+	//
+	// +optional
+	IncludeClientCertificate *bool `json:"includeClientCertificate,omitempty"`
+
+	// HTTPService configures the authorization server to be sent
+	// plain HTTP requests, in the style of a "forward auth" proxy,
+	// instead of gRPC check requests. Requests are allowed if the
+	// authorization server responds with 200 (OK). Otherwise, the
+	// response of the authorization server is returned to the client.
+	//
+	// +optional
+	HTTPService *AuthorizationHTTPService `json:"httpService,omitempty"`
+}
+
+// AuthorizationHTTPService configures an authorization server that
+// accepts plain HTTP requests.
+type AuthorizationHTTPService struct {
+	// PathPrefix is prepended to the path of the client request to
+	// form the path of the request to the authorization server.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	PathPrefix string `json:"pathPrefix,omitempty"`
+}
+
+// AuthorizationServerBufferSettings configures how the body of client
+// requests is buffered and sent to an authorization server.
+type AuthorizationServerBufferSettings struct {
+	// MaxRequestBytes sets the maximum size of the request body, in
+	// bytes, that is buffered and sent to the authorization server.
+	//
+	// +kubebuilder:validation:Minimum=1
+	MaxRequestBytes uint32 `json:"maxRequestBytes"`
+
+	// AllowPartialMessage sends the first MaxRequestBytes of a larger
+	// request body to the authorization server. Otherwise, requests
+	// with a larger body are rejected with 413 (Payload Too Large).
+	//
+	// +optional
+	AllowPartialMessage bool `json:"allowPartialMessage,omitempty"`
+
+	// PackAsBytes sends the request body to the authorization server
+	// as raw bytes instead of a UTF-8 string. Use this for requests
+	// with binary bodies.
+	//
+	// +optional
+	PackAsBytes bool `json:"packAsBytes,omitempty"`
 }
 
 // AuthorizationPolicy modifies how client requests are authenticated.
@@ -209,6 +364,198 @@ type VirtualHost struct {
 	// The policy for rate limiting on the virtual host.
 	// +optional
 	RateLimitPolicy *RateLimitPolicy `json:"rateLimitPolicy,omitempty"`
+	// The policy for managing request headers on all routes of the
+	// virtual host. If a header is also set by a route or service,
+	// the value set on the virtual host is used.
+	// Rewriting the 'Host' header is not supported.
+	// +optional
+	RequestHeadersPolicy *HeadersPolicy `json:"requestHeadersPolicy,omitempty"`
+	// The policy for managing response headers on all routes of the
+	// virtual host. If a header is also set by a route or service,
+	// the value set on the virtual host is used.
+	// Rewriting the 'Host' header is not supported.
+	// +optional
+	ResponseHeadersPolicy *HeadersPolicy `json:"responseHeadersPolicy,omitempty"`
+	// IPAllowFilterPolicy is a list of IP filter rules for which matching
+	// requests are allowed. All other requests are denied with a 403.
+	// Only one of IPAllowFilterPolicy and IPDenyFilterPolicy may be
+	// specified. The rules may be overridden by a route.
+	// +optional
+	IPAllowFilterPolicy []IPFilterPolicy `json:"ipAllowPolicy,omitempty"`
+	// IPDenyFilterPolicy is a list of IP filter rules for which matching
+	// requests are denied with a 403. All other requests are allowed.
+	// Only one of IPAllowFilterPolicy and IPDenyFilterPolicy may be
+	// specified. The rules may be overridden by a route.
+	// +optional
+	IPDenyFilterPolicy []IPFilterPolicy `json:"ipDenyPolicy,omitempty"`
+	// JWTProviders defines how to verify JWTs on requests to the
+	// virtual host. JWT verification can only be configured on
+	// virtual hosts that have TLS enabled.
+	// +optional
+	JWTProviders []JWTProvider `json:"jwtProviders,omitempty"`
+	// BasicAuth configures HTTP basic authentication for all routes
+	// of the virtual host. The policy may be overridden or disabled
+	// by a route. Basic authentication can only be configured on
+	// virtual hosts that have TLS enabled.
+	// +optional
+	BasicAuth *BasicAuthPolicy `json:"basicAuth,omitempty"`
+}
+
+// IPFilterSource indicates which IP address a filter rule is matched against.
+// +kubebuilder:validation:Enum=Remote;Peer
+type IPFilterSource string
+
+const (
+	// IPFilterSourceRemote matches the IP address of the client, as
+	// determined from the X-Forwarded-For header or PROXY protocol,
+	// respecting the configured number of trusted hops.
+	IPFilterSourceRemote IPFilterSource = "Remote"
+
+	// IPFilterSourcePeer matches the IP address of the directly
+	// connected peer, ignoring X-Forwarded-For and PROXY protocol.
+	IPFilterSourcePeer IPFilterSource = "Peer"
+)
+
+// IPFilterPolicy defines an IP filter rule.
+type IPFilterPolicy struct {
+	// Source indicates which IP address the rule is matched against:
+	//
+	// - `Remote` matches the IP address of the client, as determined from
+	// the X-Forwarded-For header or PROXY protocol.
+	// - `Peer` matches the IP address of the directly connected peer.
+	Source IPFilterSource `json:"source"`
+
+	// CIDR is an IPv4 or IPv6 CIDR block to match. A bare IP address
+	// without a prefix length matches exactly that address.
+	CIDR string `json:"cidr"`
+}
+
+// JWTProvider defines how to verify JWTs on requests.
+type JWTProvider struct {
+	// Unique name for the provider.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Whether the provider should apply to all
+	// routes in the HTTPProxy/its includes by
+	// default. At most one provider can be marked
+	// as the default. If no provider is marked
+	// as the default, individual routes must explicitly
+	// identify the provider they require.
+	// +optional
+	Default bool `json:"default,omitempty"`
+
+	// Issuer that JWTs are required to have in the "iss" field.
+	// If not provided, JWT issuers are not checked.
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Audiences that JWTs are allowed to have in the "aud" field.
+	// If not provided, JWT audiences are not checked.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+
+	// Remote JWKS to use for verifying JWT signatures.
+	// Exactly one of RemoteJWKS and LocalJWKS must be specified.
+	// +optional
+	RemoteJWKS *RemoteJWKS `json:"remoteJWKS,omitempty"`
+
+	// Inline JWKS to use for verifying JWT signatures.
+	// Exactly one of RemoteJWKS and LocalJWKS must be specified.
+	// +optional
+	LocalJWKS string `json:"localJWKS,omitempty"`
+
+	// Whether the JWT should be forwarded to the backend
+	// service after successful verification. By default,
+	// the JWT is not forwarded.
+	// +optional
+	ForwardJWT bool `json:"forwardJWT,omitempty"`
+
+	// ClaimsToHeaders is a list of claims from a verified JWT
+	// that are forwarded to the backend service as request
+	// headers. Any existing value of these headers on the
+	// client request is removed.
+	// +optional
+	ClaimsToHeaders []JWTClaimToHeader `json:"claimsToHeaders,omitempty"`
+}
+
+// RemoteJWKS defines how to fetch a JWKS from an HTTP endpoint.
+type RemoteJWKS struct {
+	// The URI for the JWKS. The scheme must be http or https.
+	// +kubebuilder:validation:MinLength=1
+	URI string `json:"uri"`
+
+	// How long to wait for a response from the URI.
+	// If not specified, a default of 1s applies.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	Timeout string `json:"timeout,omitempty"`
+
+	// How long to cache the JWKS locally. If not specified,
+	// Envoy's default of 5m applies.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	CacheDuration string `json:"cacheDuration,omitempty"`
+
+	// UpstreamValidation defines how to verify the JWKS's TLS certificate.
+	// It may only be specified when the URI scheme is https.
+	// +optional
+	UpstreamValidation *UpstreamValidation `json:"validation,omitempty"`
+}
+
+// JWTClaimToHeader forwards a claim of a verified JWT to the
+// backend service as a request header.
+type JWTClaimToHeader struct {
+	// Claim is the name of a top level claim of the JWT payload.
+	// +kubebuilder:validation:MinLength=1
+	Claim string `json:"claim"`
+
+	// Header is the name of the request header that the claim
+	// value is forwarded in.
+	// +kubebuilder:validation:MinLength=1
+	Header string `json:"header"`
+}
+
+// JWTVerificationPolicy defines whether and how JWTs are verified
+// on requests that match a route.
+type JWTVerificationPolicy struct {
+	// Require names a specific JWT provider (defined in the virtual host)
+	// to require for the route. If specified, this field overrides the
+	// default provider if one exists. If this field is not specified,
+	// the default provider will be required if one exists. At most one of
+	// this field or the "disabled" field can be specified.
+	// +optional
+	Require string `json:"require,omitempty"`
+
+	// Disabled defines whether to disable all JWT verification for this
+	// route. This can be used to opt specific routes out of the default
+	// JWT provider for the HTTPProxy. At most one of this field or the
+	// "require" field can be specified.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// BasicAuthPolicy configures HTTP basic authentication using
+// credentials stored in a Kubernetes Secret.
+type BasicAuthPolicy struct {
+	// SecretName is the name of a Secret that contains htpasswd
+	// formatted credentials in the "auth" key. Passwords must be
+	// hashed with bcrypt or SHA1. The Secret may be in another
+	// namespace if it is specified as "namespace/name" and the
+	// namespace has delegated it with a TLSCertificateDelegation.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Realm is the authentication realm that is presented to
+	// clients. If not specified, the virtual host FQDN is used.
+	// +optional
+	Realm string `json:"realm,omitempty"`
+
+	// Disabled defines whether to disable basic authentication for
+	// this route. It can only be specified on a route, in which case
+	// SecretName and Realm must not be set.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
 }
 
 // TLS describes tls properties. The SNI names that will be matched on
@@ -235,7 +582,8 @@ type TLS struct {
 	// This setting:
 	//
 	// 1. Enables TLS client certificate validation.
-	// 2. Requires clients to present a TLS certificate (i.e. not optional validation).
+	// 2. Requires clients to present a TLS certificate, unless
+	//    OptionalClientCertificate is set.
 	// 3. Specifies how the client certificate will be validated.
 	// +optional
 	ClientValidation *DownstreamValidation `json:"clientValidation,omitempty"`
@@ -280,15 +628,16 @@ type CORSPolicy struct {
 type Route struct {
 	// Conditions are a set of rules that are applied to a Route.
 	// When applied, they are merged using AND, with one exception:
-	// There can be only one Prefix MatchCondition per Conditions slice.
-	// More than one Prefix, or contradictory Conditions, will make the
-	// route invalid.
+	// There can be only one Prefix, Exact or Regex MatchCondition
+	// per Conditions slice. More than one path condition, or
+	// contradictory Conditions, will make the route invalid.
 	// +optional
 	Conditions []MatchCondition `json:"conditions,omitempty"`
 	// Services are the services to proxy traffic.
-	// +kubebuilder:validation:MinItems=1
-	// +kubebuilder:validation:Required
-	Services []Service `json:"services"`
+	// Services must not be specified if RequestRedirectPolicy or
+	// DirectResponsePolicy is.
+	// +optional
+	Services []Service `json:"services,omitempty"`
 	// Enables websocket support for the route.
 	// +optional
 	EnableWebsockets bool `json:"enableWebsockets,omitempty"`
@@ -301,6 +650,14 @@ type Route struct {
 	// match this route.
 	// +optional
 	AuthPolicy *AuthorizationPolicy `json:"authPolicy,omitempty"`
+	// Authorization configures an external server to authorize
+	// client requests that match this route, instead of the
+	// authorization server of the virtual host. It can only be
+	// specified on routes of virtual hosts that have TLS enabled.
+	// The authPolicy field of the authorization server is not
+	// supported here, use the authPolicy field of the route instead.
+	// +optional
+	Authorization *AuthorizationServer `json:"authorization,omitempty"`
 	// The timeout policy for this route.
 	// +optional
 	TimeoutPolicy *TimeoutPolicy `json:"timeoutPolicy,omitempty"`
@@ -328,6 +685,178 @@ type Route struct {
 	// The policy for rate limiting on the route.
 	// +optional
 	RateLimitPolicy *RateLimitPolicy `json:"rateLimitPolicy,omitempty"`
+	// The policy for injecting delays and aborts into requests
+	// that match the route.
+	// +optional
+	FaultInjectionPolicy *FaultInjectionPolicy `json:"faultInjectionPolicy,omitempty"`
+	// IPAllowFilterPolicy is a list of IP filter rules for which matching
+	// requests are allowed. All other requests are denied with a 403.
+	// Only one of IPAllowFilterPolicy and IPDenyFilterPolicy may be
+	// specified. If specified, the IP filter policies of the virtual
+	// host are not applied to the route.
+	// +optional
+	IPAllowFilterPolicy []IPFilterPolicy `json:"ipAllowPolicy,omitempty"`
+	// IPDenyFilterPolicy is a list of IP filter rules for which matching
+	// requests are denied with a 403. All other requests are allowed.
+	// Only one of IPAllowFilterPolicy and IPDenyFilterPolicy may be
+	// specified. If specified, the IP filter policies of the virtual
+	// host are not applied to the route.
+	// +optional
+	IPDenyFilterPolicy []IPFilterPolicy `json:"ipDenyPolicy,omitempty"`
+	// The policy for verifying JWTs for requests to this route.
+	// +optional
+	JWTVerificationPolicy *JWTVerificationPolicy `json:"jwtVerificationPolicy,omitempty"`
+	// The policy for HTTP basic authentication of requests to this
+	// route. If specified, it overrides the basic authentication
+	// policy of the virtual host.
+	// +optional
+	BasicAuth *BasicAuthPolicy `json:"basicAuth,omitempty"`
+	// RequestRedirectPolicy defines an HTTP redirection that is returned
+	// to clients instead of proxying the request to Services.
+	// Only one of Services, RequestRedirectPolicy or DirectResponsePolicy
+	// may be specified.
+	// +optional
+	RequestRedirectPolicy *HTTPRequestRedirectPolicy `json:"requestRedirectPolicy,omitempty"`
+	// DirectResponsePolicy defines an HTTP response that is returned
+	// to clients instead of proxying the request to Services.
+	// Only one of Services, RequestRedirectPolicy or DirectResponsePolicy
+	// may be specified.
+	// +optional
+	DirectResponsePolicy *HTTPDirectResponsePolicy `json:"directResponsePolicy,omitempty"`
+}
+
+// FaultInjectionPolicy defines the faults that are injected into
+// requests to test the resilience of clients and services.
+type FaultInjectionPolicy struct {
+	// Delay defines a fixed delay that is applied to requests
+	// before they are proxied.
+	// +optional
+	Delay *FaultDelay `json:"delay,omitempty"`
+
+	// Abort defines an error response that is returned to clients
+	// instead of proxying requests.
+	// +optional
+	Abort *FaultAbort `json:"abort,omitempty"`
+
+	// Headers restricts fault injection to requests that match all
+	// of the given header conditions. If not specified, faults are
+	// injected into all requests that match the route.
+	// +optional
+	Headers []HeaderMatchCondition `json:"headers,omitempty"`
+}
+
+// FaultDelay defines a fixed delay fault.
+type FaultDelay struct {
+	// Duration is the length of the delay, in duration format
+	// (for example "500ms" or "2s").
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	Duration string `json:"duration"`
+
+	// Percentage is the percentage of requests that are delayed.
+	// If omitted or zero, all requests are delayed.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percentage int64 `json:"percentage,omitempty"`
+}
+
+// FaultAbort defines an abort fault.
+type FaultAbort struct {
+	// StatusCode is the HTTP status code returned to clients
+	// for aborted requests.
+	// +kubebuilder:validation:Minimum=400
+	// +kubebuilder:validation:Maximum=599
+	StatusCode int `json:"statusCode"`
+
+	// Percentage is the percentage of requests that are aborted.
+	// If omitted or zero, all requests are aborted.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percentage int64 `json:"percentage,omitempty"`
+}
+
+// HTTPDirectResponsePolicy defines a fixed HTTP response.
+type HTTPDirectResponsePolicy struct {
+	// StatusCode is the HTTP status code to be used in the response.
+	// +kubebuilder:validation:Minimum=200
+	// +kubebuilder:validation:Maximum=599
+	StatusCode int `json:"statusCode"`
+
+	// Body is the content of the response body.
+	// The body must not be larger than 4096 bytes.
+	// Only one of Body or BodyFromConfigMap may be specified.
+	// +optional
+	// +kubebuilder:validation:MaxLength=4096
+	Body string `json:"body,omitempty"`
+
+	// BodyFromConfigMap selects a key of a ConfigMap in the same
+	// namespace as the HTTPProxy whose value is used as the response body.
+	// The value must not be larger than 4096 bytes.
+	// Only one of Body or BodyFromConfigMap may be specified.
+	// +optional
+	BodyFromConfigMap *ConfigMapKeyReference `json:"bodyFromConfigMap,omitempty"`
+}
+
+// ConfigMapKeyReference selects a key of a ConfigMap.
+type ConfigMapKeyReference struct {
+	// Name is the name of the ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key is the key of the ConfigMap to select.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// HTTPRequestRedirectPolicy defines an HTTP redirection.
+type HTTPRequestRedirectPolicy struct {
+	// Scheme is the scheme to be used in the value of the `Location`
+	// header in the response.
+	// When empty, the scheme of the request is used.
+	// +optional
+	// +kubebuilder:validation:Enum=http;https
+	Scheme *string `json:"scheme,omitempty"`
+
+	// Hostname is the precise hostname to be used in the value of the `Location`
+	// header in the response.
+	// When empty, the hostname of the request is used.
+	// No wildcards are allowed.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	Hostname *string `json:"hostname,omitempty"`
+
+	// Port is the port to be used in the value of the `Location`
+	// header in the response.
+	// When empty, the port (if specified) of the request is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// StatusCode is the HTTP status code to be used in the response.
+	// +optional
+	// +kubebuilder:default=302
+	// +kubebuilder:validation:Enum=301;302;307;308
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Path replaces the whole path of the request in the value of
+	// the `Location` header in the response.
+	// The path must start with a leading slash.
+	// Only one of Path or Prefix may be specified.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^\/.*$`
+	Path *string `json:"path,omitempty"`
+
+	// Prefix replaces the matched prefix (or path) of the request in
+	// the value of the `Location` header in the response.
+	// The prefix must start with a leading slash.
+	// Only one of Path or Prefix may be specified.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^\/.*$`
+	Prefix *string `json:"prefix,omitempty"`
 }
 
 // RateLimitPolicy defines rate limiting parameters.
@@ -538,7 +1067,15 @@ type Service struct {
 	// +optional
 	UpstreamValidation *UpstreamValidation `json:"validation,omitempty"`
 	// If Mirror is true the Service will receive a read only mirror of the traffic for this route.
+	// More than one Service per route may be a mirror.
 	Mirror bool `json:"mirror,omitempty"`
+	// MirrorPercent is the percentage of the traffic for this route that is
+	// mirrored to the Service. It may only be set if Mirror is true.
+	// If omitted or zero, all traffic is mirrored.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MirrorPercent int64 `json:"mirrorPercent,omitempty"`
 	// The policy for managing request headers during proxying.
 	// Rewriting the 'Host' header is not supported.
 	// +optional
@@ -547,6 +1084,15 @@ type Service struct {
 	// Rewriting the 'Host' header is not supported.
 	// +optional
 	ResponseHeadersPolicy *HeadersPolicy `json:"responseHeadersPolicy,omitempty"`
+	// OutlierDetection defines how hosts of the Service are passively
+	// health checked and ejected from the load balancing pool.
+	// +optional
+	OutlierDetection *OutlierDetection `json:"outlierDetection,omitempty"`
+	// CircuitBreakerPolicy defines the circuit breaking thresholds for
+	// the Service. Thresholds set here take precedence over those set
+	// by annotations on the Kubernetes Service.
+	// +optional
+	CircuitBreakerPolicy *CircuitBreakerPolicy `json:"circuitBreakerPolicy,omitempty"`
 }
 
 // HTTPHealthCheckPolicy defines health checks on the upstream service.
@@ -589,6 +1135,81 @@ type TCPHealthCheckPolicy struct {
 	HealthyThresholdCount uint32 `json:"healthyThresholdCount"`
 }
 
+// OutlierDetection defines the passive health checking of the hosts
+// of an upstream service. Hosts that fail too often are ejected from
+// the load balancing pool for a period of time.
+type OutlierDetection struct {
+	// ConsecutiveServerErrors is the number of consecutive 5xx responses
+	// after which a host is ejected. For TCPProxy services, connection
+	// failures are counted instead. If not specified, the Envoy default
+	// of 5 is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ConsecutiveServerErrors int64 `json:"consecutiveServerErrors,omitempty"`
+
+	// ConsecutiveGatewayErrors is the number of consecutive 502, 503
+	// or 504 responses after which a host is ejected. If not specified,
+	// hosts are not ejected for gateway errors alone.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ConsecutiveGatewayErrors int64 `json:"consecutiveGatewayErrors,omitempty"`
+
+	// Interval is the time between ejection sweeps, in duration format.
+	// If not specified, the Envoy default of 10s is used.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	Interval string `json:"interval,omitempty"`
+
+	// BaseEjectionTime is the base time that a host is ejected for, in
+	// duration format. The actual ejection time is the base time
+	// multiplied by the number of times the host has been ejected.
+	// If not specified, the Envoy default of 30s is used.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	BaseEjectionTime string `json:"baseEjectionTime,omitempty"`
+
+	// MaxEjectionPercent is the maximum percentage of the hosts of the
+	// Service that can be ejected at the same time. If not specified,
+	// the Envoy default of 10 is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxEjectionPercent int64 `json:"maxEjectionPercent,omitempty"`
+}
+
+// CircuitBreakerPolicy defines the circuit breaking thresholds for
+// an upstream service. Thresholds that are not specified fall back
+// to the Service annotations, and then to the Contour defaults.
+type CircuitBreakerPolicy struct {
+	// MaxConnections is the maximum number of connections that Envoy
+	// will make to the upstream service.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	MaxConnections int64 `json:"maxConnections,omitempty"`
+
+	// MaxPendingRequests is the maximum number of pending requests that
+	// Envoy will allow to the upstream service.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	MaxPendingRequests int64 `json:"maxPendingRequests,omitempty"`
+
+	// MaxRequests is the maximum number of parallel requests that
+	// Envoy will make to the upstream service.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	MaxRequests int64 `json:"maxRequests,omitempty"`
+
+	// MaxRetries is the maximum number of parallel retries that
+	// Envoy will allow to the upstream service.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	MaxRetries int64 `json:"maxRetries,omitempty"`
+}
+
 // TimeoutPolicy configures timeouts that are used for handling network requests.
 //
 // TimeoutPolicy durations are expressed in the Go [Duration format](https://godoc.org/time#ParseDuration).
@@ -656,8 +1277,58 @@ type RetryPolicy struct {
 	// This field is only respected when you include `retriable-status-codes` in the `RetryOn` field.
 	// +optional
 	RetriableStatusCodes []uint32 `json:"retriableStatusCodes,omitempty"`
+	// RetriableHeaders specifies the upstream response headers that should
+	// be retried. A response is retried if any of the header conditions match.
+	//
+	// This field is only respected when you include `retriable-headers` in the `RetryOn` field.
+	// +optional
+	RetriableHeaders []HeaderMatchCondition `json:"retriableHeaders,omitempty"`
+	// RetriableRequestHeaders specifies the request headers that must match
+	// for a request to be retried. A request is retried only if at least one
+	// of the header conditions matches. If not supplied, all requests are
+	// eligible for retry.
+	// +optional
+	RetriableRequestHeaders []HeaderMatchCondition `json:"retriableRequestHeaders,omitempty"`
+	// RetryBackoff specifies the exponential backoff between retries.
+	// If not supplied, the Envoy default base interval of 25ms is used.
+	// +optional
+	RetryBackoff *RetryBackoff `json:"retryBackoff,omitempty"`
+	// RetryHostPredicate specifies the predicates used to reject upstream
+	// hosts when selecting the host for a retry.
+	//
+	// Supported predicates:
+	//
+	// - `previous-hosts`: reject hosts that have already been attempted.
+	// - `omit-canary-hosts`: reject hosts that are marked as canary hosts.
+	// +optional
+	RetryHostPredicate []RetryHostPredicate `json:"retryHostPredicate,omitempty"`
+	// HostSelectionRetryMaxAttempts is the maximum number of times that
+	// a host is selected for a retry attempt when the selected host is
+	// rejected by a RetryHostPredicate. If not supplied, the host is
+	// only selected once.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	HostSelectionRetryMaxAttempts int64 `json:"hostSelectionRetryMaxAttempts,omitempty"`
 }
 
+// RetryBackoff defines the exponential backoff between retry attempts.
+type RetryBackoff struct {
+	// BaseInterval is the base interval between retries. The interval
+	// grows exponentially with each retry, with random jitter.
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	BaseInterval string `json:"baseInterval"`
+	// MaxInterval is the maximum interval between retries. It must be
+	// greater than or equal to the BaseInterval. If not supplied, it
+	// defaults to ten times the BaseInterval.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	MaxInterval string `json:"maxInterval,omitempty"`
+}
+
+// RetryHostPredicate is a string type alias with validation to ensure that the value is valid.
+// +kubebuilder:validation:Enum=previous-hosts;omit-canary-hosts
+type RetryHostPredicate string
+
 // ReplacePrefix describes a path prefix replacement.
 type ReplacePrefix struct {
 	// Prefix specifies the URL path prefix to be replaced.
@@ -694,6 +1365,39 @@ type PathRewritePolicy struct {
 	// ReplacePrefix describes how the path prefix should be replaced.
 	// +optional
 	ReplacePrefix []ReplacePrefix `json:"replacePrefix,omitempty"`
+
+	// RegexRewrite describes how the path should be rewritten
+	// using a regular expression.
+	// +optional
+	RegexRewrite *RegexRewrite `json:"regexRewrite,omitempty"`
+
+	// ReplaceFullPath replaces the whole path of the request,
+	// including any prefix rendered by the chain of including
+	// HTTPProxies. The path must start with a leading slash.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^\/.*$`
+	ReplaceFullPath *string `json:"replaceFullPath,omitempty"`
+}
+
+// RegexRewrite describes a regular expression path rewrite.
+type RegexRewrite struct {
+	// Pattern is the RE2 regular expression that is matched against
+	// the request path. Every match is replaced by Substitution.
+	//
+	// If Pattern is anchored with `^`, it is matched against the
+	// path following the prefix that is rendered by the chain of
+	// including HTTPProxies, and that prefix is preserved in the
+	// rewritten path.
+	//
+	// +kubebuilder:validation:MinLength=1
+	Pattern string `json:"pattern"`
+
+	// Substitution is the string that replaces each match of
+	// Pattern. Capture groups of Pattern may be referenced
+	// as `\1` to `\9`.
+	//
+	// +optional
+	Substitution string `json:"substitution,omitempty"`
 }
 
 // HeaderHashOptions contains options to configure a HTTP request header hash
@@ -707,6 +1411,41 @@ type HeaderHashOptions struct {
 	HeaderName string `json:"headerName,omitempty"`
 }
 
+// QueryParameterHashOptions contains options to configure a query parameter based hash
+// policy, used in request attribute hash based load balancing.
+type QueryParameterHashOptions struct {
+	// ParameterName is the name of the HTTP request query parameter that will be used to
+	// calculate the hash key. If the query parameter specified is not present on a
+	// request, no hash will be produced.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ParameterName string `json:"parameterName,omitempty"`
+}
+
+// CookieHashOptions contains options to configure a HTTP request cookie
+// hash policy, used in cookie based session affinity and request
+// attribute hash based load balancing.
+type CookieHashOptions struct {
+	// CookieName is the name of the HTTP request cookie that will be used
+	// to calculate the hash key. If the cookie is not present on a request,
+	// Envoy generates it. Defaults to `X-Contour-Session-Affinity`.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	CookieName string `json:"cookieName,omitempty"`
+
+	// TTL is the lifetime of the cookie generated by Envoy. If not
+	// supplied or zero, a session cookie is generated.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	TTL string `json:"ttl,omitempty"`
+
+	// Path is the path attribute of the cookie generated by Envoy.
+	// Defaults to `/`.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^\/.*$`
+	Path string `json:"path,omitempty"`
+}
+
 // RequestHashPolicy contains configuration for an individual hash policy
 // on a request attribute.
 type RequestHashPolicy struct {
@@ -719,8 +1458,26 @@ type RequestHashPolicy struct {
 	// HeaderHashOptions should be set when request header hash based load
 	// balancing is desired. It must be the only hash option field set,
 	// otherwise this request hash policy object will be ignored.
-	// +kubebuilder:validation:Required
+	// +optional
 	HeaderHashOptions *HeaderHashOptions `json:"headerHashOptions,omitempty"`
+
+	// CookieHashOptions should be set when request cookie hash based load
+	// balancing is desired. It must be the only hash option field set,
+	// otherwise this request hash policy object will be ignored.
+	// +optional
+	CookieHashOptions *CookieHashOptions `json:"cookieHashOptions,omitempty"`
+
+	// QueryParameterHashOptions should be set when request query parameter
+	// hash based load balancing is desired. It must be the only hash option
+	// field set, otherwise this request hash policy object will be ignored.
+	// +optional
+	QueryParameterHashOptions *QueryParameterHashOptions `json:"queryParameterHashOptions,omitempty"`
+
+	// HashSourceIP should be set to true when request source IP hash based
+	// load balancing is desired. It must be the only hash option field set,
+	// otherwise this request hash policy object will be ignored.
+	// +optional
+	HashSourceIP bool `json:"hashSourceIP,omitempty"`
 }
 
 // LoadBalancerPolicy defines the load balancing policy.
@@ -739,6 +1496,13 @@ type LoadBalancerPolicy struct {
 	// list of hash policies is empty after validation, the load balancing
 	// strategy will fall back the the default `RoundRobin`.
 	RequestHashPolicies []RequestHashPolicy `json:"requestHashPolicies,omitempty"`
+
+	// CookieHashOptions configures the session affinity cookie used
+	// when the `Cookie` load balancing strategy is chosen. If it is not
+	// supplied, a session cookie named `X-Contour-Session-Affinity`
+	// with a path of `/` is used.
+	// +optional
+	CookieHashOptions *CookieHashOptions `json:"cookieHashOptions,omitempty"`
 }
 
 // HeadersPolicy defines how headers are managed during forwarding.
@@ -761,7 +1525,11 @@ type HeaderValue struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
-	// Value represents the value of a header specified by a key
+	// Value represents the value of a header specified by a key.
+	// The value may contain variables of the form %VARIABLE% that are
+	// replaced with request or connection metadata, such as
+	// %DOWNSTREAM_REMOTE_ADDRESS% or %REQ(header-name)%. Unknown
+	// variables are rejected. Any other percent sign is used literally.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=1
 	Value string `json:"value"`
@@ -782,6 +1550,61 @@ type DownstreamValidation struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=1
 	CACertificate string `json:"caSecret"`
+
+	// Name of a Kubernetes secret that contains a certificate revocation
+	// list (CRL) in PEM format, under the "crl.pem" key. Client certificates
+	// that are revoked by the list are rejected. A CRL must be provided for
+	// every certificate authority in the trust chain of the client certificate.
+	// +optional
+	CertificateRevocationList string `json:"crlSecret,omitempty"`
+
+	// OptionalClientCertificate allows clients to connect without presenting
+	// a certificate. Certificates that are presented are still validated.
+	// Routes may then use an authorization server to decide which requests
+	// are allowed without a client certificate.
+	// +optional
+	OptionalClientCertificate bool `json:"optionalClientCertificate,omitempty"`
+
+	// SubjectAltNames lists the subject alternative names that are allowed
+	// in client certificates. A client certificate must contain at least one
+	// of the names. If not specified, all subject alternative names are allowed.
+	// +optional
+	SubjectAltNames []string `json:"subjectAltNames,omitempty"`
+
+	// Subjects lists the subject distinguished names, in RFC 2253 format
+	// (for example "CN=client,O=Example"), that are allowed in client
+	// certificates. Requests with a client certificate whose subject is not
+	// listed are rejected with 403 (Forbidden). If not specified, all subjects
+	// are allowed.
+	// +optional
+	Subjects []string `json:"subjects,omitempty"`
+
+	// ForwardClientCertificate adds the selected details of the client
+	// certificate to the x-forwarded-client-cert (XFCC) header of requests
+	// that are sent to backend services. If not specified, the XFCC header
+	// is removed from requests.
+	// +optional
+	ForwardClientCertificate *ClientCertificateDetails `json:"forwardClientCertificate,omitempty"`
+}
+
+// ClientCertificateDetails selects the details of a client certificate
+// that are forwarded in the x-forwarded-client-cert header.
+type ClientCertificateDetails struct {
+	// Subject of the client certificate.
+	// +optional
+	Subject bool `json:"subject,omitempty"`
+	// Client certificate, in URL encoded PEM format.
+	// +optional
+	Cert bool `json:"cert,omitempty"`
+	// Client certificate chain, in URL encoded PEM format.
+	// +optional
+	Chain bool `json:"chain,omitempty"`
+	// DNS type subject alternative names of the client certificate.
+	// +optional
+	DNS bool `json:"dns,omitempty"`
+	// URI type subject alternative name of the client certificate.
+	// +optional
+	URI bool `json:"uri,omitempty"`
 }
 
 // HTTPProxyStatus reports the current state of the HTTPProxy.
@@ -24,6 +24,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizationHTTPService) DeepCopyInto(out *AuthorizationHTTPService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationHTTPService.
+func (in *AuthorizationHTTPService) DeepCopy() *AuthorizationHTTPService {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizationHTTPService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationPolicy) DeepCopyInto(out *AuthorizationPolicy) {
 	*out = *in
@@ -55,6 +70,36 @@ func (in *AuthorizationServer) DeepCopyInto(out *AuthorizationServer) {
 		*out = new(AuthorizationPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.WithRequestBody != nil {
+		in, out := &in.WithRequestBody, &out.WithRequestBody
+		*out = new(AuthorizationServerBufferSettings)
+		**out = **in
+	}
+	if in.AllowedRequestHeaders != nil {
+		in, out := &in.AllowedRequestHeaders, &out.AllowedRequestHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedUpstreamHeaders != nil {
+		in, out := &in.AllowedUpstreamHeaders, &out.AllowedUpstreamHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedClientHeaders != nil {
+		in, out := &in.AllowedClientHeaders, &out.AllowedClientHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludeClientCertificate != nil {
+		in, out := &in.IncludeClientCertificate, &out.IncludeClientCertificate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTPService != nil {
+		in, out := &in.HTTPService, &out.HTTPService
+		*out = new(AuthorizationHTTPService)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationServer.
@@ -67,6 +112,36 @@ func (in *AuthorizationServer) DeepCopy() *AuthorizationServer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizationServerBufferSettings) DeepCopyInto(out *AuthorizationServerBufferSettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationServerBufferSettings.
+func (in *AuthorizationServerBufferSettings) DeepCopy() *AuthorizationServerBufferSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizationServerBufferSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BasicAuthPolicy) DeepCopyInto(out *BasicAuthPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicAuthPolicy.
+func (in *BasicAuthPolicy) DeepCopy() *BasicAuthPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BasicAuthPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CORSPolicy) DeepCopyInto(out *CORSPolicy) {
 	*out = *in
@@ -122,6 +197,66 @@ func (in *CertificateDelegation) DeepCopy() *CertificateDelegation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreakerPolicy) DeepCopyInto(out *CircuitBreakerPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakerPolicy.
+func (in *CircuitBreakerPolicy) DeepCopy() *CircuitBreakerPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreakerPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientCertificateDetails) DeepCopyInto(out *ClientCertificateDetails) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientCertificateDetails.
+func (in *ClientCertificateDetails) DeepCopy() *ClientCertificateDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientCertificateDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyReference) DeepCopyInto(out *ConfigMapKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyReference.
+func (in *ConfigMapKeyReference) DeepCopy() *ConfigMapKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CookieHashOptions) DeepCopyInto(out *CookieHashOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CookieHashOptions.
+func (in *CookieHashOptions) DeepCopy() *CookieHashOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(CookieHashOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DetailedCondition) DeepCopyInto(out *DetailedCondition) {
 	*out = *in
@@ -151,6 +286,21 @@ func (in *DetailedCondition) DeepCopy() *DetailedCondition {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DownstreamValidation) DeepCopyInto(out *DownstreamValidation) {
 	*out = *in
+	if in.SubjectAltNames != nil {
+		in, out := &in.SubjectAltNames, &out.SubjectAltNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForwardClientCertificate != nil {
+		in, out := &in.ForwardClientCertificate, &out.ForwardClientCertificate
+		*out = new(ClientCertificateDetails)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownstreamValidation.
@@ -178,6 +328,66 @@ func (in *ExtensionServiceReference) DeepCopy() *ExtensionServiceReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FaultAbort) DeepCopyInto(out *FaultAbort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FaultAbort.
+func (in *FaultAbort) DeepCopy() *FaultAbort {
+	if in == nil {
+		return nil
+	}
+	out := new(FaultAbort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FaultDelay) DeepCopyInto(out *FaultDelay) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FaultDelay.
+func (in *FaultDelay) DeepCopy() *FaultDelay {
+	if in == nil {
+		return nil
+	}
+	out := new(FaultDelay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FaultInjectionPolicy) DeepCopyInto(out *FaultInjectionPolicy) {
+	*out = *in
+	if in.Delay != nil {
+		in, out := &in.Delay, &out.Delay
+		*out = new(FaultDelay)
+		**out = **in
+	}
+	if in.Abort != nil {
+		in, out := &in.Abort, &out.Abort
+		*out = new(FaultAbort)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]HeaderMatchCondition, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FaultInjectionPolicy.
+func (in *FaultInjectionPolicy) DeepCopy() *FaultInjectionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FaultInjectionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GenericKeyDescriptor) DeepCopyInto(out *GenericKeyDescriptor) {
 	*out = *in
@@ -215,6 +425,26 @@ func (in *GlobalRateLimitPolicy) DeepCopy() *GlobalRateLimitPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPDirectResponsePolicy) DeepCopyInto(out *HTTPDirectResponsePolicy) {
+	*out = *in
+	if in.BodyFromConfigMap != nil {
+		in, out := &in.BodyFromConfigMap, &out.BodyFromConfigMap
+		*out = new(ConfigMapKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPDirectResponsePolicy.
+func (in *HTTPDirectResponsePolicy) DeepCopy() *HTTPDirectResponsePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPDirectResponsePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPHealthCheckPolicy) DeepCopyInto(out *HTTPHealthCheckPolicy) {
 	*out = *in
@@ -351,6 +581,46 @@ func (in *HTTPProxyStatus) DeepCopy() *HTTPProxyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPRequestRedirectPolicy) DeepCopyInto(out *HTTPRequestRedirectPolicy) {
+	*out = *in
+	if in.Scheme != nil {
+		in, out := &in.Scheme, &out.Scheme
+		*out = new(string)
+		**out = **in
+	}
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Prefix != nil {
+		in, out := &in.Prefix, &out.Prefix
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPRequestRedirectPolicy.
+func (in *HTTPRequestRedirectPolicy) DeepCopy() *HTTPRequestRedirectPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPRequestRedirectPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HeaderHashOptions) DeepCopyInto(out *HeaderHashOptions) {
 	*out = *in
@@ -421,6 +691,21 @@ func (in *HeadersPolicy) DeepCopy() *HeadersPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPFilterPolicy) DeepCopyInto(out *IPFilterPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPFilterPolicy.
+func (in *IPFilterPolicy) DeepCopy() *IPFilterPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IPFilterPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Include) DeepCopyInto(out *Include) {
 	*out = *in
@@ -443,6 +728,66 @@ func (in *Include) DeepCopy() *Include {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTClaimToHeader) DeepCopyInto(out *JWTClaimToHeader) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTClaimToHeader.
+func (in *JWTClaimToHeader) DeepCopy() *JWTClaimToHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTClaimToHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTProvider) DeepCopyInto(out *JWTProvider) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoteJWKS != nil {
+		in, out := &in.RemoteJWKS, &out.RemoteJWKS
+		*out = new(RemoteJWKS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClaimsToHeaders != nil {
+		in, out := &in.ClaimsToHeaders, &out.ClaimsToHeaders
+		*out = make([]JWTClaimToHeader, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTProvider.
+func (in *JWTProvider) DeepCopy() *JWTProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTVerificationPolicy) DeepCopyInto(out *JWTVerificationPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTVerificationPolicy.
+func (in *JWTVerificationPolicy) DeepCopy() *JWTVerificationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTVerificationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancerPolicy) DeepCopyInto(out *LoadBalancerPolicy) {
 	*out = *in
@@ -453,6 +798,11 @@ func (in *LoadBalancerPolicy) DeepCopyInto(out *LoadBalancerPolicy) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CookieHashOptions != nil {
+		in, out := &in.CookieHashOptions, &out.CookieHashOptions
+		*out = new(CookieHashOptions)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPolicy.
@@ -493,6 +843,11 @@ func (in *MatchCondition) DeepCopyInto(out *MatchCondition) {
 		*out = new(HeaderMatchCondition)
 		**out = **in
 	}
+	if in.QueryParameter != nil {
+		in, out := &in.QueryParameter, &out.QueryParameter
+		*out = new(QueryParameterMatchCondition)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchCondition.
@@ -505,6 +860,21 @@ func (in *MatchCondition) DeepCopy() *MatchCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutlierDetection) DeepCopyInto(out *OutlierDetection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutlierDetection.
+func (in *OutlierDetection) DeepCopy() *OutlierDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(OutlierDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PathRewritePolicy) DeepCopyInto(out *PathRewritePolicy) {
 	*out = *in
@@ -513,6 +883,16 @@ func (in *PathRewritePolicy) DeepCopyInto(out *PathRewritePolicy) {
 		*out = make([]ReplacePrefix, len(*in))
 		copy(*out, *in)
 	}
+	if in.RegexRewrite != nil {
+		in, out := &in.RegexRewrite, &out.RegexRewrite
+		*out = new(RegexRewrite)
+		**out = **in
+	}
+	if in.ReplaceFullPath != nil {
+		in, out := &in.ReplaceFullPath, &out.ReplaceFullPath
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PathRewritePolicy.
@@ -525,6 +905,36 @@ func (in *PathRewritePolicy) DeepCopy() *PathRewritePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryParameterHashOptions) DeepCopyInto(out *QueryParameterHashOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryParameterHashOptions.
+func (in *QueryParameterHashOptions) DeepCopy() *QueryParameterHashOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryParameterHashOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryParameterMatchCondition) DeepCopyInto(out *QueryParameterMatchCondition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryParameterMatchCondition.
+func (in *QueryParameterMatchCondition) DeepCopy() *QueryParameterMatchCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryParameterMatchCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RateLimitDescriptor) DeepCopyInto(out *RateLimitDescriptor) {
 	*out = *in
@@ -607,6 +1017,21 @@ func (in *RateLimitPolicy) DeepCopy() *RateLimitPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegexRewrite) DeepCopyInto(out *RegexRewrite) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegexRewrite.
+func (in *RegexRewrite) DeepCopy() *RegexRewrite {
+	if in == nil {
+		return nil
+	}
+	out := new(RegexRewrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteAddressDescriptor) DeepCopyInto(out *RemoteAddressDescriptor) {
 	*out = *in
@@ -622,6 +1047,26 @@ func (in *RemoteAddressDescriptor) DeepCopy() *RemoteAddressDescriptor {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteJWKS) DeepCopyInto(out *RemoteJWKS) {
+	*out = *in
+	if in.UpstreamValidation != nil {
+		in, out := &in.UpstreamValidation, &out.UpstreamValidation
+		*out = new(UpstreamValidation)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteJWKS.
+func (in *RemoteJWKS) DeepCopy() *RemoteJWKS {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteJWKS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplacePrefix) DeepCopyInto(out *ReplacePrefix) {
 	*out = *in
@@ -645,6 +1090,16 @@ func (in *RequestHashPolicy) DeepCopyInto(out *RequestHashPolicy) {
 		*out = new(HeaderHashOptions)
 		**out = **in
 	}
+	if in.CookieHashOptions != nil {
+		in, out := &in.CookieHashOptions, &out.CookieHashOptions
+		*out = new(CookieHashOptions)
+		**out = **in
+	}
+	if in.QueryParameterHashOptions != nil {
+		in, out := &in.QueryParameterHashOptions, &out.QueryParameterHashOptions
+		*out = new(QueryParameterHashOptions)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestHashPolicy.
@@ -692,6 +1147,21 @@ func (in *RequestHeaderValueMatchDescriptor) DeepCopy() *RequestHeaderValueMatch
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryBackoff) DeepCopyInto(out *RetryBackoff) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryBackoff.
+func (in *RetryBackoff) DeepCopy() *RetryBackoff {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryBackoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
 	*out = *in
@@ -705,6 +1175,26 @@ func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
 		*out = make([]uint32, len(*in))
 		copy(*out, *in)
 	}
+	if in.RetriableHeaders != nil {
+		in, out := &in.RetriableHeaders, &out.RetriableHeaders
+		*out = make([]HeaderMatchCondition, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetriableRequestHeaders != nil {
+		in, out := &in.RetriableRequestHeaders, &out.RetriableRequestHeaders
+		*out = make([]HeaderMatchCondition, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(RetryBackoff)
+		**out = **in
+	}
+	if in.RetryHostPredicate != nil {
+		in, out := &in.RetryHostPredicate, &out.RetryHostPredicate
+		*out = make([]RetryHostPredicate, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
@@ -739,6 +1229,11 @@ func (in *Route) DeepCopyInto(out *Route) {
 		*out = new(AuthorizationPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(AuthorizationServer)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TimeoutPolicy != nil {
 		in, out := &in.TimeoutPolicy, &out.TimeoutPolicy
 		*out = new(TimeoutPolicy)
@@ -779,6 +1274,41 @@ func (in *Route) DeepCopyInto(out *Route) {
 		*out = new(RateLimitPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.FaultInjectionPolicy != nil {
+		in, out := &in.FaultInjectionPolicy, &out.FaultInjectionPolicy
+		*out = new(FaultInjectionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPAllowFilterPolicy != nil {
+		in, out := &in.IPAllowFilterPolicy, &out.IPAllowFilterPolicy
+		*out = make([]IPFilterPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPDenyFilterPolicy != nil {
+		in, out := &in.IPDenyFilterPolicy, &out.IPDenyFilterPolicy
+		*out = make([]IPFilterPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.JWTVerificationPolicy != nil {
+		in, out := &in.JWTVerificationPolicy, &out.JWTVerificationPolicy
+		*out = new(JWTVerificationPolicy)
+		**out = **in
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(BasicAuthPolicy)
+		**out = **in
+	}
+	if in.RequestRedirectPolicy != nil {
+		in, out := &in.RequestRedirectPolicy, &out.RequestRedirectPolicy
+		*out = new(HTTPRequestRedirectPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DirectResponsePolicy != nil {
+		in, out := &in.DirectResponsePolicy, &out.DirectResponsePolicy
+		*out = new(HTTPDirectResponsePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Route.
@@ -814,6 +1344,16 @@ func (in *Service) DeepCopyInto(out *Service) {
 		*out = new(HeadersPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OutlierDetection != nil {
+		in, out := &in.OutlierDetection, &out.OutlierDetection
+		*out = new(OutlierDetection)
+		**out = **in
+	}
+	if in.CircuitBreakerPolicy != nil {
+		in, out := &in.CircuitBreakerPolicy, &out.CircuitBreakerPolicy
+		*out = new(CircuitBreakerPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Service.
@@ -919,7 +1459,7 @@ func (in *TLS) DeepCopyInto(out *TLS) {
 	if in.ClientValidation != nil {
 		in, out := &in.ClientValidation, &out.ClientValidation
 		*out = new(DownstreamValidation)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -1089,6 +1629,38 @@ func (in *VirtualHost) DeepCopyInto(out *VirtualHost) {
 		*out = new(RateLimitPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RequestHeadersPolicy != nil {
+		in, out := &in.RequestHeadersPolicy, &out.RequestHeadersPolicy
+		*out = new(HeadersPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResponseHeadersPolicy != nil {
+		in, out := &in.ResponseHeadersPolicy, &out.ResponseHeadersPolicy
+		*out = new(HeadersPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPAllowFilterPolicy != nil {
+		in, out := &in.IPAllowFilterPolicy, &out.IPAllowFilterPolicy
+		*out = make([]IPFilterPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPDenyFilterPolicy != nil {
+		in, out := &in.IPDenyFilterPolicy, &out.IPDenyFilterPolicy
+		*out = make([]IPFilterPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.JWTProviders != nil {
+		in, out := &in.JWTProviders, &out.JWTProviders
+		*out = make([]JWTProvider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(BasicAuthPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualHost.
@@ -29,6 +29,7 @@ import (
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/annotation"
+	"github.com/projectcontour/contour/internal/basicauth"
 	"github.com/projectcontour/contour/internal/contour"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/debug"
@@ -302,6 +303,7 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 		DefaultHTTPVersions:           parseDefaultHTTPVersions(ctx.Config.DefaultHTTPVersions),
 		AllowChunkedLength:            !ctx.Config.DisableAllowChunkedLength,
 		XffNumTrustedHops:             ctx.Config.Network.XffNumTrustedHops,
+		Compression:                   ctx.Config.Compression,
 		ConnectionBalancer:            ctx.Config.Listener.ConnectionBalancer,
 	}
 
@@ -318,6 +320,10 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(res.Object, &extensionSvc); err != nil {
 			return fmt.Errorf("error converting rate limit extension service %s: %v", namespacedName, err)
 		}
+		// the rate limit service is a gRPC service
+		if p := extensionSvc.Spec.Protocol; p != nil && *p == "http/1.1" {
+			return fmt.Errorf("rate limit extension service %s must use the h2 or h2c protocol", namespacedName)
+		}
 		// get the response timeout from the ExtensionService
 		var responseTimeout timeout.Setting
 		if tp := extensionSvc.Spec.TimeoutPolicy; tp != nil {
@@ -339,8 +345,20 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 	contourMetrics := metrics.NewMetrics(registry)
 
 	// Endpoints updates are handled directly by the EndpointsTranslator
-	// due to their high update rate and their orthogonal nature.
-	endpointHandler := xdscache_v3.NewEndpointsTranslator(log.WithField("context", "endpointstranslator"))
+	// (or EndpointSliceTranslator) due to their high update rate and
+	// their orthogonal nature.
+	var endpointHandler endpointsTranslator
+	var endpointResources []schema.GroupVersionResource
+
+	switch ctx.Config.Server.EndpointSource {
+	case config.EndpointSlicesEndpointSource:
+		endpointHandler = xdscache_v3.NewEndpointSliceTranslator(log.WithField("context", "endpointslicetranslator"))
+		// Nodes supply the localities of endpoints and Envoys.
+		endpointResources = append(k8s.EndpointSlicesResources(), k8s.NodesResources()...)
+	default:
+		endpointHandler = xdscache_v3.NewEndpointsTranslator(log.WithField("context", "endpointstranslator"))
+		endpointResources = k8s.EndpointsResources()
+	}
 
 	resources := []xdscache.ResourceCache{
 		xdscache_v3.NewListenerCache(listenerConfig, ctx.statsAddr, ctx.statsPort),
@@ -354,7 +372,12 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 	snapshotHandler := xdscache.NewSnapshotHandler(resources, log.WithField("context", "snapshotHandler"))
 
 	// register observer for endpoints updates.
-	endpointHandler.Observer = contour.ComposeObservers(snapshotHandler)
+	switch h := endpointHandler.(type) {
+	case *xdscache_v3.EndpointsTranslator:
+		h.Observer = contour.ComposeObservers(snapshotHandler)
+	case *xdscache_v3.EndpointSliceTranslator:
+		h.Observer = contour.ComposeObservers(snapshotHandler)
+	}
 
 	// Log that we're using the fallback certificate if configured.
 	if fallbackCert != nil {
@@ -364,11 +387,17 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 		log.WithField("context", "envoy-client-certificate").Infof("enabled client certificate with secret: %q", clientCert)
 	}
 
+	// basicAuthServer verifies the basic authentication credentials
+	// of requests for Envoy. It observes the DAG before the snapshot
+	// handler, so that it knows the credentials of new routes before
+	// Envoy does.
+	basicAuthServer := basicauth.NewServer(log.WithField("context", "basicauth"))
+
 	// Build the core Kubernetes event handler.
 	eventHandler := &contour.EventHandler{
 		HoldoffDelay:    100 * time.Millisecond,
 		HoldoffMaxDelay: 500 * time.Millisecond,
-		Observer:        dag.ComposeObservers(append(xdscache.ObserversOf(resources), snapshotHandler)...),
+		Observer:        dag.ComposeObservers(append(xdscache.ObserversOf(resources), basicAuthServer, snapshotHandler)...),
 		Builder:         getDAGBuilder(ctx, clients, clientCert, fallbackCert, log),
 		FieldLogger:     log.WithField("context", "contourEventHandler"),
 	}
@@ -444,8 +473,15 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 		}
 	}
 
-	// Inform on endpoints.
-	for _, r := range k8s.EndpointsResources() {
+	// Inform on configmaps.
+	for _, r := range k8s.ConfigMapsResources() {
+		if err := informOnResource(clients, r, &dynamicHandler); err != nil {
+			log.WithError(err).WithField("resource", r).Fatal("failed to create informer")
+		}
+	}
+
+	// Inform on endpoints or endpoint slices.
+	for _, r := range endpointResources {
 		if err := informOnResource(clients, r, &k8s.DynamicClientHandler{
 			Next: &contour.EventRecorder{
 				Next:    endpointHandler,
@@ -617,6 +653,8 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 			log.Fatalf("invalid xDS server type %q", ctx.Config.Server.XDSServerType)
 		}
 
+		basicAuthServer.Register(grpcServer)
+
 		addr := net.JoinHostPort(ctx.xdsAddr, strconv.Itoa(ctx.xdsPort))
 		l, err := net.Listen("tcp", addr)
 		if err != nil {
@@ -686,29 +724,43 @@ func getDAGBuilder(ctx *serveContext, clients *k8s.Clients, clientCert, fallback
 		responseHeadersPolicy.Remove = append(responseHeadersPolicy.Remove, ctx.Config.Policy.ResponseHeadersPolicy.Remove...)
 	}
 
+	var circuitBreakerDefaults *dag.CircuitBreakerPolicy
+	if cb := ctx.Config.Cluster.CircuitBreakers; cb != (config.CircuitBreakerParameters{}) {
+		circuitBreakerDefaults = &dag.CircuitBreakerPolicy{
+			MaxConnections:     cb.MaxConnections,
+			MaxPendingRequests: cb.MaxPendingRequests,
+			MaxRequests:        cb.MaxRequests,
+			MaxRetries:         cb.MaxRetries,
+		}
+	}
+
 	// Get the appropriate DAG processors.
 	dagProcessors := []dag.Processor{
 		&dag.IngressProcessor{
-			FieldLogger:       log.WithField("context", "IngressProcessor"),
-			ClientCertificate: clientCert,
+			FieldLogger:            log.WithField("context", "IngressProcessor"),
+			ClientCertificate:      clientCert,
+			CircuitBreakerDefaults: circuitBreakerDefaults,
 		},
 		&dag.ExtensionServiceProcessor{
-			FieldLogger:       log.WithField("context", "ExtensionServiceProcessor"),
-			ClientCertificate: clientCert,
+			FieldLogger:            log.WithField("context", "ExtensionServiceProcessor"),
+			ClientCertificate:      clientCert,
+			CircuitBreakerDefaults: circuitBreakerDefaults,
 		},
 		&dag.HTTPProxyProcessor{
-			DisablePermitInsecure: ctx.Config.DisablePermitInsecure,
-			FallbackCertificate:   fallbackCert,
-			DNSLookupFamily:       ctx.Config.Cluster.DNSLookupFamily,
-			ClientCertificate:     clientCert,
-			RequestHeadersPolicy:  &requestHeadersPolicy,
-			ResponseHeadersPolicy: &responseHeadersPolicy,
+			DisablePermitInsecure:  ctx.Config.DisablePermitInsecure,
+			FallbackCertificate:    fallbackCert,
+			DNSLookupFamily:        ctx.Config.Cluster.DNSLookupFamily,
+			ClientCertificate:      clientCert,
+			RequestHeadersPolicy:   &requestHeadersPolicy,
+			ResponseHeadersPolicy:  &responseHeadersPolicy,
+			CircuitBreakerDefaults: circuitBreakerDefaults,
 		},
 	}
 
 	if ctx.Config.GatewayConfig != nil && clients.ResourcesExist(k8s.GatewayAPIResources()...) {
 		dagProcessors = append(dagProcessors, &dag.GatewayAPIProcessor{
-			FieldLogger: log.WithField("context", "GatewayAPIProcessor"),
+			FieldLogger:            log.WithField("context", "GatewayAPIProcessor"),
+			CircuitBreakerDefaults: circuitBreakerDefaults,
 		})
 	}
 
@@ -747,6 +799,13 @@ func getDAGBuilder(ctx *serveContext, clients *k8s.Clients, clientCert, fallback
 	return builder
 }
 
+// endpointsTranslator is implemented by the xDS caches that
+// translate Kubernetes endpoint resources into ClusterLoadAssignments.
+type endpointsTranslator interface {
+	xdscache.ResourceCache
+	cache.ResourceEventHandler
+}
+
 func contains(namespaces []string, ns string) bool {
 	for _, namespace := range namespaces {
 		if ns == namespace {
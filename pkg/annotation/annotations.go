@@ -15,6 +15,7 @@ package annotation
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
@@ -66,6 +67,7 @@ var annotationsByKind = map[string]map[string]struct{}{
 		"projectcontour.io/max-pending-requests":  {},
 		"projectcontour.io/max-requests":          {},
 		"projectcontour.io/max-retries":           {},
+		"projectcontour.io/locality-failover":     {},
 		"projectcontour.io/upstream-protocol.h2":  {},
 		"projectcontour.io/upstream-protocol.h2c": {},
 		"projectcontour.io/upstream-protocol.tls": {},
@@ -244,3 +246,30 @@ func MaxRequests(o metav1.Object) uint32 {
 func MaxRetries(o metav1.Object) uint32 {
 	return parseUInt32(ContourAnnotation(o, "max-retries"))
 }
+
+// LocalityFailover returns whether the projectcontour.io/locality-failover
+// annotation is "true". If it is, Envoy prefers the endpoints of the
+// Service that are in its own zone, then those in its own region, and
+// only fails over to more distant endpoints when the nearer endpoints
+// are unhealthy.
+func LocalityFailover(o metav1.Object) bool {
+	return ContourAnnotation(o, "locality-failover") == "true"
+}
+
+// ValidateCircuitBreakers returns an error if any of the circuit
+// breaker annotations (max-connections, max-pending-requests,
+// max-requests and max-retries) are present but cannot be parsed
+// as a uint32.
+func ValidateCircuitBreakers(o metav1.Object) error {
+	for _, key := range []string{"max-connections", "max-pending-requests", "max-requests", "max-retries"} {
+		val := ContourAnnotation(o, key)
+		if val == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(val, 10, 32); err != nil {
+			return fmt.Errorf("invalid annotation projectcontour.io/%s value %q: must be an integer in the range 0-%d",
+				key, val, uint32(math.MaxUint32))
+		}
+	}
+	return nil
+}
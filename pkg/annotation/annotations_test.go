@@ -62,6 +62,82 @@ func TestParseUint32(t *testing.T) {
 	}
 }
 
+func TestValidateCircuitBreakers(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		wantErr     string
+	}{
+		"no annotations": {},
+		"valid annotations": {
+			annotations: map[string]string{
+				"projectcontour.io/max-connections":      "9000",
+				"projectcontour.io/max-pending-requests": "4096",
+				"projectcontour.io/max-requests":         "0",
+				"projectcontour.io/max-retries":          "7",
+			},
+		},
+		"negative value": {
+			annotations: map[string]string{
+				"projectcontour.io/max-requests": "-6",
+			},
+			wantErr: `invalid annotation projectcontour.io/max-requests value "-6": must be an integer in the range 0-4294967295`,
+		},
+		"too large": {
+			annotations: map[string]string{
+				"projectcontour.io/max-connections": "144115188075855872",
+			},
+			wantErr: `invalid annotation projectcontour.io/max-connections value "144115188075855872": must be an integer in the range 0-4294967295`,
+		},
+		"not a number": {
+			annotations: map[string]string{
+				"projectcontour.io/max-retries": "lots",
+			},
+			wantErr: `invalid annotation projectcontour.io/max-retries value "lots": must be an integer in the range 0-4294967295`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateCircuitBreakers(&metav1.ObjectMeta{Annotations: tc.annotations})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocalityFailover(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		want        bool
+	}{
+		"no annotation": {
+			want: false,
+		},
+		"enabled": {
+			annotations: map[string]string{"projectcontour.io/locality-failover": "true"},
+			want:        true,
+		},
+		"disabled": {
+			annotations: map[string]string{"projectcontour.io/locality-failover": "false"},
+			want:        false,
+		},
+		"not a boolean": {
+			annotations: map[string]string{"projectcontour.io/locality-failover": "zone"},
+			want:        false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			assert.Equal(t, tc.want, LocalityFailover(svc))
+		})
+	}
+}
+
 func TestParseUpstreamProtocols(t *testing.T) {
 	tests := map[string]struct {
 		a    map[string]string
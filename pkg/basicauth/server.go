@@ -0,0 +1,187 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package basicauth implements an Envoy external authorization
+// service that verifies HTTP basic authentication credentials.
+package basicauth
+
+import (
+	"context"
+	"crypto/sha1" // nolint:gosec
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/projectcontour/contour/pkg/dag"
+	envoy_v3 "github.com/projectcontour/contour/pkg/envoy/v3"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	rpc_status "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server verifies the basic authentication credentials of the
+// requests that Envoy checks with the filters of
+// envoy_v3.FilterBasicAuth. It learns the credentials of each
+// Secret from the routes of the DAG.
+type Server struct {
+	logrus.FieldLogger
+
+	mu sync.RWMutex
+	// users maps the name of each Secret to the password
+	// hashes of its users.
+	users map[string]map[string]string
+
+	// bcrypt limits the number of bcrypt hashes that are
+	// computed concurrently to the number of CPUs.
+	bcrypt chan struct{}
+}
+
+// NewServer returns a new Server that has no credentials.
+func NewServer(log logrus.FieldLogger) *Server {
+	return &Server{
+		FieldLogger: log,
+		users:       map[string]map[string]string{},
+		bcrypt:      make(chan struct{}, runtime.NumCPU()),
+	}
+}
+
+// Register registers the Server with the gRPC server.
+func (s *Server) Register(g *grpc.Server) {
+	envoy_service_auth_v3.RegisterAuthorizationServer(g, s)
+}
+
+// OnChange replaces the credentials of the Server with those of
+// the routes of the DAG.
+func (s *Server) OnChange(d *dag.DAG) {
+	users := map[string]map[string]string{}
+
+	var visit func(dag.Vertex)
+	visit = func(vertex dag.Vertex) {
+		if route, ok := vertex.(*dag.Route); ok {
+			if route.BasicAuth != nil {
+				users[route.BasicAuth.Secret.String()] = route.BasicAuth.Users
+			}
+			return
+		}
+		vertex.Visit(visit)
+	}
+	d.Visit(visit)
+
+	s.mu.Lock()
+	s.users = users
+	s.mu.Unlock()
+}
+
+// Check verifies the basic authentication credentials of a request.
+func (s *Server) Check(ctx context.Context, req *envoy_service_auth_v3.CheckRequest) (*envoy_service_auth_v3.CheckResponse, error) {
+	md := req.GetAttributes().GetMetadataContext().GetFilterMetadata()[envoy_v3.LuaFilterName]
+	secret := md.GetFields()[envoy_v3.BasicAuthSecretMetadataKey].GetStringValue()
+	realm := md.GetFields()[envoy_v3.BasicAuthRealmMetadataKey].GetStringValue()
+
+	s.mu.RLock()
+	users, ok := s.users[secret]
+	s.mu.RUnlock()
+
+	// Envoy can check requests with the routes of a DAG that
+	// Contour has not observed yet, so fail the check instead
+	// of denying the request.
+	if !ok {
+		return nil, status.Errorf(codes.Unavailable, "no basic authentication credentials for Secret %q", secret)
+	}
+
+	authorization := req.GetAttributes().GetRequest().GetHttp().GetHeaders()["authorization"]
+	user, password, ok := (&http.Request{Header: http.Header{"Authorization": {authorization}}}).BasicAuth()
+	if ok {
+		var err error
+		if ok, err = s.verify(ctx, users, user, password); err != nil {
+			return nil, err
+		}
+	}
+
+	if !ok {
+		s.WithField("secret", secret).WithField("user", user).Debug("basic authentication failed")
+		return denied(realm), nil
+	}
+
+	return &envoy_service_auth_v3.CheckResponse{
+		Status: &rpc_status.Status{Code: int32(code.Code_OK)},
+		HttpResponse: &envoy_service_auth_v3.CheckResponse_OkResponse{
+			OkResponse: &envoy_service_auth_v3.OkHttpResponse{},
+		},
+	}, nil
+}
+
+// verify returns true if the password of the user matches its hash.
+// Passwords of unknown users are compared with the hash of another
+// user, so that they take as long to reject as wrong passwords.
+func (s *Server) verify(ctx context.Context, users map[string]string, user string, password string) (bool, error) {
+	hash, known := users[user]
+	if !known {
+		for _, h := range users {
+			hash = h
+			break
+		}
+	}
+
+	var match bool
+	if strings.HasPrefix(hash, "{SHA}") {
+		want, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(hash, "{SHA}"))
+		if err != nil {
+			return false, status.Errorf(codes.Internal, "invalid SHA1 password hash: %s", err)
+		}
+		sum := sha1.Sum([]byte(password)) // nolint:gosec
+		match = subtle.ConstantTimeCompare(sum[:], want) == 1
+	} else {
+		select {
+		case s.bcrypt <- struct{}{}:
+		case <-ctx.Done():
+			return false, status.Error(codes.Unavailable, ctx.Err().Error())
+		}
+		match = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+		<-s.bcrypt
+	}
+
+	return known && match, nil
+}
+
+// denied returns the response that asks the client to authenticate
+// in the realm.
+func denied(realm string) *envoy_service_auth_v3.CheckResponse {
+	return &envoy_service_auth_v3.CheckResponse{
+		Status: &rpc_status.Status{Code: int32(code.Code_UNAUTHENTICATED)},
+		HttpResponse: &envoy_service_auth_v3.CheckResponse_DeniedResponse{
+			DeniedResponse: &envoy_service_auth_v3.DeniedHttpResponse{
+				Status: &envoy_type_v3.HttpStatus{
+					Code: envoy_type_v3.StatusCode_Unauthorized,
+				},
+				Headers: []*envoy_core_v3.HeaderValueOption{{
+					Header: &envoy_core_v3.HeaderValue{
+						Key:   "WWW-Authenticate",
+						Value: fmt.Sprintf("Basic realm=%q", realm),
+					},
+				}},
+			},
+		},
+	}
+}
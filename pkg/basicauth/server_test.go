@@ -0,0 +1,228 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basicauth
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	_struct "github.com/golang/protobuf/ptypes/struct"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/fixture"
+	"github.com/projectcontour/contour/pkg/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	rpc_status "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServerCheck(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	s := NewServer(fixture.NewTestLogger(t))
+	s.OnChange(buildDAG(t,
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "certificate", Namespace: "default"},
+			Type:       v1.SecretTypeTLS,
+			Data:       fixture.SecretRootsCert.Data,
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "htpasswd", Namespace: "default"},
+			Type:       v1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				dag.BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\nbob:" + string(bcryptHash)),
+			},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{Name: "http", Protocol: "TCP", Port: 80}},
+			},
+		},
+		&contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{Name: "simple", Namespace: "default"},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: "www.example.com",
+					TLS: &contour_api_v1.TLS{
+						SecretName: "certificate",
+					},
+					BasicAuth: &contour_api_v1.BasicAuthPolicy{
+						SecretName: "htpasswd",
+					},
+				},
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{Name: "backend", Port: 80}},
+				}},
+			},
+		},
+	))
+
+	denied := &envoy_service_auth_v3.CheckResponse{
+		Status: &rpc_status.Status{Code: int32(code.Code_UNAUTHENTICATED)},
+		HttpResponse: &envoy_service_auth_v3.CheckResponse_DeniedResponse{
+			DeniedResponse: &envoy_service_auth_v3.DeniedHttpResponse{
+				Status: &envoy_type_v3.HttpStatus{
+					Code: envoy_type_v3.StatusCode_Unauthorized,
+				},
+				Headers: []*envoy_core_v3.HeaderValueOption{{
+					Header: &envoy_core_v3.HeaderValue{
+						Key:   "WWW-Authenticate",
+						Value: `Basic realm="www.example.com"`,
+					},
+				}},
+			},
+		},
+	}
+
+	ok := &envoy_service_auth_v3.CheckResponse{
+		Status: &rpc_status.Status{Code: int32(code.Code_OK)},
+		HttpResponse: &envoy_service_auth_v3.CheckResponse_OkResponse{
+			OkResponse: &envoy_service_auth_v3.OkHttpResponse{},
+		},
+	}
+
+	tests := map[string]struct {
+		secret        string
+		authorization string
+		want          *envoy_service_auth_v3.CheckResponse
+		wantCode      codes.Code
+	}{
+		"SHA1 password": {
+			secret:        "default/htpasswd",
+			authorization: basicAuth("alice", "password"),
+			want:          ok,
+		},
+		"bcrypt password": {
+			secret:        "default/htpasswd",
+			authorization: basicAuth("bob", "secret"),
+			want:          ok,
+		},
+		"wrong SHA1 password": {
+			secret:        "default/htpasswd",
+			authorization: basicAuth("alice", "secret"),
+			want:          denied,
+		},
+		"wrong bcrypt password": {
+			secret:        "default/htpasswd",
+			authorization: basicAuth("bob", "password"),
+			want:          denied,
+		},
+		"unknown user": {
+			secret:        "default/htpasswd",
+			authorization: basicAuth("carol", "password"),
+			want:          denied,
+		},
+		"no credentials": {
+			secret: "default/htpasswd",
+			want:   denied,
+		},
+		"not basic authentication": {
+			secret:        "default/htpasswd",
+			authorization: "Bearer " + base64.StdEncoding.EncodeToString([]byte("alice:password")),
+			want:          denied,
+		},
+		"unknown secret": {
+			secret:        "default/missing",
+			authorization: basicAuth("alice", "password"),
+			wantCode:      codes.Unavailable,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.Check(context.Background(), checkRequest(tc.secret, "www.example.com", tc.authorization))
+			if tc.wantCode != codes.OK {
+				assert.Equal(t, tc.wantCode, status.Code(err))
+				return
+			}
+
+			require.NoError(t, err)
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
+
+func TestServerOnChange(t *testing.T) {
+	s := NewServer(fixture.NewTestLogger(t))
+	s.users["default/htpasswd"] = map[string]string{"alice": "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="}
+
+	// Credentials that no route uses any more are forgotten.
+	s.OnChange(buildDAG(t))
+
+	_, err := s.Check(context.Background(), checkRequest("default/htpasswd", "www.example.com", basicAuth("alice", "password")))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func basicAuth(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+// checkRequest returns the check request that Envoy sends for a
+// request to a route with the given basic authentication policy.
+func checkRequest(secret, realm, authorization string) *envoy_service_auth_v3.CheckRequest {
+	headers := map[string]string{}
+	if len(authorization) > 0 {
+		headers["authorization"] = authorization
+	}
+
+	return &envoy_service_auth_v3.CheckRequest{
+		Attributes: &envoy_service_auth_v3.AttributeContext{
+			Request: &envoy_service_auth_v3.AttributeContext_Request{
+				Http: &envoy_service_auth_v3.AttributeContext_HttpRequest{
+					Headers: headers,
+				},
+			},
+			MetadataContext: &envoy_core_v3.Metadata{
+				FilterMetadata: map[string]*_struct.Struct{
+					"envoy.filters.http.lua": {
+						Fields: map[string]*_struct.Value{
+							"basic_auth_secret": {Kind: &_struct.Value_StringValue{StringValue: secret}},
+							"basic_auth_realm":  {Kind: &_struct.Value_StringValue{StringValue: realm}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildDAG produces a dag.DAG from the supplied objects.
+func buildDAG(t *testing.T, objs ...interface{}) *dag.DAG {
+	builder := dag.Builder{
+		Source: dag.KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		},
+		Processors: []dag.Processor{
+			&dag.HTTPProxyProcessor{},
+			&dag.ListenerProcessor{},
+		},
+	}
+
+	for _, o := range objs {
+		builder.Source.Insert(o)
+	}
+	return builder.Build()
+}
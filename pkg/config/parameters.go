@@ -17,6 +17,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -24,6 +25,7 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
@@ -43,6 +45,23 @@ func (s ServerType) Validate() error {
 	}
 }
 
+// EndpointSourceType is the Kubernetes resource that Contour watches
+// to discover the endpoints of Services.
+type EndpointSourceType string
+
+const EndpointsEndpointSource EndpointSourceType = "endpoints"
+const EndpointSlicesEndpointSource EndpointSourceType = "endpointslices"
+
+// Validate the endpoint source.
+func (e EndpointSourceType) Validate() error {
+	switch e {
+	case EndpointsEndpointSource, EndpointSlicesEndpointSource:
+		return nil
+	default:
+		return fmt.Errorf("invalid endpoint source %q", e)
+	}
+}
+
 // Validate the GatewayConfig.
 func (g *GatewayParameters) Validate() error {
 
@@ -286,6 +305,11 @@ type ServerParameters struct {
 	// Defines the XDSServer to use for `contour serve`.
 	// Defaults to "contour"
 	XDSServerType ServerType `yaml:"xds-server-type,omitempty"`
+
+	// Defines the Kubernetes resource that Service endpoints are
+	// discovered from, either "endpoints" or "endpointslices".
+	// Defaults to "endpoints"
+	EndpointSource EndpointSourceType `yaml:"endpoint-source,omitempty"`
 }
 
 // GatewayParameters holds the configuration for what Gateway API Gateway
@@ -404,16 +428,87 @@ func (t TimeoutParameters) Validate() error {
 	return nil
 }
 
+// EnvoyHeaderVariables are the Envoy custom request/response header
+// variables that may be used in header values. See:
+// https://www.envoyproxy.io/docs/envoy/latest/configuration/http/http_conn_man/headers#custom-request-response-headers
+var EnvoyHeaderVariables = []string{
+	"DOWNSTREAM_REMOTE_ADDRESS",
+	"DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT",
+	"DOWNSTREAM_LOCAL_ADDRESS",
+	"DOWNSTREAM_LOCAL_ADDRESS_WITHOUT_PORT",
+	"DOWNSTREAM_LOCAL_PORT",
+	"DOWNSTREAM_LOCAL_URI_SAN",
+	"DOWNSTREAM_PEER_URI_SAN",
+	"DOWNSTREAM_LOCAL_SUBJECT",
+	"DOWNSTREAM_PEER_SUBJECT",
+	"DOWNSTREAM_PEER_ISSUER",
+	"DOWNSTREAM_TLS_SESSION_ID",
+	"DOWNSTREAM_TLS_CIPHER",
+	"DOWNSTREAM_TLS_VERSION",
+	"DOWNSTREAM_PEER_FINGERPRINT_256",
+	"DOWNSTREAM_PEER_FINGERPRINT_1",
+	"DOWNSTREAM_PEER_SERIAL",
+	"DOWNSTREAM_PEER_CERT",
+	"DOWNSTREAM_PEER_CERT_V_START",
+	"DOWNSTREAM_PEER_CERT_V_END",
+	"HOSTNAME",
+	"PROTOCOL",
+	"REQUESTED_SERVER_NAME",
+	"UPSTREAM_REMOTE_ADDRESS",
+	"RESPONSE_FLAGS",
+	"RESPONSE_CODE_DETAILS",
+}
+
+// headerVariables are the variables that may be used in the default
+// header policies: the Envoy variables, and the variables Contour
+// replaces with the namespace, name and port of each service.
+var headerVariables = sets.NewString(EnvoyHeaderVariables...).Insert(
+	"CONTOUR_NAMESPACE",
+	"CONTOUR_SERVICE_NAME",
+	"CONTOUR_SERVICE_PORT",
+)
+
+// headerVariableRegex matches a header value variable, either
+// %VARIABLE% or %VARIABLE(argument)%.
+var headerVariableRegex = regexp.MustCompile(`%([A-Z][A-Z0-9_]*)(\(([^%()]*)\))?%`)
+
+// headerNameRegex matches the header names that may be used as the
+// argument to the REQ variable.
+var headerNameRegex = regexp.MustCompile(`^[\w-]+$`)
+
+// validateHeaderValue returns an error if value uses a header variable
+// that is not an Envoy variable, %REQ(header-name)%, or one of the
+// variables Contour replaces for each service.
+func validateHeaderValue(value string) error {
+	for _, m := range headerVariableRegex.FindAllStringSubmatch(value, -1) {
+		variable, name, hasArgument, argument := m[0], m[1], m[2] != "", m[3]
+		switch {
+		case name == "REQ":
+			if !headerNameRegex.MatchString(argument) {
+				return fmt.Errorf("invalid header name %q in header variable %q", argument, variable)
+			}
+		case hasArgument:
+			return fmt.Errorf("header variable %q does not take an argument", variable)
+		case !headerVariables.Has(name):
+			return fmt.Errorf("unknown header variable %q", variable)
+		}
+	}
+	return nil
+}
+
 type HeadersPolicy struct {
 	Set    map[string]string `yaml:"set,omitempty"`
 	Remove []string          `yaml:"remove,omitempty"`
 }
 
 func (h HeadersPolicy) Validate() error {
-	for key := range h.Set {
+	for key, val := range h.Set {
 		if msgs := validation.IsHTTPHeaderName(key); len(msgs) != 0 {
 			return fmt.Errorf("invalid header name %q: %v", key, msgs)
 		}
+		if err := validateHeaderValue(val); err != nil {
+			return fmt.Errorf("invalid header value for %q: %v", key, err)
+		}
 	}
 	for _, val := range h.Remove {
 		if msgs := validation.IsHTTPHeaderName(val); len(msgs) != 0 {
@@ -457,6 +552,34 @@ type ClusterParameters struct {
 	// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/cluster/v3/cluster.proto.html#envoy-v3-api-enum-config-cluster-v3-cluster-dnslookupfamily
 	// for more information.
 	DNSLookupFamily ClusterDNSFamilyType `yaml:"dns-lookup-family"`
+
+	// CircuitBreakers holds the default circuit breaking thresholds
+	// for upstream clusters. Thresholds set by Service annotations or
+	// by HTTPProxy circuit breaker policies take precedence.
+	CircuitBreakers CircuitBreakerParameters `yaml:"circuit-breakers,omitempty"`
+}
+
+// CircuitBreakerParameters holds circuit breaking thresholds. A zero
+// value selects the Envoy default for that threshold.
+//
+// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/cluster/v3/circuit_breaker.proto
+// for more information.
+type CircuitBreakerParameters struct {
+	// MaxConnections is the maximum number of connections that Envoy
+	// will make to an upstream cluster.
+	MaxConnections uint32 `yaml:"max-connections,omitempty"`
+
+	// MaxPendingRequests is the maximum number of pending requests that
+	// Envoy will allow to an upstream cluster.
+	MaxPendingRequests uint32 `yaml:"max-pending-requests,omitempty"`
+
+	// MaxRequests is the maximum number of parallel requests that Envoy
+	// will make to an upstream cluster.
+	MaxRequests uint32 `yaml:"max-requests,omitempty"`
+
+	// MaxRetries is the maximum number of parallel retries that Envoy
+	// will allow to an upstream cluster.
+	MaxRetries uint32 `yaml:"max-retries,omitempty"`
 }
 
 // NetworkParameters hold various configurable network values.
@@ -478,6 +601,65 @@ type ListenerParameters struct {
 	ConnectionBalancer string `yaml:"connection-balancer"`
 }
 
+// CompressionAlgorithm is the name of a response compression algorithm.
+type CompressionAlgorithm string
+
+func (c CompressionAlgorithm) Validate() error {
+	switch c {
+	case GzipCompression, BrotliCompression:
+		return nil
+	default:
+		return fmt.Errorf("invalid compression algorithm %q", c)
+	}
+}
+
+const GzipCompression CompressionAlgorithm = "gzip"
+const BrotliCompression CompressionAlgorithm = "brotli"
+
+// CompressionParameters holds the response compression settings applied
+// by Envoy to all HTTP listeners.
+type CompressionParameters struct {
+	// Disabled turns off response compression entirely.
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// Algorithms lists the compression algorithms offered to clients,
+	// in order of preference. Supported values are "gzip" and "brotli".
+	// If not specified, only gzip is used.
+	Algorithms []CompressionAlgorithm `yaml:"algorithms,omitempty"`
+
+	// MinContentLength is the minimum response size, in bytes, that
+	// triggers compression. If not specified, Envoy's default of 30 bytes
+	// is used.
+	MinContentLength *uint32 `yaml:"minContentLength,omitempty"`
+
+	// ContentTypes lists the response content types that are compressed.
+	// If not specified, Envoy's default set of text, JSON, JavaScript,
+	// XML and SVG content types is used.
+	ContentTypes []string `yaml:"contentTypes,omitempty"`
+}
+
+// Validate the compression parameters.
+func (c CompressionParameters) Validate() error {
+	seen := map[CompressionAlgorithm]bool{}
+	for _, a := range c.Algorithms {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+		if seen[a] {
+			return fmt.Errorf("duplicate compression algorithm %q", a)
+		}
+		seen[a] = true
+	}
+
+	for _, ct := range c.ContentTypes {
+		if _, _, err := mime.ParseMediaType(ct); err != nil {
+			return fmt.Errorf("invalid compression content type %q: %w", ct, err)
+		}
+	}
+
+	return nil
+}
+
 // Parameters contains the configuration file parameters for the
 // Contour ingress controller.
 type Parameters struct {
@@ -556,6 +738,10 @@ type Parameters struct {
 
 	// Listener holds various configurable Envoy Listener values.
 	Listener ListenerParameters `yaml:"listener,omitempty"`
+	// Compression holds the response compression settings applied to
+	// all HTTP listeners.
+	Compression CompressionParameters `yaml:"compression,omitempty"`
+
 	// RateLimitService optionally holds properties of the Rate Limit Service
 	// to be used for global rate limiting.
 	RateLimitService RateLimitService `yaml:"rateLimitService,omitempty"`
@@ -594,6 +780,10 @@ func (p *Parameters) Validate() error {
 		return err
 	}
 
+	if err := p.Server.EndpointSource.Validate(); err != nil {
+		return err
+	}
+
 	if err := p.GatewayConfig.Validate(); err != nil {
 		return err
 	}
@@ -618,6 +808,10 @@ func (p *Parameters) Validate() error {
 		return err
 	}
 
+	if err := p.Compression.Validate(); err != nil {
+		return err
+	}
+
 	for _, v := range p.DefaultHTTPVersions {
 		if err := v.Validate(); err != nil {
 			return err
@@ -636,7 +830,8 @@ func Defaults() Parameters {
 		InCluster:  false,
 		Kubeconfig: filepath.Join(os.Getenv("HOME"), ".kube", "config"),
 		Server: ServerParameters{
-			XDSServerType: ContourServerType,
+			XDSServerType:  ContourServerType,
+			EndpointSource: EndpointsEndpointSource,
 		},
 		IngressStatusAddress:      "",
 		AccessLogFormat:           DEFAULT_ACCESS_LOG_TYPE,
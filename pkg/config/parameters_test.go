@@ -49,6 +49,7 @@ debug: false
 kubeconfig: TestParseDefaults/.kube/config
 server:
   xds-server-type: contour
+  endpoint-source: endpoints
 accesslog-format: envoy
 json-fields:
 - '@timestamp'
@@ -143,6 +144,48 @@ func TestValidateHeadersPolicy(t *testing.T) {
 			"l5d-dst-override": "%CONTOUR_SERVICE_NAME%.%CONTOUR_NAMESPACE%.svc.cluster.local:%CONTOUR_SERVICE_PORT%",
 		},
 	}.Validate())
+	assert.NoError(t, HeadersPolicy{
+		Set: map[string]string{
+			"X-Request-Host": "%REQ(Host)%",
+			"X-Weight":       "100%",
+		},
+	}.Validate())
+	assert.Error(t, HeadersPolicy{
+		Set: map[string]string{"X-Unknown": "%ABC%"},
+	}.Validate())
+	assert.Error(t, HeadersPolicy{
+		Set: map[string]string{"X-Start-Time": "%START_TIME%"},
+	}.Validate())
+	assert.Error(t, HeadersPolicy{
+		Set: map[string]string{"X-Hostname": "%HOSTNAME(foo)%"},
+	}.Validate())
+	assert.Error(t, HeadersPolicy{
+		Set: map[string]string{"X-Request-Host": "%REQ(Ho:st)%"},
+	}.Validate())
+}
+
+func TestValidateCompressionParameters(t *testing.T) {
+	assert.Error(t, CompressionAlgorithm("").Validate())
+	assert.Error(t, CompressionAlgorithm("deflate").Validate())
+
+	assert.NoError(t, GzipCompression.Validate())
+	assert.NoError(t, BrotliCompression.Validate())
+
+	assert.NoError(t, CompressionParameters{}.Validate())
+	assert.NoError(t, CompressionParameters{
+		Algorithms:   []CompressionAlgorithm{BrotliCompression, GzipCompression},
+		ContentTypes: []string{"application/json", "text/html; charset=utf-8"},
+	}.Validate())
+
+	assert.Error(t, CompressionParameters{
+		Algorithms: []CompressionAlgorithm{"deflate"},
+	}.Validate())
+	assert.Error(t, CompressionParameters{
+		Algorithms: []CompressionAlgorithm{GzipCompression, GzipCompression},
+	}.Validate())
+	assert.Error(t, CompressionParameters{
+		ContentTypes: []string{"application/"},
+	}.Validate())
 }
 
 func TestValidateNamespacedName(t *testing.T) {
@@ -161,6 +204,14 @@ func TestValidateServerType(t *testing.T) {
 	assert.NoError(t, ContourServerType.Validate())
 }
 
+func TestValidateEndpointSourceType(t *testing.T) {
+	assert.Error(t, EndpointSourceType("").Validate())
+	assert.Error(t, EndpointSourceType("pods").Validate())
+
+	assert.NoError(t, EndpointsEndpointSource.Validate())
+	assert.NoError(t, EndpointSlicesEndpointSource.Validate())
+}
+
 func TestValidateGatewayParameters(t *testing.T) {
 	// Namespace is required if name is passed.
 	gw := &GatewayParameters{Name: "gwname", Namespace: ""}
@@ -418,6 +469,17 @@ default-http-versions:
 - http/0.9
 `)
 
+	check(`
+compression:
+  algorithms:
+  - zstd
+`)
+
+	check(`
+server:
+  endpoint-source: pods
+`)
+
 }
 
 func TestConfigFileDefaultOverrideImport(t *testing.T) {
@@ -513,4 +575,17 @@ default-http-versions:
 network:
   num-trusted-hops: 1
 `)
+
+	check(func(t *testing.T, conf *Parameters) {
+		assert.Equal(t, AutoClusterDNSFamily, conf.Cluster.DNSLookupFamily)
+		assert.Equal(t, CircuitBreakerParameters{
+			MaxConnections: 2048,
+			MaxRetries:     5,
+		}, conf.Cluster.CircuitBreakers)
+	}, `
+cluster:
+  circuit-breakers:
+    max-connections: 2048
+    max-retries: 5
+`)
 }
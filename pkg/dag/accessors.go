@@ -74,6 +74,7 @@ func (dag *DAG) EnsureService(meta types.NamespacedName, port intstr.IntOrString
 		MaxRequests:        annotation.MaxRequests(svc),
 		MaxRetries:         annotation.MaxRetries(svc),
 		ExternalName:       externalName(svc),
+		LocalityFailover:   annotation.LocalityFailover(svc),
 	}
 	return dagSvc, nil
 }
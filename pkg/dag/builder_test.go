@@ -1433,6 +1433,104 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 				},
 			),
 		},
+		"insert basic single route with single regex header match and path match": {
+			gateway: gatewayWithSelector,
+			objs: []interface{}{
+				kuardService,
+				&gatewayapi_v1alpha1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "basic",
+						Namespace: "projectcontour",
+						Labels: map[string]string{
+							"app":  "contour",
+							"type": "controller",
+						},
+					},
+					Spec: gatewayapi_v1alpha1.HTTPRouteSpec{
+						Hostnames: []gatewayapi_v1alpha1.Hostname{
+							"test.projectcontour.io",
+						},
+						Rules: []gatewayapi_v1alpha1.HTTPRouteRule{{
+							Matches: []gatewayapi_v1alpha1.HTTPRouteMatch{{
+								Path: gatewayapi_v1alpha1.HTTPPathMatch{
+									Type:  "Prefix",
+									Value: "/",
+								},
+								Headers: &gatewayapi_v1alpha1.HTTPHeaderMatch{
+									Type:   "RegularExpression",
+									Values: map[string]string{"foo": "ba[rz]"},
+								},
+							}},
+							ForwardTo: httpRouteForwardTo("kuard", 8080, 1),
+						}},
+					},
+				},
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(virtualhost("test.projectcontour.io",
+						&Route{
+							PathMatchCondition: prefixString("/"),
+							HeaderMatchConditions: []HeaderMatchCondition{
+								{Name: "foo", Value: "ba[rz]", MatchType: "regex", Invert: false},
+							},
+							Clusters: clustersWeight(service(kuardService)),
+						}),
+					),
+				},
+			),
+		},
+		"insert single route with an invalid regex header match": {
+			gateway: gatewayWithSelector,
+			objs: []interface{}{
+				kuardService,
+				&gatewayapi_v1alpha1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "basic",
+						Namespace: "projectcontour",
+						Labels: map[string]string{
+							"app":  "contour",
+							"type": "controller",
+						},
+					},
+					Spec: gatewayapi_v1alpha1.HTTPRouteSpec{
+						Hostnames: []gatewayapi_v1alpha1.Hostname{
+							"test.projectcontour.io",
+						},
+						Rules: []gatewayapi_v1alpha1.HTTPRouteRule{{
+							Matches: []gatewayapi_v1alpha1.HTTPRouteMatch{{
+								Path: gatewayapi_v1alpha1.HTTPPathMatch{
+									Type:  "Prefix",
+									Value: "/blog",
+								},
+							}, {
+								Path: gatewayapi_v1alpha1.HTTPPathMatch{
+									Type:  "Prefix",
+									Value: "/tech",
+								},
+								Headers: &gatewayapi_v1alpha1.HTTPHeaderMatch{
+									Type:   "RegularExpression",
+									Values: map[string]string{"foo": "ba[rz"},
+								},
+							}},
+							ForwardTo: httpRouteForwardTo("kuard", 8080, 1),
+						}},
+					},
+				},
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(virtualhost("test.projectcontour.io",
+						&Route{
+							PathMatchCondition: prefixString("/blog"),
+							Clusters:           clustersWeight(service(kuardService)),
+						}),
+					),
+				},
+			),
+		},
 		"insert two routes with single header match, path match and header match": {
 			gateway: gatewayWithSelector,
 			objs: []interface{}{
@@ -1589,6 +1687,56 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 				},
 			),
 		},
+		"Route rule with request mirror": {
+			gateway: gatewayWithSelector,
+			objs: []interface{}{
+				kuardService,
+				kuardService2,
+				&gatewayapi_v1alpha1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "basic",
+						Namespace: "projectcontour",
+						Labels: map[string]string{
+							"app":  "contour",
+							"type": "controller",
+						},
+					},
+					Spec: gatewayapi_v1alpha1.HTTPRouteSpec{
+						Hostnames: []gatewayapi_v1alpha1.Hostname{
+							"test.projectcontour.io",
+						},
+						Rules: []gatewayapi_v1alpha1.HTTPRouteRule{{
+							Matches:   httpRouteMatch(gatewayapi_v1alpha1.PathMatchPrefix, "/"),
+							ForwardTo: httpRouteForwardTo("kuard", 8080, 1),
+							Filters: []gatewayapi_v1alpha1.HTTPRouteFilter{{
+								Type: gatewayapi_v1alpha1.HTTPRouteFilterRequestMirror,
+								RequestMirror: &gatewayapi_v1alpha1.HTTPRequestMirrorFilter{
+									ServiceName: pointer.StringPtr("kuard2"),
+									Port:        gatewayPort(8080),
+								},
+							}},
+						}},
+					},
+				},
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(virtualhost("test.projectcontour.io",
+						&Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters:           clustersWeight(service(kuardService)),
+							MirrorPolicies: []*MirrorPolicy{{
+								Cluster: &Cluster{
+									Upstream: service(kuardService2),
+								},
+								Percent: 100,
+							}},
+						},
+					)),
+				},
+			),
+		},
 		"HTTP forward with request header modifier": {
 			gateway: gatewayWithSelector,
 			objs: []interface{}{
@@ -2020,6 +2168,16 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
+	crl1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "crl",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			CRLKey: []byte(fixture.CRL),
+		},
+	}
+
 	i1V1 := &networking_v1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "kuard",
@@ -4540,6 +4698,21 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
+	// proxy18a is downstream validation with revocation, optional
+	// client certificates, allowlists and certificate forwarding
+	proxy18a := proxy18.DeepCopy()
+	proxy18a.Spec.VirtualHost.TLS.ClientValidation = &contour_api_v1.DownstreamValidation{
+		CACertificate:             cert1.Name,
+		CertificateRevocationList: crl1.Name,
+		OptionalClientCertificate: true,
+		SubjectAltNames:           []string{"client.example.com"},
+		Subjects:                  []string{"CN=client.example.com"},
+		ForwardClientCertificate: &contour_api_v1.ClientCertificateDetails{
+			Subject: true,
+			URI:     true,
+		},
+	}
+
 	// proxy19 is downstream validation, TCP proxying
 	proxy19 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -4653,7 +4826,7 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	// proxy13 has two mirrors, invalid.
+	// proxy13 mirrors the same service twice, invalid.
 	proxy13 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
@@ -4676,8 +4849,8 @@ func TestDAGInsert(t *testing.T) {
 					Mirror: true,
 				}, {
 					// it is legal to mention a service more that
-					// once, however it is not legal for more than one
-					// service to be marked as mirror.
+					// once, however it is not legal for the same
+					// service to be marked as mirror more than once.
 					Name:   s2.Name,
 					Port:   8080,
 					Mirror: true,
@@ -4686,6 +4859,38 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
+	// proxy13a has two mirrors, one of which only
+	// receives a fraction of the traffic.
+	proxy13a := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: s1.Name,
+					Port: 8080,
+				}, {
+					Name:          s2.Name,
+					Port:          8080,
+					Mirror:        true,
+					MirrorPercent: 5,
+				}, {
+					Name:   s2a.Name,
+					Port:   8080,
+					Mirror: true,
+				}},
+			}},
+		},
+	}
+
 	// invalid because tcpproxy both includes another and
 	// has a list of services.
 	proxy37 := &contour_api_v1.HTTPProxy{
@@ -4928,6 +5133,32 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
+	proxy100e := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "marketingwww",
+			Namespace: "marketing",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Exact: "/infotech",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "blog",
+					Port: 8080,
+				}},
+			}, {
+				Conditions: []contour_api_v1.MatchCondition{{
+					Regex: "/v[0-9]+/.*",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "blog",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
 	proxy100c := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "marketingwww",
@@ -5505,7 +5736,145 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
-	proxyLoadBalancerHashPolicyHeader := &contour_api_v1.HTTPProxy{
+	proxyLoadBalancerHashPolicyHeader := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+				}},
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "RequestHash",
+					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
+						{
+							Terminal: true,
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Some-Header",
+							},
+						},
+						{
+							// Lower case but duplicated, should be ignored.
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "x-some-header",
+							},
+						},
+						{
+							HeaderHashOptions: nil,
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Some-Other-Header",
+							},
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "",
+							},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	proxyCookieLoadBalancerOptions := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+				}},
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "Cookie",
+					CookieHashOptions: &contour_api_v1.CookieHashOptions{
+						CookieName: "app-session",
+						TTL:        "1h",
+						Path:       "/app",
+					},
+				},
+			}},
+		},
+	}
+
+	proxyLoadBalancerHashPolicyCookie := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "nginx",
+					Port: 80,
+				}},
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "RequestHash",
+					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
+						{
+							Terminal: true,
+							CookieHashOptions: &contour_api_v1.CookieHashOptions{
+								CookieName: "app-session",
+							},
+						},
+						{
+							// Duplicated cookie name, should be ignored.
+							CookieHashOptions: &contour_api_v1.CookieHashOptions{
+								CookieName: "app-session",
+								TTL:        "1h",
+							},
+						},
+						{
+							// More than one hash option, should be ignored.
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Some-Other-Header",
+							},
+							CookieHashOptions: &contour_api_v1.CookieHashOptions{},
+						},
+						{
+							// Invalid TTL, should be ignored.
+							CookieHashOptions: &contour_api_v1.CookieHashOptions{
+								CookieName: "other-session",
+								TTL:        "forever",
+							},
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Some-Header",
+							},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	proxyLoadBalancerHashPolicySourceIPAndQueryParameter := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
 			Namespace: "default",
@@ -5526,29 +5895,34 @@ func TestDAGInsert(t *testing.T) {
 					Strategy: "RequestHash",
 					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
 						{
-							Terminal: true,
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "X-Some-Header",
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "tenant",
 							},
 						},
 						{
-							// Lower case but duplicated, should be ignored.
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "x-some-header",
+							// Duplicated parameter name, should be ignored.
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "tenant",
 							},
 						},
 						{
-							HeaderHashOptions: nil,
+							// Empty parameter name, should be ignored.
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{},
 						},
 						{
+							// More than one hash option, should be ignored.
+							HashSourceIP: true,
 							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "X-Some-Other-Header",
+								HeaderName: "X-Some-Header",
 							},
 						},
 						{
-							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
-								HeaderName: "",
-							},
+							Terminal:     true,
+							HashSourceIP: true,
+						},
+						{
+							// Duplicated source IP, should be ignored.
+							HashSourceIP: true,
 						},
 					},
 				},
@@ -7980,12 +8354,30 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
-		"insert httpproxy with two mirrors": {
+		"insert httpproxy with duplicate mirrors": {
 			objs: []interface{}{
 				proxy13, s1, s2,
 			},
 			want: listeners(),
 		},
+		"insert httpproxy with two mirrors": {
+			objs: []interface{}{
+				proxy13a, s1, s2, s2a,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com",
+							withMirror(
+								withMirrorPercent(prefixroute("/", service(s1)), service(s2), 5),
+								service(s2a),
+							),
+						),
+					),
+				},
+			),
+		},
 		"insert httpproxy with websocket route and prefix rewrite": {
 			objs: []interface{}{
 				proxy10, s1,
@@ -8166,6 +8558,50 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
+		"insert httpproxy with downstream verification options": {
+			objs: []interface{}{
+				cert1, crl1, proxy18a, s1, sec1,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Port: 443,
+					VirtualHosts: virtualhosts(
+						&SecureVirtualHost{
+							VirtualHost: VirtualHost{
+								Name:         "example.com",
+								ListenerName: "ingress_https",
+								routes: routes(
+									routeUpgrade("/", service(s1))),
+							},
+							MinTLSVersion: "1.2",
+							Secret:        secret(sec1),
+							DownstreamValidation: &PeerValidationContext{
+								CACertificate:             &Secret{Object: cert1},
+								CRL:                       &Secret{Object: crl1},
+								OptionalClientCertificate: true,
+								SubjectAltNames:           []string{"client.example.com"},
+								Subjects:                  []string{"CN=client.example.com"},
+								ForwardClientCertificate: &ClientCertificateDetails{
+									Subject: true,
+									URI:     true,
+								},
+							},
+						},
+					),
+				},
+			),
+		},
+		"insert httpproxy with downstream verification, missing crl": {
+			objs: []interface{}{
+				cert1, proxy18a, s1, sec1,
+			},
+			want: listeners(),
+		},
 		"insert httpproxy w/ tcpproxy in tls termination mode w/ downstream verification": {
 			objs: []interface{}{
 				cert1, proxy19, s1, sec1,
@@ -8339,6 +8775,154 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
+		"insert httpproxy with pathPrefix include, child adds exact and regex conditions": {
+			objs: []interface{}{
+				proxy100, proxy100e, s1, s4,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com",
+							prefixroute("/", service(s1)),
+							&Route{
+								PathMatchCondition: exact("/blog/infotech"),
+								Clusters:           clusters(service(s4)),
+							},
+							&Route{
+								PathMatchCondition: regex("/blog/v[0-9]+/.*"),
+								Clusters:           clusters(service(s4)),
+							},
+						),
+					),
+				},
+			),
+		},
+		"insert httpproxy with direct response policy": {
+			objs: []interface{}{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "example-com",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/inline",
+							}},
+							DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+								StatusCode: 200,
+								Body:       "ok",
+							},
+						}, {
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/configmap",
+							}},
+							DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+								StatusCode: 503,
+								BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+									Name: "maintenance",
+									Key:  "body",
+								},
+							},
+						}, {
+							Services: []contour_api_v1.Service{{
+								Name: "kuard",
+								Port: 8080,
+							}},
+						}},
+					},
+				},
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "maintenance",
+						Namespace: "default",
+					},
+					Data: map[string]string{
+						"body": "down for maintenance",
+					},
+				},
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com",
+							&Route{
+								PathMatchCondition: prefixString("/inline"),
+								DirectResponse: &DirectResponse{
+									StatusCode: 200,
+									Body:       "ok",
+								},
+							},
+							&Route{
+								PathMatchCondition: prefixString("/configmap"),
+								DirectResponse: &DirectResponse{
+									StatusCode: 503,
+									Body:       "down for maintenance",
+								},
+							},
+							prefixroute("/", service(s1)),
+						),
+					),
+				},
+			),
+		},
+		"insert httpproxy with request redirect policy": {
+			objs: []interface{}{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "example-com",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "example.com",
+						},
+						Routes: []contour_api_v1.Route{{
+							Conditions: []contour_api_v1.MatchCondition{{
+								Prefix: "/old",
+							}},
+							RequestRedirectPolicy: &contour_api_v1.HTTPRequestRedirectPolicy{
+								Scheme:     pointer.StringPtr("https"),
+								Hostname:   pointer.StringPtr("envoyproxy.io"),
+								StatusCode: 301,
+								Prefix:     pointer.StringPtr("/new"),
+							},
+						}, {
+							Services: []contour_api_v1.Service{{
+								Name: "kuard",
+								Port: 8080,
+							}},
+						}},
+					},
+				},
+				s1,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com",
+							&Route{
+								PathMatchCondition: prefixString("/old"),
+								Redirect: &Redirect{
+									Scheme:     "https",
+									Hostname:   "envoyproxy.io",
+									StatusCode: 301,
+									Prefix:     "/new",
+								},
+							},
+							prefixroute("/", service(s1)),
+						),
+					),
+				},
+			),
+		},
 		"insert httpproxy with pathPrefix include, child adds to pathPrefix": {
 			objs: []interface{}{
 				proxy100, proxy100b, s1, s4,
@@ -9230,6 +9814,67 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
+		"insert proxy with cookie load balancing strategy and cookie options": {
+			objs: []interface{}{
+				proxyCookieLoadBalancerOptions,
+				s9,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters: []*Cluster{
+								{Upstream: service(s9), LoadBalancerPolicy: "Cookie"},
+							},
+							RequestHashPolicies: []RequestHashPolicy{
+								{
+									CookieHashOptions: &CookieHashOptions{
+										CookieName: "app-session",
+										TTL:        time.Hour,
+										Path:       "/app",
+									},
+								},
+							},
+						}),
+					),
+				},
+			),
+		},
+		"insert proxy with load balancer request cookie hash policies": {
+			objs: []interface{}{
+				proxyLoadBalancerHashPolicyCookie,
+				s9,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters: []*Cluster{
+								{Upstream: service(s9), LoadBalancerPolicy: "RequestHash"},
+							},
+							RequestHashPolicies: []RequestHashPolicy{
+								{
+									Terminal: true,
+									CookieHashOptions: &CookieHashOptions{
+										CookieName: "app-session",
+										Path:       "/",
+									},
+								},
+								{
+									HeaderHashOptions: &HeaderHashOptions{
+										HeaderName: "X-Some-Header",
+									},
+								},
+							},
+						}),
+					),
+				},
+			),
+		},
 		"insert proxy with load balancer request header hash policies": {
 			objs: []interface{}{
 				proxyLoadBalancerHashPolicyHeader,
@@ -9262,6 +9907,36 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
+		"insert proxy with load balancer source ip and query parameter hash policies": {
+			objs: []interface{}{
+				proxyLoadBalancerHashPolicySourceIPAndQueryParameter,
+				s9,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", &Route{
+							PathMatchCondition: prefixString("/"),
+							Clusters: []*Cluster{
+								{Upstream: service(s9), LoadBalancerPolicy: "RequestHash"},
+							},
+							RequestHashPolicies: []RequestHashPolicy{
+								{
+									QueryParameterHashOptions: &QueryParameterHashOptions{
+										ParameterName: "tenant",
+									},
+								},
+								{
+									Terminal:     true,
+									HashSourceIP: true,
+								},
+							},
+						}),
+					),
+				},
+			),
+		},
 		"insert proxy with all invalid request header hash policies": {
 			objs: []interface{}{
 				proxyLoadBalancerHashPolicyHeaderAllInvalid,
@@ -10349,12 +11024,8 @@ func TestValidateHeaderAlteration(t *testing.T) {
 		dyn: map[string]string{
 			"CONTOUR_NAMESPACE": "myns",
 		},
-		dhp: nil,
-		want: &HeadersPolicy{
-			Set: map[string]string{
-				"L5d-Dst-Override": "%%CONTOUR_SERVICE_NAME%%.myns.svc.cluster.local:%%CONTOUR_SERVICE_PORT%%",
-			},
-		},
+		dhp:     nil,
+		wantErr: errors.New(`invalid set header "L5d-Dst-Override": unknown header variable "%CONTOUR_SERVICE_NAME%"`),
 	}, {
 		name: "default headers are combined with given headers and escaped",
 		in: &contour_api_v1.HeadersPolicy{
@@ -10658,11 +11329,16 @@ func exact(path string) MatchCondition  { return &ExactMatchCondition{Path: path
 func regex(regex string) MatchCondition { return &RegexMatchCondition{Regex: regex} }
 
 func withMirror(r *Route, mirror *Service) *Route {
-	r.MirrorPolicy = &MirrorPolicy{
+	return withMirrorPercent(r, mirror, 100)
+}
+
+func withMirrorPercent(r *Route, mirror *Service, percent uint32) *Route {
+	r.MirrorPolicies = append(r.MirrorPolicies, &MirrorPolicy{
 		Cluster: &Cluster{
 			Upstream: mirror,
 		},
-	}
+		Percent: percent,
+	})
 	return r
 
 }
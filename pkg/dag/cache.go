@@ -57,6 +57,7 @@ type KubernetesCache struct {
 	ingressclass              *networking_v1.IngressClass
 	httpproxies               map[types.NamespacedName]*contour_api_v1.HTTPProxy
 	secrets                   map[types.NamespacedName]*v1.Secret
+	configmaps                map[types.NamespacedName]*v1.ConfigMap
 	tlscertificatedelegations map[types.NamespacedName]*contour_api_v1.TLSCertificateDelegation
 	services                  map[types.NamespacedName]*v1.Service
 	namespaces                map[string]*v1.Namespace
@@ -78,6 +79,7 @@ func (kc *KubernetesCache) init() {
 	kc.ingresses = make(map[types.NamespacedName]*networking_v1.Ingress)
 	kc.httpproxies = make(map[types.NamespacedName]*contour_api_v1.HTTPProxy)
 	kc.secrets = make(map[types.NamespacedName]*v1.Secret)
+	kc.configmaps = make(map[types.NamespacedName]*v1.ConfigMap)
 	kc.tlscertificatedelegations = make(map[types.NamespacedName]*contour_api_v1.TLSCertificateDelegation)
 	kc.services = make(map[types.NamespacedName]*v1.Service)
 	kc.namespaces = make(map[string]*v1.Namespace)
@@ -201,6 +203,9 @@ func (kc *KubernetesCache) Insert(obj interface{}) bool {
 
 		kc.secrets[k8s.NamespacedNameOf(obj)] = obj
 		return kc.secretTriggersRebuild(obj)
+	case *v1.ConfigMap:
+		kc.configmaps[k8s.NamespacedNameOf(obj)] = obj
+		return kc.configMapTriggersRebuild(obj)
 	case *v1.Service:
 		kc.services[k8s.NamespacedNameOf(obj)] = obj
 		return kc.serviceTriggersRebuild(obj)
@@ -391,6 +396,11 @@ func (kc *KubernetesCache) remove(obj interface{}) bool {
 		_, ok := kc.secrets[m]
 		delete(kc.secrets, m)
 		return ok
+	case *v1.ConfigMap:
+		m := k8s.NamespacedNameOf(obj)
+		_, ok := kc.configmaps[m]
+		delete(kc.configmaps, m)
+		return ok && kc.configMapTriggersRebuild(obj)
 	case *v1.Service:
 		m := k8s.NamespacedNameOf(obj)
 		_, ok := kc.services[m]
@@ -553,6 +563,12 @@ func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
 		return true
 	}
 
+	if _, isCRL := secret.Data[CRLKey]; isCRL {
+		// Revocation lists are only referenced by client
+		// validation, so treat them the same way as CA secrets.
+		return true
+	}
+
 	delegations := make(map[string]bool) // targetnamespace/secretname to bool
 
 	// TODO(youngnick): Check if this is required.
@@ -616,6 +632,33 @@ func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
 		}
 	}
 
+	// Basic authentication policies may be on any proxy, and on
+	// routes as well as virtual hosts. Their secret references follow
+	// the same delegation rules as TLS secrets.
+	for _, proxy := range kc.httpproxies {
+		var policies []*contour_api_v1.BasicAuthPolicy
+		if vh := proxy.Spec.VirtualHost; vh != nil {
+			policies = append(policies, vh.BasicAuth)
+		}
+		for _, route := range proxy.Spec.Routes {
+			policies = append(policies, route.BasicAuth)
+		}
+
+		for _, policy := range policies {
+			if policy == nil {
+				continue
+			}
+			if proxy.Namespace == secret.Namespace && policy.SecretName == secret.Name {
+				return true
+			}
+			if delegations[proxy.Namespace+"/"+secret.Name] || delegations["*/"+secret.Name] {
+				if policy.SecretName == secret.Namespace+"/"+secret.Name {
+					return true
+				}
+			}
+		}
+	}
+
 	// Secrets referred by the configuration file shall also trigger rebuild.
 	for _, s := range kc.ConfiguredSecretRefs {
 		if s.Namespace == secret.Namespace && s.Name == secret.Name {
@@ -644,6 +687,27 @@ func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
 	return false
 }
 
+// configMapTriggersRebuild returns true if this configmap is referenced
+// by an HTTPProxy in the same namespace.
+func (kc *KubernetesCache) configMapTriggersRebuild(configmap *v1.ConfigMap) bool {
+	for _, proxy := range kc.httpproxies {
+		if proxy.Namespace != configmap.Namespace {
+			continue
+		}
+		for _, route := range proxy.Spec.Routes {
+			dr := route.DirectResponsePolicy
+			if dr == nil || dr.BodyFromConfigMap == nil {
+				continue
+			}
+			if dr.BodyFromConfigMap.Name == configmap.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // LookupSecret returns a Secret if present or nil if the underlying kubernetes
 // secret fails validation or is missing.
 func (kc *KubernetesCache) LookupSecret(name types.NamespacedName, validate func(*v1.Secret) error) (*Secret, error) {
@@ -663,6 +727,24 @@ func (kc *KubernetesCache) LookupSecret(name types.NamespacedName, validate func
 	return s, nil
 }
 
+// LookupConfigMapKey returns the value of the key in the named ConfigMap,
+// or an error if the ConfigMap or key is missing.
+func (kc *KubernetesCache) LookupConfigMapKey(name types.NamespacedName, key string) (string, error) {
+	cm, ok := kc.configmaps[name]
+	if !ok {
+		return "", fmt.Errorf("ConfigMap not found")
+	}
+
+	if value, ok := cm.Data[key]; ok {
+		return value, nil
+	}
+	if value, ok := cm.BinaryData[key]; ok {
+		return string(value), nil
+	}
+
+	return "", fmt.Errorf("key %q not found in ConfigMap", key)
+}
+
 func (kc *KubernetesCache) LookupUpstreamValidation(uv *contour_api_v1.UpstreamValidation, namespace string) (*PeerValidationContext, error) {
 	if uv == nil {
 		// no upstream validation requested, nothing to do
@@ -695,9 +777,53 @@ func (kc *KubernetesCache) LookupDownstreamValidation(vc *contour_api_v1.Downstr
 		return nil, fmt.Errorf("invalid CA Secret %q: %s", secretName, err)
 	}
 
-	return &PeerValidationContext{
-		CACertificate: cacert,
-	}, nil
+	pvc := &PeerValidationContext{
+		CACertificate:             cacert,
+		OptionalClientCertificate: vc.OptionalClientCertificate,
+	}
+
+	if vc.CertificateRevocationList != "" {
+		secretName := types.NamespacedName{Name: vc.CertificateRevocationList, Namespace: namespace}
+		crl, err := kc.LookupSecret(secretName, validCRL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CRL Secret %q: %s", secretName, err)
+		}
+		pvc.CRL = crl
+	}
+
+	for _, san := range vc.SubjectAltNames {
+		if san == "" {
+			return nil, errors.New("empty subject alternative name")
+		}
+	}
+	pvc.SubjectAltNames = vc.SubjectAltNames
+
+	for _, subject := range vc.Subjects {
+		if subject == "" {
+			return nil, errors.New("empty subject")
+		}
+		// Subjects are embedded in the Lua code of the filter
+		// that checks them, so they are limited to characters
+		// that can be quoted for Lua.
+		for _, c := range subject {
+			if c < ' ' || c > '~' {
+				return nil, fmt.Errorf("subject %q must only contain printable ASCII characters", subject)
+			}
+		}
+	}
+	pvc.Subjects = vc.Subjects
+
+	if fcc := vc.ForwardClientCertificate; fcc != nil {
+		pvc.ForwardClientCertificate = &ClientCertificateDetails{
+			Subject: fcc.Subject,
+			Cert:    fcc.Cert,
+			Chain:   fcc.Chain,
+			DNS:     fcc.DNS,
+			URI:     fcc.URI,
+		}
+	}
+
+	return pvc, nil
 }
 
 // DelegationPermitted returns true if the referenced secret has been delegated
@@ -743,6 +869,14 @@ func validCA(s *v1.Secret) error {
 	return nil
 }
 
+func validCRL(s *v1.Secret) error {
+	if len(s.Data[CRLKey]) == 0 {
+		return fmt.Errorf("empty %q key", CRLKey)
+	}
+
+	return nil
+}
+
 // LookupService returns the Kubernetes service and port matching the provided parameters,
 // or an error if a match can't be found.
 func (kc *KubernetesCache) LookupService(meta types.NamespacedName, port intstr.IntOrString) (*v1.Service, v1.ServicePort, error) {
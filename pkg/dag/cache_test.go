@@ -249,6 +249,71 @@ func TestKubernetesCacheInsert(t *testing.T) {
 			},
 			want: true,
 		},
+		"insert configmap": {
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "configmap",
+					Namespace: "default",
+				},
+			},
+			want: false,
+		},
+		"insert configmap referenced by httpproxy": {
+			pre: []interface{}{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						Routes: []contour_api_v1.Route{{
+							DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+								StatusCode: 503,
+								BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+									Name: "configmap",
+									Key:  "body",
+								},
+							},
+						}},
+					},
+				},
+			},
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "configmap",
+					Namespace: "default",
+				},
+			},
+			want: true,
+		},
+		"insert configmap referenced by httpproxy in another namespace": {
+			pre: []interface{}{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						Routes: []contour_api_v1.Route{{
+							DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+								StatusCode: 503,
+								BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+									Name: "configmap",
+									Key:  "body",
+								},
+							},
+						}},
+					},
+				},
+			},
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "configmap",
+					Namespace: "other",
+				},
+			},
+			want: false,
+		},
 		"insert secret referenced by httpproxy": {
 			pre: []interface{}{
 				&contour_api_v1.HTTPProxy{
@@ -372,6 +437,19 @@ func TestKubernetesCacheInsert(t *testing.T) {
 			// any CA secret causes a rebuild.
 			want: true,
 		},
+		"insert certificate revocation list secret": {
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "crl",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					CRLKey: []byte(fixture.CRL),
+				},
+			},
+			want: true,
+		},
 		"insert certificate secret referenced by httpproxy": {
 			pre: []interface{}{
 				&contour_api_v1.HTTPProxy{
@@ -1033,6 +1111,55 @@ func TestKubernetesCacheRemove(t *testing.T) {
 			},
 			want: true,
 		},
+		"remove configmap": {
+			cache: cache(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "configmap",
+					Namespace: "default",
+				},
+			}),
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "configmap",
+					Namespace: "default",
+				},
+			},
+			want: false,
+		},
+		"remove configmap referenced by httpproxy": {
+			cache: cache(
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "simple",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						Routes: []contour_api_v1.Route{{
+							DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+								StatusCode: 503,
+								BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+									Name: "configmap",
+									Key:  "body",
+								},
+							},
+						}},
+					},
+				},
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "configmap",
+						Namespace: "default",
+					},
+				},
+			),
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "configmap",
+					Namespace: "default",
+				},
+			},
+			want: true,
+		},
 		"remove service": {
 			cache: cache(&v1.Service{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1626,6 +1753,19 @@ func TestSecretTriggersRebuild(t *testing.T) {
 		},
 	}
 
+	basicAuthSecret := func(namespace, name string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="),
+			},
+		}
+	}
+
 	tlsCertificateDelegation := func(namespace, name string, targetNamespaces ...string) *contour_api_v1.TLSCertificateDelegation {
 		return &contour_api_v1.TLSCertificateDelegation{
 			ObjectMeta: metav1.ObjectMeta{
@@ -1777,6 +1917,84 @@ func TestSecretTriggersRebuild(t *testing.T) {
 			secret: secret("default", "tlscert"),
 			want:   true,
 		},
+		"httpproxy vhost basic auth secret triggers rebuild": {
+			cache: cache(
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "proxy",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						VirtualHost: &contour_api_v1.VirtualHost{
+							Fqdn: "test.projectcontour.io",
+							BasicAuth: &contour_api_v1.BasicAuthPolicy{
+								SecretName: "htpasswd",
+							},
+						},
+					},
+				},
+			),
+			secret: basicAuthSecret("default", "htpasswd"),
+			want:   true,
+		},
+		"httpproxy route basic auth secret triggers rebuild": {
+			cache: cache(
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "proxy",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						Routes: []contour_api_v1.Route{{
+							BasicAuth: &contour_api_v1.BasicAuthPolicy{
+								SecretName: "htpasswd",
+							},
+						}},
+					},
+				},
+			),
+			secret: basicAuthSecret("default", "htpasswd"),
+			want:   true,
+		},
+		"httpproxy route with delegated basic auth secret triggers rebuild": {
+			cache: cache(
+				tlsCertificateDelegation("default", "htpasswd", "user"),
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "proxy",
+						Namespace: "user",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						Routes: []contour_api_v1.Route{{
+							BasicAuth: &contour_api_v1.BasicAuthPolicy{
+								SecretName: "default/htpasswd",
+							},
+						}},
+					},
+				},
+			),
+			secret: basicAuthSecret("default", "htpasswd"),
+			want:   true,
+		},
+		"httpproxy basic auth secret in another namespace does not trigger rebuild": {
+			cache: cache(
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "proxy",
+						Namespace: "user",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						Routes: []contour_api_v1.Route{{
+							BasicAuth: &contour_api_v1.BasicAuthPolicy{
+								SecretName: "htpasswd",
+							},
+						}},
+					},
+				},
+			),
+			secret: basicAuthSecret("default", "htpasswd"),
+			want:   false,
+		},
 		"configuration file secret triggers rebuild": {
 			cache: &KubernetesCache{
 				FieldLogger: fixture.NewTestLogger(t),
@@ -23,16 +23,44 @@ import (
 )
 
 // mergePathMatchConditions merges the given slice of prefix MatchConditions into a single
-// prefix Condition.
-// pathMatchConditionsValid guarantees that if a prefix is present, it will start with a
-// / character, so we can simply concatenate.
+// path Condition. The prefixes are concatenated and, if an exact or regex condition is
+// present, it is appended to the resulting prefix.
+// pathMatchConditionsValid guarantees that if a prefix or exact condition is present, it
+// will start with a / character, so we can simply concatenate.
 func mergePathMatchConditions(conds []contour_api_v1.MatchCondition) MatchCondition {
 	prefix := ""
+	exact := ""
+	regex := ""
 	for _, cond := range conds {
-		prefix = prefix + cond.Prefix
+		switch {
+		case cond.Exact != "":
+			exact = cond.Exact
+		case cond.Regex != "":
+			regex = cond.Regex
+		default:
+			prefix = prefix + cond.Prefix
+		}
 	}
 
 	re := regexp.MustCompile(`//+`)
+
+	switch {
+	case exact != "":
+		return &ExactMatchCondition{
+			Path: re.ReplaceAllString(prefix+exact, `/`),
+		}
+	case regex != "":
+		// The included prefixes are matched literally, so
+		// quote them before prepending them to the regex.
+		prefix = strings.TrimRight(re.ReplaceAllString(prefix, `/`), "/")
+		if prefix != "" && !strings.HasPrefix(regex, "/") {
+			prefix += "/"
+		}
+		return &RegexMatchCondition{
+			Regex: regexp.QuoteMeta(prefix) + regex,
+		}
+	}
+
 	prefix = re.ReplaceAllString(prefix, `/`)
 
 	// After the merge operation is done, if the string is still empty, then
@@ -50,20 +78,53 @@ func mergePathMatchConditions(conds []contour_api_v1.MatchCondition) MatchCondit
 }
 
 // pathMatchConditionsValid validates a slice of MatchConditions can be correctly merged.
-// It encodes the business rules about what is allowed for prefix MatchConditions.
+// It encodes the business rules about what is allowed for path MatchConditions.
 func pathMatchConditionsValid(conds []contour_api_v1.MatchCondition) error {
 	prefixCount := 0
+	pathCount := 0
 
 	for _, cond := range conds {
 		if cond.Prefix != "" {
 			prefixCount++
+			pathCount++
 			if cond.Prefix[0] != '/' {
 				return fmt.Errorf("prefix conditions must start with /, %s was supplied", cond.Prefix)
 			}
 		}
+		if cond.Exact != "" {
+			pathCount++
+			if cond.Exact[0] != '/' {
+				return fmt.Errorf("exact conditions must start with /, %s was supplied", cond.Exact)
+			}
+		}
+		if cond.Regex != "" {
+			pathCount++
+			if err := ValidateRegex(cond.Regex); err != nil {
+				return fmt.Errorf("invalid regex condition %q: %s", cond.Regex, err)
+			}
+		}
 		if prefixCount > 1 {
 			return errors.New("more than one prefix is not allowed in a condition block")
 		}
+		if pathCount > 1 {
+			return errors.New("more than one prefix, exact or regex condition is not allowed in a condition block")
+		}
+	}
+
+	return nil
+}
+
+// includeMatchConditionsValid validates the MatchConditions on an include.
+// Includes may only be matched by prefix, since an exact or regex
+// condition cannot be combined with the prefixes of the included routes.
+func includeMatchConditionsValid(conds []contour_api_v1.MatchCondition) error {
+	for _, cond := range conds {
+		if cond.Exact != "" {
+			return errors.New("exact conditions are not allowed in includes")
+		}
+		if cond.Regex != "" {
+			return errors.New("regex conditions are not allowed in includes")
+		}
 	}
 
 	return nil
@@ -116,6 +177,19 @@ func headerMatchConditions(conditions []contour_api_v1.HeaderMatchCondition) []H
 				MatchType: HeaderMatchTypeExact,
 				Invert:    true,
 			})
+		case cond.Regex != "":
+			hc = append(hc, HeaderMatchCondition{
+				Name:      cond.Name,
+				Value:     cond.Regex,
+				MatchType: HeaderMatchTypeRegex,
+			})
+		case cond.NotRegex != "":
+			hc = append(hc, HeaderMatchCondition{
+				Name:      cond.Name,
+				Value:     cond.NotRegex,
+				MatchType: HeaderMatchTypeRegex,
+				Invert:    true,
+			})
 		}
 	}
 	return hc
@@ -127,6 +201,8 @@ func headerMatchConditions(conditions []contour_api_v1.HeaderMatchCondition) []H
 //	- more than 1 'exact' condition for the same header
 //	- an 'exact' and a 'notexact' condition for the same header, with the same values
 //	- a 'contains' and a 'notcontains' condition for the same header, with the same values
+//	- a 'regex' or 'notregex' condition with an invalid regular expression
+//	- a 'regex' and a 'notregex' condition for the same header, with the same values
 //
 // Note that there are additional, more complex scenarios that we could check for here. For
 // example, "exact: foo" and "notcontains: <any substring of foo>" are contradictory.
@@ -179,6 +255,30 @@ func headerMatchConditionsValid(conditions []contour_api_v1.MatchCondition) erro
 			}] {
 				return errors.New("cannot specify contradictory 'contains' and 'notcontains' conditions for the same route and header")
 			}
+		case v.Header.Regex != "":
+			if err := ValidateRegex(v.Header.Regex); err != nil {
+				return fmt.Errorf("invalid regex for header condition %q: %s", v.Header.Name, err)
+			}
+
+			// look for a NotRegex condition on the same header with the same value
+			if seenMatchConditions[contour_api_v1.HeaderMatchCondition{
+				Name:     headerName,
+				NotRegex: v.Header.Regex,
+			}] {
+				return errors.New("cannot specify contradictory 'regex' and 'notregex' conditions for the same route and header")
+			}
+		case v.Header.NotRegex != "":
+			if err := ValidateRegex(v.Header.NotRegex); err != nil {
+				return fmt.Errorf("invalid regex for header condition %q: %s", v.Header.Name, err)
+			}
+
+			// look for a Regex condition on the same header with the same value
+			if seenMatchConditions[contour_api_v1.HeaderMatchCondition{
+				Name:  headerName,
+				Regex: v.Header.NotRegex,
+			}] {
+				return errors.New("cannot specify contradictory 'regex' and 'notregex' conditions for the same route and header")
+			}
 		}
 
 		key := *v.Header
@@ -190,6 +290,89 @@ func headerMatchConditionsValid(conditions []contour_api_v1.MatchCondition) erro
 	return nil
 }
 
+func mergeQueryParamMatchConditions(conds []contour_api_v1.MatchCondition) []QueryParamMatchCondition {
+	var qpc []QueryParamMatchCondition
+
+	for _, cond := range conds {
+		if cond.QueryParameter == nil {
+			continue
+		}
+
+		qp := cond.QueryParameter
+		switch {
+		case qp.Exact != "":
+			qpc = append(qpc, QueryParamMatchCondition{
+				Name:      qp.Name,
+				Value:     qp.Exact,
+				MatchType: QueryParamMatchTypeExact,
+			})
+		case qp.Prefix != "":
+			qpc = append(qpc, QueryParamMatchCondition{
+				Name:      qp.Name,
+				Value:     qp.Prefix,
+				MatchType: QueryParamMatchTypePrefix,
+			})
+		case qp.Regex != "":
+			qpc = append(qpc, QueryParamMatchCondition{
+				Name:      qp.Name,
+				Value:     qp.Regex,
+				MatchType: QueryParamMatchTypeRegex,
+			})
+		case qp.Present:
+			qpc = append(qpc, QueryParamMatchCondition{
+				Name:      qp.Name,
+				MatchType: QueryParamMatchTypePresent,
+			})
+		}
+	}
+	return qpc
+}
+
+// queryParamMatchConditionsValid validates that the query parameter conditions
+// within a slice of MatchConditions are valid. Specifically, it returns an error
+// for any of the following scenarios:
+//	- a condition that does not specify exactly one of exact, prefix, regex or present
+//	- a regex condition with an invalid regular expression
+//	- more than 1 'exact' condition for the same query parameter
+func queryParamMatchConditionsValid(conditions []contour_api_v1.MatchCondition) error {
+	paramsWithExactMatch := map[string]bool{}
+
+	for _, v := range conditions {
+		if v.QueryParameter == nil {
+			continue
+		}
+
+		qp := v.QueryParameter
+		if qp.Name == "" {
+			return errors.New("query parameter condition must specify a name")
+		}
+
+		count := 0
+		for _, set := range []bool{qp.Exact != "", qp.Prefix != "", qp.Regex != "", qp.Present} {
+			if set {
+				count++
+			}
+		}
+		if count != 1 {
+			return fmt.Errorf("query parameter condition for %q must specify exactly one of exact, prefix, regex or present", qp.Name)
+		}
+
+		switch {
+		case qp.Exact != "":
+			if paramsWithExactMatch[qp.Name] {
+				return errors.New("cannot specify duplicate query parameter 'exact match' conditions in the same route")
+			}
+			paramsWithExactMatch[qp.Name] = true
+		case qp.Regex != "":
+			if err := ValidateRegex(qp.Regex); err != nil {
+				return fmt.Errorf("invalid regex for query parameter condition %q: %s", qp.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // ValidateRegex returns an error if the supplied
 // RE2 regex syntax is invalid.
 func ValidateRegex(regex string) error {
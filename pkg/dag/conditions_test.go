@@ -82,6 +82,42 @@ func TestPathMatchCondition(t *testing.T) {
 			}},
 			want: &PrefixMatchCondition{Prefix: "/"},
 		},
+		"exact condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Exact: "/a",
+			}},
+			want: &ExactMatchCondition{Path: "/a"},
+		},
+		"exact condition with prefix": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/a/",
+			}, {
+				Exact: "/b",
+			}},
+			want: &ExactMatchCondition{Path: "/a/b"},
+		},
+		"regex condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Regex: "/v[0-9]+/.*",
+			}},
+			want: &RegexMatchCondition{Regex: "/v[0-9]+/.*"},
+		},
+		"regex condition with prefix": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/a.b/",
+			}, {
+				Regex: "/v[0-9]+/.*",
+			}},
+			want: &RegexMatchCondition{Regex: `/a\.b/v[0-9]+/.*`},
+		},
+		"regex condition without leading slash with prefix": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/a",
+			}, {
+				Regex: ".*\\.png",
+			}},
+			want: &RegexMatchCondition{Regex: `/a/.*\.png`},
+		},
 	}
 
 	for name, tc := range tests {
@@ -233,6 +269,33 @@ func TestHeaderMatchConditions(t *testing.T) {
 				Value:     "abcdef",
 			}},
 		},
+		"header regex": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:  "x-request-id",
+					Regex: "abc.*",
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      "x-request-id",
+				MatchType: "regex",
+				Value:     "abc.*",
+			}},
+		},
+		"header notregex": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:     "x-request-id",
+					NotRegex: "abc.*",
+				},
+			}},
+			want: []HeaderMatchCondition{{
+				Name:      "x-request-id",
+				MatchType: "regex",
+				Value:     "abc.*",
+				Invert:    true,
+			}},
+		},
 	}
 
 	for name, tc := range tests {
@@ -304,6 +367,46 @@ func TestPrefixMatchConditionsValid(t *testing.T) {
 			}},
 			want: false,
 		},
+		"valid exact condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Exact: "/api",
+			}},
+			want: true,
+		},
+		"invalid exact condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Exact: "api",
+			}},
+			want: false,
+		},
+		"valid regex condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Regex: "/api/v[0-9]+",
+			}},
+			want: true,
+		},
+		"invalid regex condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Regex: "/api/v[0-9+",
+			}},
+			want: false,
+		},
+		"prefix and exact matchconditions": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/api",
+			}, {
+				Exact: "/v1",
+			}},
+			want: false,
+		},
+		"exact and regex matchconditions": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Exact: "/api",
+			}, {
+				Regex: "/v1",
+			}},
+			want: false,
+		},
 	}
 
 	for name, tc := range tests {
@@ -314,6 +417,43 @@ func TestPrefixMatchConditionsValid(t *testing.T) {
 	}
 }
 
+func TestIncludeMatchConditionsValid(t *testing.T) {
+	tests := map[string]struct {
+		matchconditions []contour_api_v1.MatchCondition
+		want            bool
+	}{
+		"empty condition list": {
+			matchconditions: nil,
+			want:            true,
+		},
+		"prefix condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/api",
+			}},
+			want: true,
+		},
+		"exact condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Exact: "/api",
+			}},
+			want: false,
+		},
+		"regex condition": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Regex: "/api/.*",
+			}},
+			want: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := includeMatchConditionsValid(tc.matchconditions)
+			assert.Equal(t, tc.want, err == nil)
+		})
+	}
+}
+
 func TestValidateHeaderMatchConditions(t *testing.T) {
 	tests := map[string]struct {
 		matchconditions []contour_api_v1.MatchCondition
@@ -488,6 +628,47 @@ func TestValidateHeaderMatchConditions(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		"valid regex header": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:  "x-header",
+					Regex: "ab[cd]",
+				},
+			}},
+			wantErr: false,
+		},
+		"invalid regex header": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:  "x-header",
+					Regex: "ab[cd",
+				},
+			}},
+			wantErr: true,
+		},
+		"invalid notregex header": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:     "x-header",
+					NotRegex: "ab[cd",
+				},
+			}},
+			wantErr: true,
+		},
+		"regex and notregex with same value": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:  "x-header",
+					Regex: "ab[cd]",
+				},
+			}, {
+				Header: &contour_api_v1.HeaderMatchCondition{
+					Name:     "X-Header",
+					NotRegex: "ab[cd]",
+				},
+			}},
+			wantErr: true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -504,3 +685,172 @@ func TestValidateHeaderMatchConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryParamMatchConditions(t *testing.T) {
+	tests := map[string]struct {
+		matchconditions []contour_api_v1.MatchCondition
+		want            []QueryParamMatchCondition
+	}{
+		"empty condition list": {
+			matchconditions: nil,
+			want:            nil,
+		},
+		"prefix only": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/blog",
+			}},
+			want: nil,
+		},
+		"all match types": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "api-version",
+					Exact: "2021-01-01",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:   "search",
+					Prefix: "foo",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "id",
+					Regex: "[0-9]+",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:    "debug",
+					Present: true,
+				},
+			}},
+			want: []QueryParamMatchCondition{{
+				Name:      "api-version",
+				Value:     "2021-01-01",
+				MatchType: QueryParamMatchTypeExact,
+			}, {
+				Name:      "search",
+				Value:     "foo",
+				MatchType: QueryParamMatchTypePrefix,
+			}, {
+				Name:      "id",
+				Value:     "[0-9]+",
+				MatchType: QueryParamMatchTypeRegex,
+			}, {
+				Name:      "debug",
+				MatchType: QueryParamMatchTypePresent,
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := mergeQueryParamMatchConditions(tc.matchconditions)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestValidateQueryParamMatchConditions(t *testing.T) {
+	tests := map[string]struct {
+		matchconditions []contour_api_v1.MatchCondition
+		wantErr         bool
+	}{
+		"empty condition list": {
+			matchconditions: nil,
+			wantErr:         false,
+		},
+		"valid matchconditions": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				Prefix: "/blog",
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "api-version",
+					Exact: "v1",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "id",
+					Regex: "[0-9]+",
+				},
+			}},
+			wantErr: false,
+		},
+		"empty name": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Exact: "v1",
+				},
+			}},
+			wantErr: true,
+		},
+		"no match type": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name: "api-version",
+				},
+			}},
+			wantErr: true,
+		},
+		"more than one match type": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:    "api-version",
+					Exact:   "v1",
+					Present: true,
+				},
+			}},
+			wantErr: true,
+		},
+		"invalid regex": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "id",
+					Regex: "[0-9+",
+				},
+			}},
+			wantErr: true,
+		},
+		"duplicate exact matches": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "api-version",
+					Exact: "v1",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "api-version",
+					Exact: "v2",
+				},
+			}},
+			wantErr: true,
+		},
+		"exact matches on different parameters": {
+			matchconditions: []contour_api_v1.MatchCondition{{
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "api-version",
+					Exact: "v1",
+				},
+			}, {
+				QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+					Name:  "API-Version",
+					Exact: "v2",
+				},
+			}},
+			wantErr: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotErr := queryParamMatchConditionsValid(tc.matchconditions)
+
+			if !tc.wantErr {
+				assert.NoError(t, gotErr)
+			}
+
+			if tc.wantErr {
+				assert.Error(t, gotErr)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package dag
 import (
 	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -159,6 +160,10 @@ const (
 	// provided value.
 	HeaderMatchTypeContains = "contains"
 
+	// HeaderMatchTypeRegex matches a header value if it matches the
+	// provided regular expression.
+	HeaderMatchTypeRegex = "regex"
+
 	// HeaderMatchTypePresent matches a header if it is present in a request.
 	HeaderMatchTypePresent = "present"
 )
@@ -182,11 +187,84 @@ func (hc *HeaderMatchCondition) String() string {
 	return "header: " + details
 }
 
+const (
+	// QueryParamMatchTypeExact matches a query parameter value exactly.
+	QueryParamMatchTypeExact = "exact"
+
+	// QueryParamMatchTypePrefix matches a query parameter value if it
+	// starts with the provided value.
+	QueryParamMatchTypePrefix = "prefix"
+
+	// QueryParamMatchTypeRegex matches a query parameter value if it
+	// matches the provided regular expression.
+	QueryParamMatchTypeRegex = "regex"
+
+	// QueryParamMatchTypePresent matches a query parameter if it is
+	// present in a request.
+	QueryParamMatchTypePresent = "present"
+)
+
+// QueryParamMatchCondition matches request query parameters by MatchType
+type QueryParamMatchCondition struct {
+	Name      string
+	Value     string
+	MatchType string
+}
+
+func (qc *QueryParamMatchCondition) String() string {
+	details := strings.Join([]string{
+		"name=" + qc.Name,
+		"value=" + qc.Value,
+		"matchtype=" + qc.MatchType,
+	}, "&")
+
+	return "queryparam: " + details
+}
+
 // DirectResponse allows for a specific HTTP status code
 // to be the response to a route request vs routing to
 // an envoy cluster.
 type DirectResponse struct {
 	StatusCode uint32
+
+	// Body is the optional content of the response body.
+	Body string
+}
+
+// RegexRewrite rewrites the path of a request by replacing every
+// match of a regular expression with a substitution.
+type RegexRewrite struct {
+	// Pattern is the RE2 regular expression matched against the path.
+	Pattern string
+
+	// Substitution replaces each match of Pattern and may
+	// reference its capture groups as \1 to \9.
+	Substitution string
+}
+
+// Redirect allows for redirecting the request to
+// another URL instead of routing it to an envoy cluster.
+type Redirect struct {
+	// Scheme is the scheme to redirect to.
+	// If empty, the scheme of the request is used.
+	Scheme string
+
+	// Hostname is the hostname to redirect to.
+	// If empty, the hostname of the request is used.
+	Hostname string
+
+	// PortNumber is the port to redirect to.
+	// If zero, the port of the request is used.
+	PortNumber uint32
+
+	// StatusCode is the HTTP status code of the redirect response.
+	StatusCode int
+
+	// Path replaces the whole request path in the redirect.
+	Path string
+
+	// Prefix replaces the matched prefix (or path) in the redirect.
+	Prefix string
 }
 
 // Route defines the properties of a route to a Cluster.
@@ -200,6 +278,10 @@ type Route struct {
 	// match on the request headers.
 	HeaderMatchConditions []HeaderMatchCondition
 
+	// QueryParamMatchConditions specifies a set of additional Conditions to
+	// match on the request query parameters.
+	QueryParamMatchConditions []QueryParamMatchCondition
+
 	Clusters []*Cluster
 
 	// Should this route generate a 301 upgrade if accessed
@@ -214,6 +296,19 @@ type Route struct {
 	// AuthContext sets the authorization context (if authorization is enabled).
 	AuthContext map[string]string
 
+	// ExternalAuthorization, if not nil, overrides the external
+	// authorization server of the secure virtual host for this route.
+	ExternalAuthorization *ExternalAuthorization
+
+	// JWTProvider names the JWT provider, defined on the secure
+	// virtual host, that verifies requests to the route. If empty,
+	// JWT verification is disabled for the route.
+	JWTProvider string
+
+	// BasicAuth is the HTTP basic authentication policy for
+	// the route. If nil, basic authentication is disabled.
+	BasicAuth *BasicAuth
+
 	// Is this a websocket route?
 	// TODO(dfc) this should go on the service
 	Websocket bool
@@ -227,8 +322,11 @@ type Route struct {
 	// Indicates that during forwarding, the matched prefix (or path) should be swapped with this value
 	PrefixRewrite string
 
-	// Mirror Policy defines the mirroring policy for this Route.
-	MirrorPolicy *MirrorPolicy
+	// RegexRewrite rewrites the path of the request using a regular expression.
+	RegexRewrite *RegexRewrite
+
+	// MirrorPolicies defines the mirroring policies for this Route.
+	MirrorPolicies []*MirrorPolicy
 
 	// RequestHeadersPolicy defines how headers are managed during forwarding
 	RequestHeadersPolicy *HeadersPolicy
@@ -239,6 +337,18 @@ type Route struct {
 	// RateLimitPolicy defines if/how requests for the route are rate limited.
 	RateLimitPolicy *RateLimitPolicy
 
+	// FaultInjectionPolicy defines the delays and aborts injected into
+	// requests for the route.
+	FaultInjectionPolicy *FaultInjectionPolicy
+
+	// IPFilterAllow determines whether the IPFilterRules
+	// allow or deny the requests that match them.
+	IPFilterAllow bool
+
+	// IPFilterRules are the IP filter rules for the route. If
+	// set, they override the IP filter rules of the virtual host.
+	IPFilterRules []IPFilterRule
+
 	// RequestHashPolicies is a list of policies for configuring hashes on
 	// request attributes.
 	RequestHashPolicies []RequestHashPolicy
@@ -247,6 +357,10 @@ type Route struct {
 	// to be the response to a route request vs routing to
 	// an envoy cluster.
 	DirectResponse *DirectResponse
+
+	// Redirect allows for the request to be redirected
+	// to another URL vs routing to an envoy cluster.
+	Redirect *Redirect
 }
 
 // HasPathPrefix returns whether this route has a PrefixPathCondition.
@@ -287,11 +401,59 @@ type RetryPolicy struct {
 	// PerTryTimeout specifies the timeout per retry attempt.
 	// Ignored if RetryOn is blank.
 	PerTryTimeout timeout.Setting
+
+	// RetriableHeaders specifies the response headers under which retry takes place.
+	RetriableHeaders []HeaderMatchCondition
+
+	// RetriableRequestHeaders specifies the request headers that
+	// must match for retry to take place.
+	RetriableRequestHeaders []HeaderMatchCondition
+
+	// Backoff specifies the exponential backoff between retries.
+	// If nil, the Envoy default backoff is used.
+	Backoff *RetryBackoff
+
+	// HostPredicates specifies the predicates used to
+	// reject hosts when selecting the host for a retry.
+	HostPredicates []RetryHostPredicate
+
+	// HostSelectionMaxAttempts specifies the maximum number of
+	// times a host is selected for a retry attempt. Ignored if zero.
+	HostSelectionMaxAttempts int64
+}
+
+// RetryBackoff defines the exponential backoff between retries.
+type RetryBackoff struct {
+	// BaseInterval is the base interval between retries.
+	BaseInterval time.Duration
+
+	// MaxInterval is the maximum interval between retries.
+	// If zero, the Envoy default of ten times the base
+	// interval is used.
+	MaxInterval time.Duration
 }
 
+// RetryHostPredicate is a predicate used to reject hosts
+// when selecting the host for a retry.
+type RetryHostPredicate string
+
+const (
+	// RetryHostPredicatePreviousHosts rejects hosts
+	// that have already been attempted.
+	RetryHostPredicatePreviousHosts RetryHostPredicate = "previous-hosts"
+
+	// RetryHostPredicateOmitCanaryHosts rejects hosts
+	// that are marked as canary hosts.
+	RetryHostPredicateOmitCanaryHosts RetryHostPredicate = "omit-canary-hosts"
+)
+
 // MirrorPolicy defines the mirroring policy for a route.
 type MirrorPolicy struct {
 	Cluster *Cluster
+
+	// Percent is the percentage of requests that are mirrored
+	// to the Cluster, in the range 1-100.
+	Percent uint32
 }
 
 // HeadersPolicy defines how headers are managed during forwarding
@@ -319,15 +481,46 @@ type LocalRateLimitPolicy struct {
 	ResponseHeadersToAdd map[string]string
 }
 
+// FaultInjectionPolicy holds fault injection parameters.
+type FaultInjectionPolicy struct {
+	// Delay is the fixed delay applied to requests, if any.
+	Delay *FaultDelay
+
+	// Abort is the error response returned to requests, if any.
+	Abort *FaultAbort
+
+	// HeaderMatchConditions restricts fault injection to requests
+	// that match all of the conditions.
+	HeaderMatchConditions []HeaderMatchCondition
+}
+
+// FaultDelay holds the parameters of a fixed delay fault.
+type FaultDelay struct {
+	Duration time.Duration
+	Percent  uint32
+}
+
+// FaultAbort holds the parameters of an abort fault.
+type FaultAbort struct {
+	StatusCode uint32
+	Percent    uint32
+}
+
 // HeaderHashOptions contains options for hashing a HTTP header.
 type HeaderHashOptions struct {
 	// HeaderName is the name of the header to hash.
 	HeaderName string
 }
 
+// QueryParameterHashOptions contains options for hashing a request query parameter.
+type QueryParameterHashOptions struct {
+	// ParameterName is the name of the query parameter to hash.
+	ParameterName string
+}
+
 // CookieHashOptions contains options for hashing a HTTP cookie.
 type CookieHashOptions struct {
-	// CookieName is the name of the header to hash.
+	// CookieName is the name of the cookie to hash.
 	CookieName string
 
 	// TTL is how long the cookie should be valid for.
@@ -349,6 +542,12 @@ type RequestHashPolicy struct {
 
 	// CookieHashOptions is set when a cookie hash is desired.
 	CookieHashOptions *CookieHashOptions
+
+	// QueryParameterHashOptions is set when a query parameter hash is desired.
+	QueryParameterHashOptions *QueryParameterHashOptions
+
+	// HashSourceIP is set to true when source ip hashing is desired.
+	HashSourceIP bool
 }
 
 // GlobalRateLimitPolicy holds global rate limiting parameters.
@@ -426,6 +625,31 @@ type PeerValidationContext struct {
 	// SubjectName holds an optional subject name which Envoy will check against the
 	// certificate presented by the upstream.
 	SubjectName string
+	// CRL holds a reference to the Secret containing the certificate revocation
+	// list used to verify the downstream connection.
+	CRL *Secret
+	// OptionalClientCertificate, if true, allows downstream clients to
+	// connect without presenting a certificate.
+	OptionalClientCertificate bool
+	// SubjectAltNames lists the subject alternative names of which
+	// the certificate presented by the downstream must contain one.
+	SubjectAltNames []string
+	// Subjects lists the subjects that are allowed in the certificate
+	// presented by the downstream.
+	Subjects []string
+	// ForwardClientCertificate selects the details of the certificate
+	// presented by the downstream that are forwarded to upstreams.
+	ForwardClientCertificate *ClientCertificateDetails
+}
+
+// ClientCertificateDetails selects the details of a client certificate
+// that are forwarded in the x-forwarded-client-cert header.
+type ClientCertificateDetails struct {
+	Subject bool
+	Cert    bool
+	Chain   bool
+	DNS     bool
+	URI     bool
 }
 
 // GetCACertificate returns the CA certificate from PeerValidationContext.
@@ -437,6 +661,24 @@ func (pvc *PeerValidationContext) GetCACertificate() []byte {
 	return pvc.CACertificate.Object.Data[CACertificateKey]
 }
 
+// GetCRL returns the certificate revocation list from PeerValidationContext.
+func (pvc *PeerValidationContext) GetCRL() []byte {
+	if pvc == nil || pvc.CRL == nil {
+		// No revocation list required.
+		return nil
+	}
+	return pvc.CRL.Object.Data[CRLKey]
+}
+
+// GetForwardClientCertificate returns the ForwardClientCertificate from PeerValidationContext.
+func (pvc *PeerValidationContext) GetForwardClientCertificate() *ClientCertificateDetails {
+	if pvc == nil {
+		// No client certificate to forward.
+		return nil
+	}
+	return pvc.ForwardClientCertificate
+}
+
 // GetSubjectName returns the SubjectName from PeerValidationContext.
 func (pvc *PeerValidationContext) GetSubjectName() string {
 	if pvc == nil {
@@ -452,8 +694,10 @@ func (r *Route) Visit(f func(Vertex)) {
 	}
 	// Allow any mirror clusters to also be visited so that
 	// they are also added to CDS.
-	if r.MirrorPolicy != nil && r.MirrorPolicy.Cluster != nil {
-		f(r.MirrorPolicy.Cluster)
+	for _, mp := range r.MirrorPolicies {
+		if mp.Cluster != nil {
+			f(mp.Cluster)
+		}
 	}
 }
 
@@ -472,9 +716,36 @@ type VirtualHost struct {
 	// are rate limited.
 	RateLimitPolicy *RateLimitPolicy
 
+	// RequestHeadersPolicy defines how request headers are managed
+	// for all routes of the virtual host.
+	RequestHeadersPolicy *HeadersPolicy
+
+	// ResponseHeadersPolicy defines how response headers are managed
+	// for all routes of the virtual host.
+	ResponseHeadersPolicy *HeadersPolicy
+
+	// IPFilterAllow determines whether the IPFilterRules
+	// allow or deny the requests that match them.
+	IPFilterAllow bool
+
+	// IPFilterRules are the IP filter rules that apply to all
+	// routes of the virtual host, unless a route has its own.
+	IPFilterRules []IPFilterRule
+
 	routes map[string]*Route
 }
 
+// IPFilterRule is a pre-validated IP filter rule.
+type IPFilterRule struct {
+	// Remote determines whether the rule matches the IP address
+	// of the client, as determined from X-Forwarded-For or PROXY
+	// protocol, or the IP address of the directly connected peer.
+	Remote bool
+
+	// CIDR is the block of addresses that the rule matches.
+	CIDR net.IPNet
+}
+
 func (v *VirtualHost) addRoute(route *Route) {
 	if v.routes == nil {
 		v.routes = make(map[string]*Route)
@@ -487,6 +758,9 @@ func conditionsToString(r *Route) string {
 	for _, cond := range r.HeaderMatchConditions {
 		s = append(s, cond.String())
 	}
+	for _, cond := range r.QueryParamMatchConditions {
+		s = append(s, cond.String())
+	}
 	return strings.Join(s, ",")
 }
 
@@ -520,9 +794,20 @@ type SecureVirtualHost struct {
 	// DownstreamValidation defines how to verify the client's certificate.
 	DownstreamValidation *PeerValidationContext
 
-	// AuthorizationService points to the extension that client
+	// ExternalAuthorization configures the extension that client
 	// requests are forwarded to for authorization. If nil, no
 	// authorization is enabled for this host.
+	ExternalAuthorization *ExternalAuthorization
+
+	// JWTProviders specify how to verify JWTs on requests
+	// to this host.
+	JWTProviders []JWTProvider
+}
+
+// ExternalAuthorization configures an external authorization server.
+type ExternalAuthorization struct {
+	// AuthorizationService points to the extension that client
+	// requests are forwarded to for authorization.
 	AuthorizationService *ExtensionCluster
 
 	// AuthorizationResponseTimeout sets how long the proxy should wait
@@ -534,6 +819,50 @@ type SecureVirtualHost struct {
 	// only reason to set this to `true` is when you are migrating
 	// from internal to external authorization.
 	AuthorizationFailOpen bool
+
+	// WithRequestBody, if not nil, configures the buffering of
+	// request bodies that are sent to the authorization server.
+	WithRequestBody *AuthorizationBufferSettings
+
+	// AllowedRequestHeaders lists the request headers that are
+	// sent to the authorization server. If empty, all headers
+	// are sent.
+	AllowedRequestHeaders []string
+
+	// AllowedUpstreamHeaders lists the authorization response
+	// headers that are added to allowed requests.
+	AllowedUpstreamHeaders []string
+
+	// AllowedClientHeaders lists the authorization response
+	// headers that are sent to clients of denied requests.
+	AllowedClientHeaders []string
+
+	// StatusOnDeny is the status code for denied requests. If
+	// zero, the Envoy default of 403 (Forbidden) is used.
+	StatusOnDeny uint32
+
+	// IncludeClientCertificate sets whether the client certificate
+	// is sent to the authorization server.
+	IncludeClientCertificate bool
+
+	// HTTPService, if not nil, configures the authorization server
+	// to be sent HTTP requests instead of gRPC check requests.
+	HTTPService *AuthorizationHTTPService
+}
+
+// AuthorizationHTTPService configures an authorization server that
+// accepts HTTP requests.
+type AuthorizationHTTPService struct {
+	// PathPrefix is prepended to the path of authorization requests.
+	PathPrefix string
+}
+
+// AuthorizationBufferSettings configures how request bodies are
+// buffered for an authorization server.
+type AuthorizationBufferSettings struct {
+	MaxRequestBytes     uint32
+	AllowPartialMessage bool
+	PackAsBytes         bool
 }
 
 func (s *SecureVirtualHost) Visit(f func(Vertex)) {
@@ -544,8 +873,98 @@ func (s *SecureVirtualHost) Visit(f func(Vertex)) {
 	if s.Secret != nil {
 		f(s.Secret) // secret is not required if vhost is using tls passthrough
 	}
+	for _, provider := range s.JWTProviders {
+		if provider.RemoteJWKS != nil {
+			f(&provider.RemoteJWKS.Cluster)
+		}
+	}
 }
 
+// BasicAuth is a pre-validated HTTP basic authentication policy.
+type BasicAuth struct {
+	// Secret is the Secret that holds the credentials.
+	Secret types.NamespacedName
+
+	// Realm is presented to clients that fail to authenticate.
+	Realm string
+
+	// Users maps the user names that may authenticate
+	// to their password hashes.
+	Users map[string]string
+}
+
+// JWTProvider is a pre-validated JWT provider.
+type JWTProvider struct {
+	// Name is the unique name of the provider
+	// within the virtual host.
+	Name string
+
+	// Issuer that JWTs are required to have.
+	// If empty, the issuer is not checked.
+	Issuer string
+
+	// Audiences that JWTs are allowed to have.
+	// If empty, the audience is not checked.
+	Audiences []string
+
+	// RemoteJWKS defines where to fetch the JWKS from.
+	// If nil, LocalJWKS is used.
+	RemoteJWKS *RemoteJWKS
+
+	// LocalJWKS is an inline JWKS.
+	LocalJWKS string
+
+	// ForwardJWT determines whether the JWT is forwarded
+	// to the upstream after verification.
+	ForwardJWT bool
+
+	// ClaimsToHeaders lists the claims of verified JWTs
+	// that are forwarded to the upstream as request headers.
+	ClaimsToHeaders []JWTClaimToHeader
+}
+
+// RemoteJWKS defines how to fetch a JWKS from an HTTP endpoint.
+type RemoteJWKS struct {
+	// URI is the URI of the JWKS.
+	URI string
+
+	// Timeout is how long to wait for a response from the URI.
+	Timeout time.Duration
+
+	// CacheDuration is how long to cache the JWKS for.
+	// Zero selects the Envoy default.
+	CacheDuration time.Duration
+
+	// Cluster is the cluster that the JWKS is fetched through.
+	Cluster DNSNameCluster
+}
+
+// JWTClaimToHeader forwards a JWT claim as a request header.
+type JWTClaimToHeader struct {
+	Claim  string
+	Header string
+}
+
+// DNSNameCluster is a cluster that routes directly to a DNS
+// name (i.e. not a Kubernetes service).
+type DNSNameCluster struct {
+	// Address is the DNS name or IP address of the cluster.
+	Address string
+
+	// Scheme is the URI scheme used to reach the cluster,
+	// either "http" or "https".
+	Scheme string
+
+	// Port is the port number of the cluster.
+	Port int
+
+	// UpstreamValidation defines how to verify the cluster's
+	// certificate when the scheme is "https".
+	UpstreamValidation *PeerValidationContext
+}
+
+func (c *DNSNameCluster) Visit(func(Vertex)) {}
+
 func (s *SecureVirtualHost) Valid() bool {
 	// A SecureVirtualHost is valid if either
 	// 1. it has a secret and at least one route.
@@ -620,6 +1039,10 @@ type Service struct {
 
 	// ExternalName is an optional field referencing a dns entry for Service type "ExternalName"
 	ExternalName string
+
+	// LocalityFailover sets whether the endpoints of this service
+	// are prioritized by their distance from each Envoy.
+	LocalityFailover bool
 }
 
 // Visit applies the visitor function to the Service vertex.
@@ -637,6 +1060,7 @@ func (s *Service) Visit(f func(Vertex)) {
 		Services: []WeightedService{
 			s.Weighted,
 		},
+		LocalityFailover: s.LocalityFailover,
 	}
 
 	f(&c)
@@ -668,6 +1092,14 @@ type Cluster struct {
 	// Cluster tcp health check policy
 	*TCPHealthCheckPolicy
 
+	// OutlierDetection defines how hosts of the cluster are passively
+	// health checked.
+	OutlierDetection *OutlierDetection
+
+	// CircuitBreakerPolicy defines the circuit breaking thresholds of
+	// the cluster. If nil, the thresholds of the Upstream are used.
+	CircuitBreakerPolicy *CircuitBreakerPolicy
+
 	// RequestHeadersPolicy defines how headers are managed during forwarding
 	RequestHeadersPolicy *HeadersPolicy
 
@@ -723,14 +1155,18 @@ type ServiceCluster struct {
 	ClusterName string
 	// Services are the load balancing targets. This slice must not be empty.
 	Services []WeightedService
+	// LocalityFailover sets whether the endpoints are prioritized
+	// by their distance from the Envoy that requests them.
+	LocalityFailover bool
 }
 
 // DeepCopy performs a deep copy of ServiceClusters
 // TODO(jpeach): apply deepcopy-gen to DAG objects.
 func (s *ServiceCluster) DeepCopy() *ServiceCluster {
 	s2 := ServiceCluster{
-		ClusterName: s.ClusterName,
-		Services:    make([]WeightedService, len(s.Services)),
+		ClusterName:      s.ClusterName,
+		Services:         make([]WeightedService, len(s.Services)),
+		LocalityFailover: s.LocalityFailover,
 	}
 
 	for i, w := range s.Services {
@@ -846,6 +1282,25 @@ type TCPHealthCheckPolicy struct {
 	HealthyThreshold   uint32
 }
 
+// OutlierDetection holds the passive health checking parameters of a
+// cluster. Zero values select the Envoy defaults.
+type OutlierDetection struct {
+	ConsecutiveServerErrors  uint32
+	ConsecutiveGatewayErrors uint32
+	Interval                 time.Duration
+	BaseEjectionTime         time.Duration
+	MaxEjectionPercent       uint32
+}
+
+// CircuitBreakerPolicy holds the circuit breaking thresholds of a
+// cluster. Zero values select the Envoy defaults.
+type CircuitBreakerPolicy struct {
+	MaxConnections     uint32
+	MaxPendingRequests uint32
+	MaxRequests        uint32
+	MaxRetries         uint32
+}
+
 // ExtensionCluster generates an Envoy cluster (aka ClusterLoadAssignment)
 // for an ExtensionService resource.
 type ExtensionCluster struct {
@@ -874,6 +1329,10 @@ type ExtensionCluster struct {
 	// ClientCertificate is the optional identifier of the TLS secret containing client certificate and
 	// private key to be used when establishing TLS connection to upstream cluster.
 	ClientCertificate *Secret
+
+	// CircuitBreakerPolicy defines the circuit breaking thresholds of
+	// this cluster, or nil to use the Envoy defaults.
+	CircuitBreakerPolicy *CircuitBreakerPolicy
 }
 
 // Visit processes extension clusters.
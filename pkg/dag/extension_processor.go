@@ -35,6 +35,10 @@ type ExtensionServiceProcessor struct {
 	// secret containing client certificate and private key to be
 	// used when establishing TLS connection to upstream cluster.
 	ClientCertificate *types.NamespacedName
+
+	// CircuitBreakerDefaults holds the circuit breaking thresholds
+	// of extension service clusters (optional).
+	CircuitBreakerDefaults *CircuitBreakerPolicy
 }
 
 var _ Processor = &ExtensionServiceProcessor{}
@@ -108,6 +112,8 @@ func (p *ExtensionServiceProcessor) buildExtensionService(
 		TimeoutPolicy:      tp,
 		SNI:                "",
 		ClientCertificate:  clientCertSecret,
+
+		CircuitBreakerPolicy: p.CircuitBreakerDefaults,
 	}
 
 	lbPolicy := loadBalancerPolicy(ext.Spec.LoadBalancerPolicy)
@@ -131,7 +137,7 @@ func (p *ExtensionServiceProcessor) buildExtensionService(
 			".Spec.TimeoutPolicy.Idle")
 	}
 
-	// API server validation ensures that the protocol is "h2" or "h2c".
+	// API server validation ensures that the protocol is "h2", "h2c" or "http/1.1".
 	if ext.Spec.Protocol != nil {
 		extension.Protocol = stringOrDefault(*ext.Spec.Protocol, extension.Protocol)
 	}
@@ -40,6 +40,10 @@ type GatewayAPIProcessor struct {
 
 	dag    *DAG
 	source *KubernetesCache
+
+	// CircuitBreakerDefaults holds the circuit breaking thresholds
+	// used when the Service annotations do not set them (optional).
+	CircuitBreakerDefaults *CircuitBreakerPolicy
 }
 
 // matchConditions holds match rules.
@@ -273,6 +277,8 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1alpha1.HTTPRo
 
 		for _, match := range rule.Matches {
 			mc := &matchConditions{}
+			invalid := false
+
 			// TODO: Replace this with nil check when gateway-api includes this fix -
 			// https://github.com/kubernetes-sigs/gateway-api/commit/9d63656df8cc9e67da60d4fe3f6289aad22e72d3
 			if match.Path.Value == "" || match.Path.Type == "" {
@@ -293,10 +299,27 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1alpha1.HTTPRo
 					for k, v := range match.Headers.Values {
 						mc.headerMatchCondition = append(mc.headerMatchCondition, HeaderMatchCondition{MatchType: HeaderMatchTypeExact, Name: k, Value: v})
 					}
+				case gatewayapi_v1alpha1.HeaderMatchRegularExpression:
+					for k, v := range match.Headers.Values {
+						if err := ValidateRegex(v); err != nil {
+							routeAccessor.AddCondition(status.ConditionResolvedRefs, metav1.ConditionFalse, status.ReasonDegraded, fmt.Sprintf("HTTPRoute.Spec.Rules.HeaderMatch: invalid regex for header %q: %s", k, err))
+							invalid = true
+							continue
+						}
+						mc.headerMatchCondition = append(mc.headerMatchCondition, HeaderMatchCondition{MatchType: HeaderMatchTypeRegex, Name: k, Value: v})
+					}
 				default:
-					routeAccessor.AddCondition(status.ConditionNotImplemented, metav1.ConditionTrue, status.ReasonHeaderMatchType, "HTTPRoute.Spec.Rules.HeaderMatch: Only Exact match type is supported.")
+					routeAccessor.AddCondition(status.ConditionNotImplemented, metav1.ConditionTrue, status.ReasonHeaderMatchType, "HTTPRoute.Spec.Rules.HeaderMatch: Only Exact and RegularExpression match types are supported.")
 				}
 			}
+
+			// A match with an invalid condition is dropped rather
+			// than programmed without that condition, which would
+			// match more requests than intended.
+			if invalid {
+				continue
+			}
+
 			matchconditions = append(matchconditions, mc)
 		}
 
@@ -356,6 +379,7 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1alpha1.HTTPRo
 		}
 
 		var headerPolicy *HeadersPolicy
+		var mirrorPolicies []*MirrorPolicy
 		for _, filter := range rule.Filters {
 			switch filter.Type {
 			case gatewayapi_v1alpha1.HTTPRouteFilterRequestHeaderModifier:
@@ -364,12 +388,16 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1alpha1.HTTPRo
 				if err != nil {
 					routeAccessor.AddCondition(status.ConditionResolvedRefs, metav1.ConditionFalse, status.ReasonDegraded, fmt.Sprintf("%s on request headers", err))
 				}
+			case gatewayapi_v1alpha1.HTTPRouteFilterRequestMirror:
+				if mirrorPolicy := p.mirrorPolicy(routeAccessor, route.Namespace, filter.RequestMirror); mirrorPolicy != nil {
+					mirrorPolicies = append(mirrorPolicies, mirrorPolicy)
+				}
 			default:
-				routeAccessor.AddCondition(status.ConditionNotImplemented, metav1.ConditionTrue, status.ReasonHTTPRouteFilterType, "HTTPRoute.Spec.Rules.Filters: Only RequestHeaderModifier type is supported.")
+				routeAccessor.AddCondition(status.ConditionNotImplemented, metav1.ConditionTrue, status.ReasonHTTPRouteFilterType, "HTTPRoute.Spec.Rules.Filters: Only RequestHeaderModifier and RequestMirror types are supported.")
 			}
 		}
 
-		routes := p.routes(matchconditions, headerPolicy, clusters)
+		routes := p.routes(matchconditions, headerPolicy, mirrorPolicies, clusters)
 		for _, host := range hosts {
 			for _, route := range routes {
 				// If there aren't any valid services, or the total weight of all of
@@ -405,8 +433,8 @@ func (p *GatewayAPIProcessor) computeHTTPRoute(route *gatewayapi_v1alpha1.HTTPRo
 	}
 }
 
-// routes builds a []*dag.Route for the supplied set of matchConditions, headerPolicy and clusters.
-func (p *GatewayAPIProcessor) routes(matchConditions []*matchConditions, headerPolicy *HeadersPolicy, clusters []*Cluster) []*Route {
+// routes builds a []*dag.Route for the supplied set of matchConditions, headerPolicy, mirrorPolicies and clusters.
+func (p *GatewayAPIProcessor) routes(matchConditions []*matchConditions, headerPolicy *HeadersPolicy, mirrorPolicies []*MirrorPolicy, clusters []*Cluster) []*Route {
 	var routes []*Route
 
 	for _, mc := range matchConditions {
@@ -417,6 +445,7 @@ func (p *GatewayAPIProcessor) routes(matchConditions []*matchConditions, headerP
 			r.PathMatchCondition = pathMatch
 			r.HeaderMatchConditions = mc.headerMatchCondition
 			r.RequestHeadersPolicy = headerPolicy
+			r.MirrorPolicies = mirrorPolicies
 			routes = append(routes, r)
 		}
 	}
@@ -426,10 +455,43 @@ func (p *GatewayAPIProcessor) routes(matchConditions []*matchConditions, headerP
 
 // cluster builds a *dag.Cluster for the supplied set of headerPolicy and service.
 func (p *GatewayAPIProcessor) cluster(headerPolicy *HeadersPolicy, service *Service, weight uint32) *Cluster {
+	// Gateway API has no way to set circuit breakers other than the
+	// Service annotations, so this never fails.
+	cb, _ := circuitBreakerPolicy(service, nil, p.CircuitBreakerDefaults)
+
 	return &Cluster{
 		Upstream:             service,
 		Weight:               weight,
 		Protocol:             service.Protocol,
 		RequestHeadersPolicy: headerPolicy,
+		CircuitBreakerPolicy: cb,
+	}
+}
+
+// mirrorPolicy builds a *dag.MirrorPolicy for the supplied RequestMirror filter.
+// If the filter is invalid, the error is recorded on the route's status and nil
+// is returned.
+func (p *GatewayAPIProcessor) mirrorPolicy(routeAccessor *status.HTTPRouteUpdate, namespace string, mirror *gatewayapi_v1alpha1.HTTPRequestMirrorFilter) *MirrorPolicy {
+	if mirror == nil || mirror.ServiceName == nil {
+		routeAccessor.AddCondition(status.ConditionResolvedRefs, metav1.ConditionFalse, status.ReasonDegraded, "Spec.Rules.Filters.RequestMirror.ServiceName must be specified.")
+		return nil
+	}
+
+	// TODO: Do not require port to be present (#3352).
+	if mirror.Port == nil {
+		routeAccessor.AddCondition(status.ConditionResolvedRefs, metav1.ConditionFalse, status.ReasonDegraded, "Spec.Rules.Filters.RequestMirror.Port must be specified.")
+		return nil
+	}
+
+	meta := types.NamespacedName{Name: *mirror.ServiceName, Namespace: namespace}
+	service, err := p.dag.EnsureService(meta, intstr.FromInt(int(*mirror.Port)), p.source)
+	if err != nil {
+		routeAccessor.AddCondition(status.ConditionResolvedRefs, metav1.ConditionFalse, status.ReasonDegraded, fmt.Sprintf("Service %q does not exist", meta.Name))
+		return nil
+	}
+
+	return &MirrorPolicy{
+		Cluster: p.cluster(nil, service, 0),
+		Percent: 100,
 	}
 }
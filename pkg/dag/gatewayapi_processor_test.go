@@ -202,3 +202,50 @@ func TestComputeHosts(t *testing.T) {
 		})
 	}
 }
+
+func TestGatewayAPIClusterCircuitBreakers(t *testing.T) {
+	defaults := &CircuitBreakerPolicy{
+		MaxConnections: 100,
+		MaxRetries:     5,
+	}
+
+	tests := map[string]struct {
+		service  *Service
+		defaults *CircuitBreakerPolicy
+		want     *CircuitBreakerPolicy
+	}{
+		"no defaults": {
+			service: &Service{MaxConnections: 9000},
+			want:    nil,
+		},
+		"defaults only": {
+			service:  &Service{},
+			defaults: defaults,
+			want: &CircuitBreakerPolicy{
+				MaxConnections: 100,
+				MaxRetries:     5,
+			},
+		},
+		"annotations override defaults": {
+			service:  &Service{MaxConnections: 9000, MaxRequests: 50},
+			defaults: defaults,
+			want: &CircuitBreakerPolicy{
+				MaxConnections: 9000,
+				MaxRequests:    50,
+				MaxRetries:     5,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			processor := &GatewayAPIProcessor{
+				FieldLogger:            fixture.NewTestLogger(t),
+				CircuitBreakerDefaults: tc.defaults,
+			}
+
+			got := processor.cluster(nil, tc.service, 1)
+			assert.Equal(t, tc.want, got.CircuitBreakerPolicy)
+		})
+	}
+}
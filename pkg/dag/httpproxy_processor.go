@@ -14,6 +14,7 @@
 package dag
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -29,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // defaultExtensionRef populates the unset fields in ref with default values.
@@ -76,6 +78,11 @@ type HTTPProxyProcessor struct {
 
 	// Response headers that will be set on all routes (optional).
 	ResponseHeadersPolicy *HeadersPolicy
+
+	// CircuitBreakerDefaults holds the circuit breaking thresholds
+	// used when neither the HTTPProxy nor the Service annotations
+	// set them (optional).
+	CircuitBreakerDefaults *CircuitBreakerPolicy
 }
 
 // Run translates HTTPProxies into DAG objects and
@@ -148,6 +155,34 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 		return
 	}
 
+	if len(proxy.Spec.VirtualHost.JWTProviders) > 0 {
+		if tls := proxy.Spec.VirtualHost.TLS; tls == nil || tls.Passthrough || proxy.Spec.TCPProxy != nil {
+			validCond.AddError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTProvidersRequireTLS",
+				"Spec.VirtualHost.JWTProviders can only be defined for root HTTPProxies that terminate TLS and route HTTP requests")
+			return
+		}
+	}
+
+	if auth := proxy.Spec.VirtualHost.BasicAuth; auth != nil {
+		if tls := proxy.Spec.VirtualHost.TLS; tls == nil || tls.Passthrough || proxy.Spec.TCPProxy != nil {
+			validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthRequiresTLS",
+				"Spec.VirtualHost.BasicAuth can only be defined for root HTTPProxies that terminate TLS and route HTTP requests")
+			return
+		}
+
+		if auth.Disabled {
+			validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthPolicyNotValid",
+				"Spec.VirtualHost.BasicAuth is invalid: disabled can only be specified on a route")
+			return
+		}
+
+		if _, err := p.basicAuth(auth, proxy.Namespace, host); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthPolicyNotValid",
+				"Spec.VirtualHost.BasicAuth is invalid: %s", err)
+			return
+		}
+	}
+
 	var tlsEnabled bool
 	if tls := proxy.Spec.VirtualHost.TLS; tls != nil {
 		if tls.Passthrough && tls.EnableFallbackCertificate {
@@ -213,6 +248,14 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 				return
 			}
 
+			// Fallback certificates and JWT verification are
+			// incompatible for the same reason.
+			if tls.EnableFallbackCertificate && len(proxy.Spec.VirtualHost.JWTProviders) > 0 {
+				validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
+					"Spec.Virtualhost.TLS fallback & JWT verification are incompatible")
+				return
+			}
+
 			// If FallbackCertificate is enabled, but no cert passed, set error
 			if tls.EnableFallbackCertificate {
 				if p.FallbackCertificate == nil {
@@ -239,6 +282,13 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 
 			// Fill in DownstreamValidation when external client validation is enabled.
 			if tls.ClientValidation != nil {
+				// Subjects are checked, and certificate details are forwarded, in HTTP requests.
+				if proxy.Spec.TCPProxy != nil && (len(tls.ClientValidation.Subjects) > 0 || tls.ClientValidation.ForwardClientCertificate != nil) {
+					validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
+						"Spec.Virtualhost.TLS client validation subjects & forwardClientCertificate are incompatible with tcpproxy")
+					return
+				}
+
 				dv, err := p.source.LookupDownstreamValidation(tls.ClientValidation, proxy.Namespace)
 				if err != nil {
 					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
@@ -249,43 +299,39 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 			}
 
 			if proxy.Spec.VirtualHost.AuthorizationConfigured() {
-				auth := proxy.Spec.VirtualHost.Authorization
-				ref := defaultExtensionRef(auth.ExtensionServiceRef)
-
-				if ref.APIVersion != contour_api_v1alpha1.GroupVersion.String() {
-					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "AuthBadResourceVersion",
-						"Spec.Virtualhost.Authorization.extensionRef specifies an unsupported resource version %q", auth.ExtensionServiceRef.APIVersion)
+				ext, reason, err := p.externalAuthorization(proxy.Spec.VirtualHost.Authorization, proxy.Namespace)
+				if err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, reason,
+						"Spec.Virtualhost.Authorization.%s", err)
 					return
 				}
 
-				// Lookup the extension service reference.
-				extensionName := types.NamespacedName{
-					Name:      ref.Name,
-					Namespace: stringOrDefault(ref.Namespace, proxy.Namespace),
-				}
+				svhost.ExternalAuthorization = ext
+			}
 
-				ext := p.dag.GetExtensionCluster(ExtensionClusterName(extensionName))
-				if ext == nil {
-					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "ExtensionServiceNotFound",
-						"Spec.Virtualhost.Authorization.ServiceRef extension service %q not found", extensionName)
+			if len(proxy.Spec.VirtualHost.JWTProviders) > 0 {
+				providers, err := jwtProviders(proxy.Spec.VirtualHost.JWTProviders)
+				if err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "JWTProvidersNotValid",
+						"Spec.VirtualHost.JWTProviders is invalid: %s", err)
 					return
 				}
 
-				svhost.AuthorizationService = ext
-				svhost.AuthorizationFailOpen = auth.FailOpen
+				for i, provider := range proxy.Spec.VirtualHost.JWTProviders {
+					if provider.RemoteJWKS == nil {
+						continue
+					}
 
-				timeout, err := timeout.Parse(auth.ResponseTimeout)
-				if err != nil {
-					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "AuthResponseTimeoutInvalid",
-						"Spec.Virtualhost.Authorization.ResponseTimeout is invalid: %s", err)
-					return
+					uv, err := p.source.LookupUpstreamValidation(provider.RemoteJWKS.UpstreamValidation, proxy.Namespace)
+					if err != nil {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "JWTProvidersNotValid",
+							"Spec.VirtualHost.JWTProviders provider %q remote JWKS validation is invalid: %s", provider.Name, err)
+						return
+					}
+					providers[i].RemoteJWKS.Cluster.UpstreamValidation = uv
 				}
 
-				if timeout.UseDefault() {
-					svhost.AuthorizationResponseTimeout = ext.TimeoutPolicy.ResponseTimeout
-				} else {
-					svhost.AuthorizationResponseTimeout = timeout
-				}
+				svhost.JWTProviders = providers
 			}
 		}
 	}
@@ -319,6 +365,35 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 	}
 	insecure.RateLimitPolicy = rlp
 
+	dynamicHeaders := map[string]string{
+		"CONTOUR_NAMESPACE": proxy.Namespace,
+	}
+
+	reqHP, err := headersPolicyRoute(proxy.Spec.VirtualHost.RequestHeadersPolicy, false /* disallow Host */, dynamicHeaders)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "RequestHeadersPolicyInvalid",
+			"Spec.VirtualHost.RequestHeadersPolicy: %s", err)
+		return
+	}
+	insecure.RequestHeadersPolicy = reqHP
+
+	respHP, err := headersPolicyRoute(proxy.Spec.VirtualHost.ResponseHeadersPolicy, false /* disallow Host */, dynamicHeaders)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ResponseHeadersPolicyInvalid",
+			"Spec.VirtualHost.ResponseHeadersPolicy: %s", err)
+		return
+	}
+	insecure.ResponseHeadersPolicy = respHP
+
+	ipFilterAllow, ipFilterRules, err := ipFilterPolicy(proxy.Spec.VirtualHost.IPAllowFilterPolicy, proxy.Spec.VirtualHost.IPDenyFilterPolicy)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeIPFilterError, "PolicyDidNotParse",
+			"Spec.VirtualHost.IPFilterPolicy: %s", err)
+		return
+	}
+	insecure.IPFilterAllow = ipFilterAllow
+	insecure.IPFilterRules = ipFilterRules
+
 	addRoutes(insecure, routes)
 
 	// if TLS is enabled for this virtual host and there is no tcp proxy defined,
@@ -334,6 +409,10 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 			return
 		}
 		secure.RateLimitPolicy = rlp
+		secure.RequestHeadersPolicy = reqHP
+		secure.ResponseHeadersPolicy = respHP
+		secure.IPFilterAllow = ipFilterAllow
+		secure.IPFilterRules = ipFilterRules
 
 		addRoutes(secure, routes)
 	}
@@ -407,6 +486,12 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		if err := includeMatchConditionsValid(include.Conditions); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "PathMatchConditionsNotValid",
+				"include: %s", err)
+			return nil
+		}
+
 		inc, incCommit := p.dag.StatusCache.ProxyAccessor(includedProxy)
 		incValidCond := inc.ConditionFor(status.ValidCondition)
 		routes = append(routes, p.computeRoutes(incValidCond, rootProxy, includedProxy, append(conditions, include.Conditions...), visited, enforceTLS)...)
@@ -436,6 +521,13 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		// Look for invalid query parameter conditions on this route
+		if err := queryParamMatchConditionsValid(conds); err != nil {
+			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "QueryParamMatchConditionsNotValid",
+				err.Error())
+			return nil
+		}
+
 		reqHP, err := headersPolicyRoute(route.RequestHeadersPolicy, true /* allow Host */, dynamicHeaders)
 		if err != nil {
 			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RequestHeadersPolicyInvalid",
@@ -450,12 +542,46 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
-		if len(route.Services) < 1 {
+		if route.RequestRedirectPolicy != nil && len(route.Services) > 0 {
+			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "RequestRedirectAndServices",
+				"cannot specify services and requestRedirectPolicy in the same route")
+			return nil
+		}
+
+		if route.DirectResponsePolicy != nil && len(route.Services) > 0 {
+			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "DirectResponseAndServices",
+				"cannot specify services and directResponsePolicy in the same route")
+			return nil
+		}
+
+		if route.DirectResponsePolicy != nil && route.RequestRedirectPolicy != nil {
+			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "DirectResponseAndRequestRedirect",
+				"cannot specify requestRedirectPolicy and directResponsePolicy in the same route")
+			return nil
+		}
+
+		if len(route.Services) < 1 && route.RequestRedirectPolicy == nil && route.DirectResponsePolicy == nil {
 			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "NoServicesPresent",
 				"route.services must have at least one entry")
 			return nil
 		}
 
+		redirect, err := redirectPolicy(route.RequestRedirectPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RequestRedirectPolicyNotValid",
+				"route.requestRedirectPolicy is invalid: %s", err)
+			return nil
+		}
+
+		directResponse, err := directResponsePolicy(route.DirectResponsePolicy, func(ref *contour_api_v1.ConfigMapKeyReference) (string, error) {
+			return p.source.LookupConfigMapKey(types.NamespacedName{Name: ref.Name, Namespace: proxy.Namespace}, ref.Key)
+		})
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid",
+				"route.directResponsePolicy is invalid: %s", err)
+			return nil
+		}
+
 		tp, err := timeoutPolicy(route.TimeoutPolicy)
 		if err != nil {
 			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "TimeoutPolicyNotValid",
@@ -470,19 +596,54 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		fip, err := faultInjectionPolicy(route.FaultInjectionPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "FaultInjectionPolicyNotValid",
+				"route.faultInjectionPolicy is invalid: %s", err)
+			return nil
+		}
+
+		ipFilterAllow, ipFilterRules, err := ipFilterPolicy(route.IPAllowFilterPolicy, route.IPDenyFilterPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIPFilterError, "PolicyDidNotParse",
+				"route.ipFilterPolicy is invalid: %s", err)
+			return nil
+		}
+
+		rp, err := retryPolicy(route.RetryPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RetryPolicyNotValid",
+				"route.retryPolicy is invalid: %s", err)
+			return nil
+		}
+
 		requestHashPolicies, lbPolicy := loadBalancerRequestHashPolicies(route.LoadBalancerPolicy, validCond)
 
 		r := &Route{
-			PathMatchCondition:    mergePathMatchConditions(conds),
-			HeaderMatchConditions: mergeHeaderMatchConditions(conds),
-			Websocket:             route.EnableWebsockets,
-			HTTPSUpgrade:          routeEnforceTLS(enforceTLS, route.PermitInsecure && !p.DisablePermitInsecure),
-			TimeoutPolicy:         tp,
-			RetryPolicy:           retryPolicy(route.RetryPolicy),
-			RequestHeadersPolicy:  reqHP,
-			ResponseHeadersPolicy: respHP,
-			RateLimitPolicy:       rlp,
-			RequestHashPolicies:   requestHashPolicies,
+			PathMatchCondition:        mergePathMatchConditions(conds),
+			HeaderMatchConditions:     mergeHeaderMatchConditions(conds),
+			QueryParamMatchConditions: mergeQueryParamMatchConditions(conds),
+			Websocket:                 route.EnableWebsockets,
+			HTTPSUpgrade:              routeEnforceTLS(enforceTLS, route.PermitInsecure && !p.DisablePermitInsecure),
+			TimeoutPolicy:             tp,
+			RetryPolicy:               rp,
+			RequestHeadersPolicy:      reqHP,
+			ResponseHeadersPolicy:     respHP,
+			RateLimitPolicy:           rlp,
+			FaultInjectionPolicy:      fip,
+			IPFilterAllow:             ipFilterAllow,
+			IPFilterRules:             ipFilterRules,
+			RequestHashPolicies:       requestHashPolicies,
+			Redirect:                  redirect,
+			DirectResponse:            directResponse,
+		}
+
+		// Envoy does not support prefix rewrites when redirecting
+		// a route that is matched by a regex.
+		if r.Redirect != nil && len(r.Redirect.Prefix) > 0 && r.HasPathRegex() {
+			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "RequestRedirectPolicyNotValid",
+				"route.requestRedirectPolicy is invalid: cannot specify prefix on a route with a regex condition")
+			return nil
 		}
 
 		// If the enclosing root proxy enabled authorization,
@@ -507,6 +668,129 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			r.AuthContext = route.AuthorizationContext(rootProxy.Spec.VirtualHost.AuthorizationContext())
 		}
 
+		// A route authorization server overrides the server of
+		// the root virtual host. The context of the virtual host
+		// is not sent to a different server.
+		if route.Authorization != nil {
+			tls := rootProxy.Spec.VirtualHost.TLS
+			if tls == nil || len(tls.SecretName) == 0 {
+				validCond.AddError(contour_api_v1.ConditionTypeAuthError, "AuthNotPermitted",
+					"route.authorization is invalid: authorization can only be enabled on virtual hosts with TLS")
+				return nil
+			}
+
+			if tls.EnableFallbackCertificate {
+				validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
+					"Spec.Virtualhost.TLS fallback & client authorization are incompatible")
+				return nil
+			}
+
+			if route.Authorization.AuthPolicy != nil {
+				validCond.AddError(contour_api_v1.ConditionTypeAuthError, "AuthPolicyNotValid",
+					"route.authorization.authPolicy is not supported, use route.authPolicy instead")
+				return nil
+			}
+
+			if route.AuthPolicy != nil && route.AuthPolicy.Disabled {
+				validCond.AddError(contour_api_v1.ConditionTypeAuthError, "AuthPolicyNotValid",
+					"route.authorization cannot be specified when route.authPolicy is disabled")
+				return nil
+			}
+
+			ext, reason, err := p.externalAuthorization(route.Authorization, proxy.Namespace)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, reason,
+					"route.authorization.%s", err)
+				return nil
+			}
+
+			r.ExternalAuthorization = ext
+			r.AuthDisabled = false
+			r.AuthContext = route.AuthorizationContext(nil)
+		}
+
+		r.JWTProvider, err = jwtVerificationProvider(route.JWTVerificationPolicy, rootProxy.Spec.VirtualHost.JWTProviders)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationPolicyNotValid",
+				"route.jwtVerificationPolicy is invalid: %s", err)
+			return nil
+		}
+
+		// Requests over plain HTTP are not verified, so routes
+		// that require JWT verification must not be served over
+		// plain HTTP.
+		if len(r.JWTProvider) > 0 && route.PermitInsecure && !p.DisablePermitInsecure {
+			validCond.AddError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationNotPermitted",
+				"route.permitInsecure cannot be combined with JWT verification; disable route.jwtVerificationPolicy to serve the route over plain HTTP")
+			return nil
+		}
+
+		// A route basic authentication policy overrides the
+		// policy of the root virtual host. Secrets are resolved
+		// relative to the proxy that declares the policy.
+		if route.BasicAuth != nil {
+			if route.BasicAuth.Disabled && (len(route.BasicAuth.SecretName) > 0 || len(route.BasicAuth.Realm) > 0) {
+				validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthPolicyNotValid",
+					"route.basicAuth is invalid: cannot specify secretName or realm when disabled")
+				return nil
+			}
+			r.BasicAuth, err = p.basicAuth(route.BasicAuth, proxy.Namespace, rootProxy.Spec.VirtualHost.Fqdn)
+		} else {
+			r.BasicAuth, err = p.basicAuth(rootProxy.Spec.VirtualHost.BasicAuth, rootProxy.Namespace, rootProxy.Spec.VirtualHost.Fqdn)
+		}
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthPolicyNotValid",
+				"route.basicAuth is invalid: %s", err)
+			return nil
+		}
+
+		if r.BasicAuth != nil {
+			// Basic authentication sends passwords in clear
+			// text, so it is only enabled on the secure listener.
+			tls := rootProxy.Spec.VirtualHost.TLS
+			if tls == nil || len(tls.SecretName) == 0 {
+				validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthRequiresTLS",
+					"route.basicAuth is invalid: basic authentication can only be enabled on virtual hosts with TLS")
+				return nil
+			}
+
+			if route.PermitInsecure && !p.DisablePermitInsecure {
+				validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+					"route.permitInsecure cannot be combined with basic authentication; disable route.basicAuth to serve the route over plain HTTP")
+				return nil
+			}
+
+			// The fallback certificate filter chain does not
+			// authenticate requests, so it must not serve routes
+			// that require basic authentication.
+			if tls.EnableFallbackCertificate {
+				validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
+					"Spec.Virtualhost.TLS fallback & basic authentication are incompatible")
+				return nil
+			}
+
+			// Basic authentication is verified by an ext_authz
+			// filter, and Envoy disables all the ext_authz filters
+			// of routes that disable external authorization.
+			if r.AuthDisabled {
+				validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+					"route.basicAuth cannot be combined with a disabled authPolicy; disable route.basicAuth or enable route.authPolicy")
+				return nil
+			}
+		}
+
+		includePrefix := ""
+		if pc, ok := mergePathMatchConditions(conditions).(*PrefixMatchCondition); ok {
+			includePrefix = pc.Prefix
+		}
+
+		r.RegexRewrite, err = regexRewritePolicy(route.PathRewritePolicy, includePrefix)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "PathRewritePolicyNotValid",
+				"route.pathRewritePolicy is invalid: %s", err)
+			return nil
+		}
+
 		if len(route.GetPrefixReplacements()) > 0 {
 			if !r.HasPathPrefix() {
 				validCond.AddError(contour_api_v1.ConditionTypePrefixReplaceError, "MustHavePrefix",
@@ -551,6 +835,16 @@ func (p *HTTPProxyProcessor) computeRoutes(
 					"service %q: port must be in the range 1-65535", service.Name)
 				return nil
 			}
+			if service.MirrorPercent < 0 || service.MirrorPercent > 100 {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "MirrorPercentInvalid",
+					"service %q: mirror percent must be in the range 0-100", service.Name)
+				return nil
+			}
+			if service.MirrorPercent > 0 && !service.Mirror {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "MirrorPercentInvalid",
+					"service %q: mirror percent may only be set on a mirror service", service.Name)
+				return nil
+			}
 			m := types.NamespacedName{Name: service.Name, Namespace: proxy.Namespace}
 			s, err := p.dag.EnsureService(m, intstr.FromInt(service.Port), p.source)
 			if err != nil {
@@ -593,6 +887,20 @@ func (p *HTTPProxyProcessor) computeRoutes(
 				return nil
 			}
 
+			od, err := outlierDetection(service.OutlierDetection)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "OutlierDetectionInvalid",
+					"service %q: outlier detection is invalid: %s", service.Name, err)
+				return nil
+			}
+
+			cb, err := p.circuitBreakerPolicy(m, s, service)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "CircuitBreakerPolicyInvalid",
+					"service %q: circuit breaker policy is invalid: %s", service.Name, err)
+				return nil
+			}
+
 			var clientCertSecret *Secret
 			if p.ClientCertificate != nil {
 				clientCertSecret, err = p.source.LookupSecret(*p.ClientCertificate, validSecret)
@@ -608,6 +916,8 @@ func (p *HTTPProxyProcessor) computeRoutes(
 				LoadBalancerPolicy:    lbPolicy,
 				Weight:                uint32(service.Weight),
 				HTTPHealthCheckPolicy: httpHealthCheckPolicy(route.HealthCheckPolicy),
+				OutlierDetection:      od,
+				CircuitBreakerPolicy:  cb,
 				UpstreamValidation:    uv,
 				RequestHeadersPolicy:  reqHP,
 				ResponseHeadersPolicy: respHP,
@@ -616,19 +926,29 @@ func (p *HTTPProxyProcessor) computeRoutes(
 				DNSLookupFamily:       string(p.DNSLookupFamily),
 				ClientCertificate:     clientCertSecret,
 			}
-			if service.Mirror && r.MirrorPolicy != nil {
-				validCond.AddError(contour_api_v1.ConditionTypeServiceError, "OnlyOneMirror",
-					"only one service per route may be nominated as mirror")
-				return nil
-			}
 			if service.Mirror {
-				r.MirrorPolicy = &MirrorPolicy{
-					Cluster: c,
+				for _, mp := range r.MirrorPolicies {
+					if mp.Cluster.Upstream.Weighted.ServiceName == s.Weighted.ServiceName &&
+						mp.Cluster.Upstream.Weighted.ServicePort.Port == s.Weighted.ServicePort.Port {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "DuplicateMirror",
+							"service %q: port %d is nominated as mirror more than once", service.Name, service.Port)
+						return nil
+					}
 				}
+				r.MirrorPolicies = append(r.MirrorPolicies, &MirrorPolicy{
+					Cluster: c,
+					Percent: mirrorPercent(service.MirrorPercent),
+				})
 			} else {
 				r.Clusters = append(r.Clusters, c)
 			}
 		}
+
+		if len(r.MirrorPolicies) > 0 && len(r.Clusters) == 0 {
+			validCond.AddError(contour_api_v1.ConditionTypeServiceError, "MirrorWithoutServices",
+				"route must have at least one service that is not a mirror")
+			return nil
+		}
 		routes = append(routes, r)
 	}
 
@@ -691,11 +1011,27 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 				return false
 			}
 
+			od, err := outlierDetection(service.OutlierDetection)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "OutlierDetectionInvalid",
+					"service %q: outlier detection is invalid: %s", service.Name, err)
+				return false
+			}
+
+			cb, err := p.circuitBreakerPolicy(m, s, service)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "CircuitBreakerPolicyInvalid",
+					"service %q: circuit breaker policy is invalid: %s", service.Name, err)
+				return false
+			}
+
 			proxy.Clusters = append(proxy.Clusters, &Cluster{
 				Upstream:             s,
 				Protocol:             protocol,
 				LoadBalancerPolicy:   lbPolicy,
 				TCPHealthCheckPolicy: tcpHealthCheckPolicy(tcpproxy.HealthCheckPolicy),
+				OutlierDetection:     od,
+				CircuitBreakerPolicy: cb,
 				SNI:                  s.ExternalName,
 			})
 		}
@@ -799,6 +1135,21 @@ func (p *HTTPProxyProcessor) validHTTPProxies() []*contour_api_v1.HTTPProxy {
 	return valid
 }
 
+// circuitBreakerPolicy validates the circuit breaker annotations of the
+// Kubernetes Service referenced by the supplied HTTPProxy service and
+// returns the circuit breaking thresholds for its cluster.
+func (p *HTTPProxyProcessor) circuitBreakerPolicy(m types.NamespacedName, s *Service, service contour_api_v1.Service) (*CircuitBreakerPolicy, error) {
+	svc, _, err := p.source.LookupService(m, intstr.FromInt(service.Port))
+	if err != nil {
+		return nil, err
+	}
+	if err := annotation.ValidateCircuitBreakers(svc); err != nil {
+		return nil, err
+	}
+
+	return circuitBreakerPolicy(s, service.CircuitBreakerPolicy, p.CircuitBreakerDefaults)
+}
+
 // rootAllowed returns true if the HTTPProxy lives in a permitted root namespace.
 func (p *HTTPProxyProcessor) rootAllowed(namespace string) bool {
 	if len(p.source.RootNamespaces) == 0 {
@@ -837,6 +1188,7 @@ func expandPrefixMatches(routes []*Route) []*Route {
 		// If there is no path prefix, we won't do any expansion, so skip it.
 		if !r.HasPathPrefix() {
 			expandedRoutes = append(expandedRoutes, r)
+			continue
 		}
 
 		routingPrefix := r.PathMatchCondition.(*PrefixMatchCondition).Prefix
@@ -987,6 +1339,147 @@ func includeMatchConditionsIdentical(includes []contour_api_v1.Include) bool {
 	return false
 }
 
+// externalAuthorization resolves the extension service of an
+// authorization server that is referenced from the given namespace. If
+// the server is invalid, it returns the condition reason with the error.
+func (p *HTTPProxyProcessor) externalAuthorization(auth *contour_api_v1.AuthorizationServer, namespace string) (*ExternalAuthorization, string, error) {
+	ref := defaultExtensionRef(auth.ExtensionServiceRef)
+
+	if ref.APIVersion != contour_api_v1alpha1.GroupVersion.String() {
+		return nil, "AuthBadResourceVersion",
+			fmt.Errorf("extensionRef specifies an unsupported resource version %q", auth.ExtensionServiceRef.APIVersion)
+	}
+
+	// Lookup the extension service reference.
+	extensionName := types.NamespacedName{
+		Name:      ref.Name,
+		Namespace: stringOrDefault(ref.Namespace, namespace),
+	}
+
+	ext := p.dag.GetExtensionCluster(ExtensionClusterName(extensionName))
+	if ext == nil {
+		return nil, "ExtensionServiceNotFound",
+			fmt.Errorf("ServiceRef extension service %q not found", extensionName)
+	}
+
+	responseTimeout, err := timeout.Parse(auth.ResponseTimeout)
+	if err != nil {
+		return nil, "AuthResponseTimeoutInvalid",
+			fmt.Errorf("ResponseTimeout is invalid: %s", err)
+	}
+
+	if responseTimeout.UseDefault() {
+		responseTimeout = ext.TimeoutPolicy.ResponseTimeout
+	}
+
+	authz := &ExternalAuthorization{
+		AuthorizationService:         ext,
+		AuthorizationResponseTimeout: responseTimeout,
+		AuthorizationFailOpen:        auth.FailOpen,
+		AllowedRequestHeaders:        auth.AllowedRequestHeaders,
+		AllowedUpstreamHeaders:       auth.AllowedUpstreamHeaders,
+		AllowedClientHeaders:         auth.AllowedClientHeaders,
+		StatusOnDeny:                 auth.StatusOnDeny,
+		IncludeClientCertificate:     true,
+	}
+
+	if auth.IncludeClientCertificate != nil {
+		authz.IncludeClientCertificate = *auth.IncludeClientCertificate
+	}
+
+	if body := auth.WithRequestBody; body != nil {
+		authz.WithRequestBody = &AuthorizationBufferSettings{
+			MaxRequestBytes:     body.MaxRequestBytes,
+			AllowPartialMessage: body.AllowPartialMessage,
+			PackAsBytes:         body.PackAsBytes,
+		}
+	}
+
+	for _, allowed := range []struct {
+		field   string
+		headers []string
+	}{
+		{"allowedRequestHeaders", auth.AllowedRequestHeaders},
+		{"allowedUpstreamHeaders", auth.AllowedUpstreamHeaders},
+		{"allowedClientHeaders", auth.AllowedClientHeaders},
+	} {
+		field, headers := allowed.field, allowed.headers
+
+		for _, header := range headers {
+			if msgs := validation.IsHTTPHeaderName(header); len(msgs) != 0 {
+				return nil, "AuthAllowedHeadersInvalid",
+					fmt.Errorf("%s is invalid: invalid header name %q: %v", field, header, msgs)
+			}
+		}
+
+		// The gRPC check protocol always sends all the request
+		// headers, and lets the authorization server choose which
+		// headers to add to the request or the response.
+		if len(headers) > 0 && auth.HTTPService == nil {
+			return nil, "AuthAllowedHeadersNotSupported",
+				fmt.Errorf("%s is not supported by gRPC authorization servers", field)
+		}
+	}
+
+	if svc := auth.HTTPService; svc != nil {
+		if len(svc.PathPrefix) > 0 && !strings.HasPrefix(svc.PathPrefix, "/") {
+			return nil, "AuthHTTPServiceInvalid",
+				fmt.Errorf("httpService.pathPrefix %q must start with \"/\"", svc.PathPrefix)
+		}
+
+		authz.HTTPService = &AuthorizationHTTPService{
+			PathPrefix: svc.PathPrefix,
+		}
+	} else if ext.Protocol == "http/1.1" {
+		return nil, "AuthProtocolNotSupported",
+			fmt.Errorf("ServiceRef extension service %q uses the http/1.1 protocol, which requires an httpService", extensionName)
+	}
+
+	return authz, "", nil
+}
+
+// basicAuth returns the basic authentication policy for requests that
+// are subject to the supplied policy, or nil if basic authentication is
+// disabled. The policy Secret is resolved relative to namespace, and
+// the realm defaults to defaultRealm.
+func (p *HTTPProxyProcessor) basicAuth(policy *contour_api_v1.BasicAuthPolicy, namespace string, defaultRealm string) (*BasicAuth, error) {
+	if policy == nil || policy.Disabled {
+		return nil, nil
+	}
+
+	if isBlank(policy.SecretName) {
+		return nil, errors.New("secretName must be specified")
+	}
+
+	realm := stringOrDefault(policy.Realm, defaultRealm)
+	for _, c := range realm {
+		if c < ' ' || c > '~' || c == '"' || c == '\\' {
+			return nil, fmt.Errorf("realm %q must only contain printable ASCII characters other than quotes and backslashes", realm)
+		}
+	}
+
+	secretName := k8s.NamespacedNameFrom(policy.SecretName, k8s.DefaultNamespace(namespace))
+	sec, err := p.source.LookupSecret(secretName, validBasicAuthSecret)
+	if err != nil {
+		return nil, fmt.Errorf("Secret %q is invalid: %s", policy.SecretName, err)
+	}
+
+	if !p.source.DelegationPermitted(secretName, namespace) {
+		return nil, fmt.Errorf("Secret %q delegation not permitted", policy.SecretName)
+	}
+
+	users, err := htpasswdUsers(sec.Object.Data[BasicAuthKey])
+	if err != nil {
+		return nil, err
+	}
+
+	return &BasicAuth{
+		Secret: secretName,
+		Realm:  realm,
+		Users:  users,
+	}, nil
+}
+
 // isBlank indicates if a string contains nothing but blank characters.
 func isBlank(s string) bool {
 	return len(strings.TrimSpace(s)) == 0
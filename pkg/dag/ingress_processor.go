@@ -36,6 +36,10 @@ type IngressProcessor struct {
 	// ClientCertificate is the optional identifier of the TLS secret containing client certificate and
 	// private key to be used when establishing TLS connection to upstream cluster.
 	ClientCertificate *types.NamespacedName
+
+	// CircuitBreakerDefaults holds the circuit breaking thresholds
+	// used when the Service annotations do not set them (optional).
+	CircuitBreakerDefaults *CircuitBreakerPolicy
 }
 
 // Run translates Ingresses into DAG objects and
@@ -166,6 +170,11 @@ func (p *IngressProcessor) computeIngressRule(ing *networking_v1.Ingress, rule n
 			return
 		}
 
+		// Ingress has no way to set circuit breakers other than the
+		// Service annotations, so this never fails.
+		cb, _ := circuitBreakerPolicy(s, nil, p.CircuitBreakerDefaults)
+		r.Clusters[0].CircuitBreakerPolicy = cb
+
 		// should we create port 80 routes for this ingress
 		if annotation.TLSRequired(ing) || annotation.HTTPAllowed(ing) {
 			vhost := p.dag.EnsureVirtualHost(ListenerName{Name: host, ListenerName: "ingress_http"})
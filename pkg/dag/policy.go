@@ -14,10 +14,15 @@
 package dag
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,11 +31,13 @@ import (
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/pkg/annotation"
+	"github.com/projectcontour/contour/pkg/config"
 	"github.com/projectcontour/contour/pkg/timeout"
 	"github.com/sirupsen/logrus"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/pointer"
 )
 
 const (
@@ -69,9 +76,9 @@ func retryOn(ro []contour_api_v1.RetryOn) string {
 	return strings.Join(ss, ",")
 }
 
-func retryPolicy(rp *contour_api_v1.RetryPolicy) *RetryPolicy {
+func retryPolicy(rp *contour_api_v1.RetryPolicy) (*RetryPolicy, error) {
 	if rp == nil {
-		return nil
+		return nil, nil
 	}
 
 	// If PerTryTimeout is not a valid duration string, use the Envoy default
@@ -84,12 +91,91 @@ func retryPolicy(rp *contour_api_v1.RetryPolicy) *RetryPolicy {
 		perTryTimeout = timeout.DurationSetting(perTryDuration)
 	}
 
+	if err := retriableHeadersValid(rp.RetriableHeaders); err != nil {
+		return nil, fmt.Errorf("invalid retriable headers: %s", err)
+	}
+
+	if err := retriableHeadersValid(rp.RetriableRequestHeaders); err != nil {
+		return nil, fmt.Errorf("invalid retriable request headers: %s", err)
+	}
+
+	backoff, err := retryBackoff(rp.RetryBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var hostPredicates []RetryHostPredicate
+	seen := map[RetryHostPredicate]bool{}
+	for _, p := range rp.RetryHostPredicate {
+		predicate := RetryHostPredicate(p)
+		switch predicate {
+		case RetryHostPredicatePreviousHosts, RetryHostPredicateOmitCanaryHosts:
+		default:
+			return nil, fmt.Errorf("invalid retry host predicate %q", p)
+		}
+		if seen[predicate] {
+			return nil, fmt.Errorf("duplicate retry host predicate %q", p)
+		}
+		seen[predicate] = true
+		hostPredicates = append(hostPredicates, predicate)
+	}
+
+	if rp.HostSelectionRetryMaxAttempts < 0 {
+		return nil, fmt.Errorf("host selection retry max attempts %d must not be negative", rp.HostSelectionRetryMaxAttempts)
+	}
+
 	return &RetryPolicy{
-		RetryOn:              retryOn(rp.RetryOn),
-		RetriableStatusCodes: rp.RetriableStatusCodes,
-		NumRetries:           max(1, uint32(rp.NumRetries)),
-		PerTryTimeout:        perTryTimeout,
+		RetryOn:                  retryOn(rp.RetryOn),
+		RetriableStatusCodes:     rp.RetriableStatusCodes,
+		NumRetries:               max(1, uint32(rp.NumRetries)),
+		PerTryTimeout:            perTryTimeout,
+		RetriableHeaders:         headerMatchConditions(rp.RetriableHeaders),
+		RetriableRequestHeaders:  headerMatchConditions(rp.RetriableRequestHeaders),
+		Backoff:                  backoff,
+		HostPredicates:           hostPredicates,
+		HostSelectionMaxAttempts: rp.HostSelectionRetryMaxAttempts,
+	}, nil
+}
+
+// retriableHeadersValid validates the header conditions of a retry policy
+// using the same rules as the header conditions of a route.
+func retriableHeadersValid(headers []contour_api_v1.HeaderMatchCondition) error {
+	conds := make([]contour_api_v1.MatchCondition, 0, len(headers))
+	for i := range headers {
+		conds = append(conds, contour_api_v1.MatchCondition{Header: &headers[i]})
+	}
+
+	return headerMatchConditionsValid(conds)
+}
+
+// retryBackoff builds a *RetryBackoff for the supplied retry backoff.
+func retryBackoff(in *contour_api_v1.RetryBackoff) (*RetryBackoff, error) {
+	if in == nil {
+		return nil, nil
 	}
+
+	base, err := time.ParseDuration(in.BaseInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry backoff base interval %q: %s", in.BaseInterval, err)
+	}
+	if base <= 0 {
+		return nil, fmt.Errorf("retry backoff base interval %q must be greater than zero", in.BaseInterval)
+	}
+
+	backoff := &RetryBackoff{BaseInterval: base}
+
+	if in.MaxInterval != "" {
+		maxInterval, err := time.ParseDuration(in.MaxInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry backoff max interval %q: %s", in.MaxInterval, err)
+		}
+		if maxInterval < base {
+			return nil, fmt.Errorf("retry backoff max interval %q must not be less than the base interval %q", in.MaxInterval, in.BaseInterval)
+		}
+		backoff.MaxInterval = maxInterval
+	}
+
+	return backoff, nil
 }
 
 func headersPolicyService(defaultPolicy *HeadersPolicy, policy *contour_api_v1.HeadersPolicy, dynamicHeaders map[string]string) (*HeadersPolicy, error) {
@@ -115,9 +201,11 @@ func headersPolicyService(defaultPolicy *HeadersPolicy, policy *contour_api_v1.H
 		if msgs := validation.IsHTTPHeaderName(key); len(msgs) != 0 {
 			return nil, fmt.Errorf("invalid set header %q: %v", key, msgs)
 		}
-		// if the user policy set on the object does not contain this header then use the default
+		// if the user policy set on the object does not contain this header then use the default.
+		// The default values are validated when the Contour configuration is loaded, so they can't
+		// fail here and put every service into error.
 		if _, exists := userPolicy.Set[key]; !exists {
-			userPolicy.Set[key] = escapeHeaderValue(v, dynamicHeaders)
+			userPolicy.Set[key] = escapeTrustedHeaderValue(v, dynamicHeaders)
 		}
 	}
 	// add any default remove header policy if not already set
@@ -160,7 +248,11 @@ func headersPolicyRoute(policy *contour_api_v1.HeadersPolicy, allowHostRewrite b
 		if msgs := validation.IsHTTPHeaderName(key); len(msgs) != 0 {
 			return nil, fmt.Errorf("invalid set header %q: %v", key, msgs)
 		}
-		set[key] = escapeHeaderValue(entry.Value, dynamicHeaders)
+		value, err := escapeHeaderValue(entry.Value, dynamicHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("invalid set header %q: %v", key, err)
+		}
+		set[key] = value
 	}
 
 	remove := sets.NewString()
@@ -210,7 +302,12 @@ func headersPolicyGatewayAPI(hf *gatewayapi_v1alpha1.HTTPRequestHeaderFilter) (*
 			errlist = append(errlist, fmt.Errorf("invalid set header %q: %v", key, msgs))
 			continue
 		}
-		set[key] = escapeHeaderValue(v, nil)
+		value, err := escapeHeaderValue(v, nil)
+		if err != nil {
+			errlist = append(errlist, fmt.Errorf("invalid set header %q: %v", key, err))
+			continue
+		}
+		set[key] = value
 	}
 	for k, v := range hf.Add {
 		key := http.CanonicalHeaderKey(k)
@@ -226,7 +323,12 @@ func headersPolicyGatewayAPI(hf *gatewayapi_v1alpha1.HTTPRequestHeaderFilter) (*
 			errlist = append(errlist, fmt.Errorf("invalid add header %q: %v", key, msgs))
 			continue
 		}
-		add[key] = escapeHeaderValue(v, nil)
+		value, err := escapeHeaderValue(v, nil)
+		if err != nil {
+			errlist = append(errlist, fmt.Errorf("invalid add header %q: %v", key, err))
+			continue
+		}
+		add[key] = value
 	}
 
 	remove := sets.NewString()
@@ -259,49 +361,91 @@ func headersPolicyGatewayAPI(hf *gatewayapi_v1alpha1.HTTPRequestHeaderFilter) (*
 	}, utilerrors.NewAggregate(errlist)
 }
 
-func escapeHeaderValue(value string, dynamicHeaders map[string]string) string {
-	// Envoy supports %-encoded variables, so literal %'s in the header's value must be escaped.  See:
-	// https://www.envoyproxy.io/docs/envoy/latest/configuration/http/http_conn_man/headers#custom-request-response-headers
-	// Only allow a specific set of known good Envoy dynamic headers to pass through unescaped
+// envoyHeaderVariables is the set of Envoy custom request/response header
+// variables that may be used in header values.
+var envoyHeaderVariables = sets.NewString(config.EnvoyHeaderVariables...)
+
+// headerVariableRegex matches a header value variable at the start
+// of a string, either %VARIABLE% or %VARIABLE(argument)%.
+var headerVariableRegex = regexp.MustCompile(`^%([A-Z][A-Z0-9_]*)(\(([^%()]*)\))?%`)
+
+// headerNameRegex matches the header names that may be used as the
+// argument to the REQ variable.
+var headerNameRegex = regexp.MustCompile(`^[\w-]+$`)
+
+// escapeHeaderValue translates a header value into an Envoy header
+// formatter string. Envoy supports %-encoded variables, so literal %'s
+// in the header's value must be escaped. Variables must either be one
+// of the known good Envoy variables, %REQ(header-name)%, or one of the
+// supplied dynamic headers, which are replaced with their values. An
+// error is returned for any other variable. A % that does not start a
+// variable is escaped.
+func escapeHeaderValue(value string, dynamicHeaders map[string]string) (string, error) {
+	return formatHeaderValue(value, dynamicHeaders, true)
+}
+
+// escapeTrustedHeaderValue translates a header value that has already
+// been validated, such as a default header policy from the Contour
+// configuration, into an Envoy header formatter string. Unlike
+// escapeHeaderValue it never fails; a variable it does not recognize
+// is escaped and passed through literally.
+func escapeTrustedHeaderValue(value string, dynamicHeaders map[string]string) string {
+	escaped, _ := formatHeaderValue(value, dynamicHeaders, false)
+	return escaped
+}
+
+// formatHeaderValue implements escapeHeaderValue. If strict is false,
+// invalid variables are escaped rather than returning an error.
+func formatHeaderValue(value string, dynamicHeaders map[string]string, strict bool) (string, error) {
 	if !strings.Contains(value, "%") {
-		return value
-	}
-	escapedValue := strings.Replace(value, "%", "%%", -1)
-	for dynamicVar, dynamicVal := range dynamicHeaders {
-		escapedValue = strings.ReplaceAll(escapedValue, "%%"+dynamicVar+"%%", dynamicVal)
-	}
-	for _, envoyVar := range []string{
-		"DOWNSTREAM_REMOTE_ADDRESS",
-		"DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT",
-		"DOWNSTREAM_LOCAL_ADDRESS",
-		"DOWNSTREAM_LOCAL_ADDRESS_WITHOUT_PORT",
-		"DOWNSTREAM_LOCAL_PORT",
-		"DOWNSTREAM_LOCAL_URI_SAN",
-		"DOWNSTREAM_PEER_URI_SAN",
-		"DOWNSTREAM_LOCAL_SUBJECT",
-		"DOWNSTREAM_PEER_SUBJECT",
-		"DOWNSTREAM_PEER_ISSUER",
-		"DOWNSTREAM_TLS_SESSION_ID",
-		"DOWNSTREAM_TLS_CIPHER",
-		"DOWNSTREAM_TLS_VERSION",
-		"DOWNSTREAM_PEER_FINGERPRINT_256",
-		"DOWNSTREAM_PEER_FINGERPRINT_1",
-		"DOWNSTREAM_PEER_SERIAL",
-		"DOWNSTREAM_PEER_CERT",
-		"DOWNSTREAM_PEER_CERT_V_START",
-		"DOWNSTREAM_PEER_CERT_V_END",
-		"HOSTNAME",
-		"PROTOCOL",
-		"UPSTREAM_REMOTE_ADDRESS",
-		"RESPONSE_FLAGS",
-		"RESPONSE_CODE_DETAILS",
-	} {
-		escapedValue = strings.ReplaceAll(escapedValue, "%%"+envoyVar+"%%", "%"+envoyVar+"%")
+		return value, nil
 	}
-	// REQ(header-name)
-	var validReqEnvoyVar = regexp.MustCompile(`%(%REQ\([\w-]+\)%)%`)
-	escapedValue = validReqEnvoyVar.ReplaceAllString(escapedValue, "$1")
-	return escapedValue
+
+	var escaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			escaped.WriteByte(value[i])
+			continue
+		}
+
+		m := headerVariableRegex.FindStringSubmatch(value[i:])
+		if m == nil {
+			// A stray % that doesn't start a variable.
+			escaped.WriteString("%%")
+			continue
+		}
+
+		variable, name, hasArgument, argument := m[0], m[1], m[2] != "", m[3]
+		var err error
+		switch {
+		case name == "REQ":
+			if !headerNameRegex.MatchString(argument) {
+				err = fmt.Errorf("invalid header name %q in header variable %q", argument, variable)
+				break
+			}
+			escaped.WriteString(variable)
+		case hasArgument:
+			err = fmt.Errorf("header variable %q does not take an argument", variable)
+		case envoyHeaderVariables.Has(name):
+			escaped.WriteString(variable)
+		default:
+			dynamicVal, ok := dynamicHeaders[name]
+			if !ok {
+				err = fmt.Errorf("unknown header variable %q", variable)
+				break
+			}
+			escaped.WriteString(strings.ReplaceAll(dynamicVal, "%", "%%"))
+		}
+		if err != nil {
+			if strict {
+				return "", err
+			}
+			escaped.WriteString(strings.ReplaceAll(variable, "%", "%%"))
+		}
+		i += len(variable) - 1
+	}
+
+	return escaped.String(), nil
 }
 
 // ingressRetryPolicy builds a RetryPolicy from ingress annotations.
@@ -395,6 +539,15 @@ func httpHealthCheckPolicy(hc *contour_api_v1.HTTPHealthCheckPolicy) *HTTPHealth
 	}
 }
 
+// mirrorPercent returns the percentage of requests to mirror,
+// defaulting to all requests if the percentage is not set.
+func mirrorPercent(percent int64) uint32 {
+	if percent <= 0 || percent > 100 {
+		return 100
+	}
+	return uint32(percent)
+}
+
 func tcpHealthCheckPolicy(hc *contour_api_v1.TCPHealthCheckPolicy) *TCPHealthCheckPolicy {
 	if hc == nil {
 		return nil
@@ -407,6 +560,54 @@ func tcpHealthCheckPolicy(hc *contour_api_v1.TCPHealthCheckPolicy) *TCPHealthChe
 	}
 }
 
+// outlierDetection builds an *OutlierDetection for the supplied
+// outlier detection policy.
+func outlierDetection(od *contour_api_v1.OutlierDetection) (*OutlierDetection, error) {
+	if od == nil {
+		return nil, nil
+	}
+
+	if od.ConsecutiveServerErrors < 0 {
+		return nil, fmt.Errorf("consecutive server errors %d must not be negative", od.ConsecutiveServerErrors)
+	}
+	if od.ConsecutiveGatewayErrors < 0 {
+		return nil, fmt.Errorf("consecutive gateway errors %d must not be negative", od.ConsecutiveGatewayErrors)
+	}
+	if od.MaxEjectionPercent < 0 || od.MaxEjectionPercent > 100 {
+		return nil, fmt.Errorf("max ejection percent %d must be in the range 0-100", od.MaxEjectionPercent)
+	}
+
+	res := &OutlierDetection{
+		ConsecutiveServerErrors:  uint32(od.ConsecutiveServerErrors),
+		ConsecutiveGatewayErrors: uint32(od.ConsecutiveGatewayErrors),
+		MaxEjectionPercent:       uint32(od.MaxEjectionPercent),
+	}
+
+	if len(od.Interval) > 0 {
+		interval, err := time.ParseDuration(od.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %s", od.Interval, err)
+		}
+		if interval < 0 {
+			return nil, fmt.Errorf("interval %q must not be negative", od.Interval)
+		}
+		res.Interval = interval
+	}
+
+	if len(od.BaseEjectionTime) > 0 {
+		baseEjectionTime, err := time.ParseDuration(od.BaseEjectionTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base ejection time %q: %s", od.BaseEjectionTime, err)
+		}
+		if baseEjectionTime < 0 {
+			return nil, fmt.Errorf("base ejection time %q must not be negative", od.BaseEjectionTime)
+		}
+		res.BaseEjectionTime = baseEjectionTime
+	}
+
+	return res, nil
+}
+
 // loadBalancerPolicy returns the load balancer strategy or
 // blank if no valid strategy is supplied.
 func loadBalancerPolicy(lbp *contour_api_v1.LoadBalancerPolicy) string {
@@ -447,6 +648,192 @@ func prefixReplacementsAreValid(replacements []contour_api_v1.ReplacePrefix) (st
 	return "", nil
 }
 
+// regexRewritePolicy builds a *RegexRewrite for the regexRewrite or
+// replaceFullPath fields of the supplied path rewrite policy. Anchored
+// patterns are matched following includePrefix, the path prefix that is
+// rendered by the chain of including HTTPProxies.
+func regexRewritePolicy(prp *contour_api_v1.PathRewritePolicy, includePrefix string) (*RegexRewrite, error) {
+	if prp == nil {
+		return nil, nil
+	}
+
+	set := 0
+	if len(prp.ReplacePrefix) > 0 {
+		set++
+	}
+	if prp.RegexRewrite != nil {
+		set++
+	}
+	if prp.ReplaceFullPath != nil {
+		set++
+	}
+	if set > 1 {
+		return nil, errors.New("only one of replacePrefix, regexRewrite or replaceFullPath may be specified")
+	}
+
+	switch {
+	case prp.ReplaceFullPath != nil:
+		path := *prp.ReplaceFullPath
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("replaceFullPath %q must start with a leading slash", path)
+		}
+
+		return &RegexRewrite{
+			Pattern:      "^.*$",
+			Substitution: escapeSubstitution(path),
+		}, nil
+	case prp.RegexRewrite != nil:
+		pattern := prp.RegexRewrite.Pattern
+		substitution := prp.RegexRewrite.Substitution
+
+		if len(pattern) == 0 {
+			return nil, errors.New("regexRewrite pattern must not be empty")
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexRewrite pattern %q: %s", pattern, err)
+		}
+
+		if err := substitutionValid(substitution, re.NumSubexp()); err != nil {
+			return nil, fmt.Errorf("invalid regexRewrite substitution %q: %s", substitution, err)
+		}
+
+		// Anchored patterns are relative to the include prefix, so
+		// quote the prefix into the pattern and preserve it in the
+		// substitution, in the same way as regex match conditions.
+		prefix := strings.TrimRight(includePrefix, "/")
+		if len(prefix) > 0 && strings.HasPrefix(pattern, "^") {
+			pattern = "^" + regexp.QuoteMeta(joinPrefix(prefix, pattern[1:])) + pattern[1:]
+			substitution = escapeSubstitution(joinPrefix(prefix, substitution)) + substitution
+		}
+
+		return &RegexRewrite{
+			Pattern:      pattern,
+			Substitution: substitution,
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// joinPrefix returns prefix with a trailing slash if path does not
+// already start with one.
+func joinPrefix(prefix, path string) string {
+	if strings.HasPrefix(path, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
+
+// escapeSubstitution escapes backslashes in s so that it is
+// substituted literally.
+func escapeSubstitution(s string) string {
+	return strings.ReplaceAll(s, `\`, `\\`)
+}
+
+// substitutionValid checks that the escapes in the regex substitution
+// s are valid and only reference the given number of capture groups.
+func substitutionValid(s string, groups int) error {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			continue
+		}
+
+		i++
+		if i == len(s) {
+			return errors.New("must not end with a backslash")
+		}
+
+		switch c := s[i]; {
+		case c == '\\':
+			// Escaped backslash.
+		case c >= '0' && c <= '9':
+			if n := int(c - '0'); n > groups {
+				return fmt.Errorf("capture group %d does not exist", n)
+			}
+		default:
+			return fmt.Errorf("invalid escape sequence \\%c", c)
+		}
+	}
+
+	return nil
+}
+
+// redirectPolicy builds a *Redirect for the supplied redirect policy.
+func redirectPolicy(rp *contour_api_v1.HTTPRequestRedirectPolicy) (*Redirect, error) {
+	if rp == nil {
+		return nil, nil
+	}
+
+	if rp.Path != nil && rp.Prefix != nil {
+		return nil, errors.New("cannot specify both path and prefix")
+	}
+
+	redirect := &Redirect{
+		Scheme:     pointer.StringPtrDerefOr(rp.Scheme, ""),
+		Hostname:   pointer.StringPtrDerefOr(rp.Hostname, ""),
+		Path:       pointer.StringPtrDerefOr(rp.Path, ""),
+		Prefix:     pointer.StringPtrDerefOr(rp.Prefix, ""),
+		StatusCode: http.StatusFound,
+	}
+
+	if rp.Port != nil {
+		if *rp.Port < 1 || *rp.Port > 65535 {
+			return nil, fmt.Errorf("port %d must be in the range 1-65535", *rp.Port)
+		}
+		redirect.PortNumber = uint32(*rp.Port)
+	}
+
+	switch rp.StatusCode {
+	case 0:
+		// Use the default of 302.
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		redirect.StatusCode = rp.StatusCode
+	default:
+		return nil, fmt.Errorf("status code %d is not supported, must be one of 301, 302, 307 or 308", rp.StatusCode)
+	}
+
+	return redirect, nil
+}
+
+// maxDirectResponseBodySize is the largest direct response body
+// that Envoy accepts by default.
+const maxDirectResponseBodySize = 4096
+
+// directResponsePolicy builds a *DirectResponse for the supplied direct
+// response policy. lookup is used to resolve a body from a ConfigMap.
+func directResponsePolicy(dr *contour_api_v1.HTTPDirectResponsePolicy, lookup func(*contour_api_v1.ConfigMapKeyReference) (string, error)) (*DirectResponse, error) {
+	if dr == nil {
+		return nil, nil
+	}
+
+	if dr.StatusCode < 200 || dr.StatusCode > 599 {
+		return nil, fmt.Errorf("status code %d must be in the range 200-599", dr.StatusCode)
+	}
+
+	if len(dr.Body) > 0 && dr.BodyFromConfigMap != nil {
+		return nil, errors.New("cannot specify both body and bodyFromConfigMap")
+	}
+
+	body := dr.Body
+	if ref := dr.BodyFromConfigMap; ref != nil {
+		var err error
+		if body, err = lookup(ref); err != nil {
+			return nil, fmt.Errorf("unresolved ConfigMap %q key %q: %s", ref.Name, ref.Key, err)
+		}
+	}
+
+	if len(body) > maxDirectResponseBodySize {
+		return nil, fmt.Errorf("body is %d bytes, must not be larger than %d bytes", len(body), maxDirectResponseBodySize)
+	}
+
+	return &DirectResponse{
+		StatusCode: uint32(dr.StatusCode),
+		Body:       body,
+	}, nil
+}
+
 func rateLimitPolicy(in *contour_api_v1.RateLimitPolicy) (*RateLimitPolicy, error) {
 	if in == nil || (in.Local == nil && in.Global == nil) {
 		return nil, nil
@@ -510,7 +897,11 @@ func localRateLimitPolicy(in *contour_api_v1.LocalRateLimitPolicy) (*LocalRateLi
 		if msgs := validation.IsHTTPHeaderName(key); len(msgs) != 0 {
 			return nil, fmt.Errorf("invalid header name %q: %v", key, msgs)
 		}
-		res.ResponseHeadersToAdd[key] = escapeHeaderValue(header.Value, map[string]string{})
+		value, err := escapeHeaderValue(header.Value, map[string]string{})
+		if err != nil {
+			return nil, fmt.Errorf("invalid header %q: %v", key, err)
+		}
+		res.ResponseHeadersToAdd[key] = value
 	}
 
 	return res, nil
@@ -583,6 +974,138 @@ func globalRateLimitPolicy(in *contour_api_v1.GlobalRateLimitPolicy) (*GlobalRat
 	return res, nil
 }
 
+// faultInjectionPolicy builds a *FaultInjectionPolicy for the supplied
+// fault injection policy.
+func faultInjectionPolicy(in *contour_api_v1.FaultInjectionPolicy) (*FaultInjectionPolicy, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	if in.Delay == nil && in.Abort == nil {
+		return nil, errors.New("at least one of delay or abort must be specified")
+	}
+
+	fp := &FaultInjectionPolicy{}
+
+	if in.Delay != nil {
+		duration, err := time.ParseDuration(in.Delay.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delay duration %q: %s", in.Delay.Duration, err)
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("delay duration %q must be greater than zero", in.Delay.Duration)
+		}
+		percent, err := faultPercent(in.Delay.Percentage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delay: %s", err)
+		}
+		fp.Delay = &FaultDelay{
+			Duration: duration,
+			Percent:  percent,
+		}
+	}
+
+	if in.Abort != nil {
+		if in.Abort.StatusCode < 400 || in.Abort.StatusCode > 599 {
+			return nil, fmt.Errorf("abort status code %d must be in the range 400-599", in.Abort.StatusCode)
+		}
+		percent, err := faultPercent(in.Abort.Percentage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid abort: %s", err)
+		}
+		fp.Abort = &FaultAbort{
+			StatusCode: uint32(in.Abort.StatusCode),
+			Percent:    percent,
+		}
+	}
+
+	var conds []contour_api_v1.MatchCondition
+	for i := range in.Headers {
+		conds = append(conds, contour_api_v1.MatchCondition{Header: &in.Headers[i]})
+	}
+	if err := headerMatchConditionsValid(conds); err != nil {
+		return nil, err
+	}
+	fp.HeaderMatchConditions = mergeHeaderMatchConditions(conds)
+
+	return fp, nil
+}
+
+// faultPercent returns the percentage of requests that a fault
+// applies to, defaulting to all requests.
+func faultPercent(percent int64) (uint32, error) {
+	switch {
+	case percent < 0 || percent > 100:
+		return 0, fmt.Errorf("percentage %d must be in the range 0-100", percent)
+	case percent == 0:
+		return 100, nil
+	default:
+		return uint32(percent), nil
+	}
+}
+
+// cookieNameRegex matches a cookie name, which must be an RFC 7230 token.
+var cookieNameRegex = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// cookieHashOptions validates the supplied cookie hash options and
+// returns them with defaults applied for any unset fields.
+func cookieHashOptions(in *contour_api_v1.CookieHashOptions) (*CookieHashOptions, error) {
+	cookie := &CookieHashOptions{
+		CookieName: "X-Contour-Session-Affinity",
+		Path:       "/",
+	}
+
+	if in == nil {
+		return cookie, nil
+	}
+
+	if len(in.CookieName) > 0 {
+		if !cookieNameRegex.MatchString(in.CookieName) {
+			return nil, fmt.Errorf("invalid cookie name %q: must consist of RFC 7230 token characters", in.CookieName)
+		}
+		cookie.CookieName = in.CookieName
+	}
+
+	if len(in.TTL) > 0 {
+		ttl, err := time.ParseDuration(in.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie TTL %q: %s", in.TTL, err)
+		}
+		if ttl < 0 {
+			return nil, fmt.Errorf("cookie TTL %q must not be negative", in.TTL)
+		}
+		cookie.TTL = ttl
+	}
+
+	if len(in.Path) > 0 {
+		if !strings.HasPrefix(in.Path, "/") {
+			return nil, fmt.Errorf("cookie path %q must start with a leading slash", in.Path)
+		}
+		cookie.Path = in.Path
+	}
+
+	return cookie, nil
+}
+
+// requestHashOptionsSet returns the number of hash options
+// that are set on the supplied request hash policy.
+func requestHashOptionsSet(hp contour_api_v1.RequestHashPolicy) int {
+	set := 0
+	if hp.HeaderHashOptions != nil {
+		set++
+	}
+	if hp.CookieHashOptions != nil {
+		set++
+	}
+	if hp.QueryParameterHashOptions != nil {
+		set++
+	}
+	if hp.HashSourceIP {
+		set++
+	}
+	return set
+}
+
 // Validates and returns list of hash policies along with lb actual strategy to
 // be used. Will return default strategy and empty list of hash policies if
 // validation fails.
@@ -593,43 +1116,108 @@ func loadBalancerRequestHashPolicies(lbp *contour_api_v1.LoadBalancerPolicy, val
 	strategy := loadBalancerPolicy(lbp)
 	switch strategy {
 	case LoadBalancerPolicyCookie:
+		cookie, err := cookieHashOptions(lbp.CookieHashOptions)
+		if err != nil {
+			validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
+				"ignoring invalid cookie hash policy options, using the default session affinity cookie: %s", err)
+			cookie, _ = cookieHashOptions(nil)
+		}
 		return []RequestHashPolicy{
-			{CookieHashOptions: &CookieHashOptions{
-				CookieName: "X-Contour-Session-Affinity",
-				TTL:        time.Duration(0),
-				Path:       "/",
-			}},
+			{CookieHashOptions: cookie},
 		}, LoadBalancerPolicyCookie
 	case LoadBalancerPolicyRequestHash:
 		rhp := []RequestHashPolicy{}
 		actualStrategy := strategy
 		// Map of unique header names.
 		headerHashPolicies := map[string]bool{}
+		// Map of unique cookie names.
+		cookieHashPolicies := map[string]bool{}
+		// Map of unique query parameter names.
+		queryParameterHashPolicies := map[string]bool{}
+		sourceIPHashPolicy := false
 		for _, hashPolicy := range lbp.RequestHashPolicies {
-			if hashPolicy.HeaderHashOptions == nil {
+			if set := requestHashOptionsSet(hashPolicy); set == 0 {
 				validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
 					"ignoring invalid nil hash policy options")
 				continue
-			}
-			headerName := http.CanonicalHeaderKey(hashPolicy.HeaderHashOptions.HeaderName)
-			if msgs := validation.IsHTTPHeaderName(headerName); len(msgs) != 0 {
+			} else if set > 1 {
 				validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
-					"ignoring invalid header hash policy options with invalid header name %q: %v", headerName, msgs)
-				continue
-			}
-			if _, ok := headerHashPolicies[headerName]; ok {
-				validCond.AddWarningf("SpecError", "IgnoredField",
-					"ignoring invalid header hash policy options with duplicated header name %s", headerName)
+					"ignoring invalid hash policy options with more than one hash option set")
 				continue
 			}
-			headerHashPolicies[headerName] = true
 
-			rhp = append(rhp, RequestHashPolicy{
-				Terminal: hashPolicy.Terminal,
-				HeaderHashOptions: &HeaderHashOptions{
-					HeaderName: headerName,
-				},
-			})
+			switch {
+			case hashPolicy.HashSourceIP:
+				if sourceIPHashPolicy {
+					validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
+						"ignoring invalid duplicated source IP hash policy options")
+					continue
+				}
+				sourceIPHashPolicy = true
+
+				rhp = append(rhp, RequestHashPolicy{
+					Terminal:     hashPolicy.Terminal,
+					HashSourceIP: true,
+				})
+			case hashPolicy.QueryParameterHashOptions != nil:
+				parameterName := hashPolicy.QueryParameterHashOptions.ParameterName
+				if len(parameterName) == 0 {
+					validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
+						"ignoring invalid query parameter hash policy options with empty parameter name")
+					continue
+				}
+				if _, ok := queryParameterHashPolicies[parameterName]; ok {
+					validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
+						"ignoring invalid query parameter hash policy options with duplicated parameter name %s", parameterName)
+					continue
+				}
+				queryParameterHashPolicies[parameterName] = true
+
+				rhp = append(rhp, RequestHashPolicy{
+					Terminal: hashPolicy.Terminal,
+					QueryParameterHashOptions: &QueryParameterHashOptions{
+						ParameterName: parameterName,
+					},
+				})
+			case hashPolicy.CookieHashOptions != nil:
+				cookie, err := cookieHashOptions(hashPolicy.CookieHashOptions)
+				if err != nil {
+					validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
+						"ignoring invalid cookie hash policy options: %s", err)
+					continue
+				}
+				if _, ok := cookieHashPolicies[cookie.CookieName]; ok {
+					validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
+						"ignoring invalid cookie hash policy options with duplicated cookie name %s", cookie.CookieName)
+					continue
+				}
+				cookieHashPolicies[cookie.CookieName] = true
+
+				rhp = append(rhp, RequestHashPolicy{
+					Terminal:          hashPolicy.Terminal,
+					CookieHashOptions: cookie,
+				})
+			default:
+				headerName := http.CanonicalHeaderKey(hashPolicy.HeaderHashOptions.HeaderName)
+				if msgs := validation.IsHTTPHeaderName(headerName); len(msgs) != 0 {
+					validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
+						"ignoring invalid header hash policy options with invalid header name %q: %v", headerName, msgs)
+					continue
+				}
+				if _, ok := headerHashPolicies[headerName]; ok {
+					validCond.AddWarningf("SpecError", "IgnoredField",
+						"ignoring invalid header hash policy options with duplicated header name %s", headerName)
+					continue
+				}
+				headerHashPolicies[headerName] = true
+
+				rhp = append(rhp, RequestHashPolicy{
+					Terminal: hashPolicy.Terminal,
+					HeaderHashOptions: &HeaderHashOptions{
+						HeaderName: headerName,
+					},
+				})
+			}
 		}
 		if len(rhp) == 0 {
 			validCond.AddWarningf(contour_api_v1.ConditionTypeSpecError, "IgnoredField",
@@ -643,3 +1231,318 @@ func loadBalancerRequestHashPolicies(lbp *contour_api_v1.LoadBalancerPolicy, val
 	}
 
 }
+
+// circuitBreakerPolicy returns the circuit breaking thresholds for a
+// cluster of the supplied service. Thresholds set by the HTTPProxy take
+// precedence over the annotations of the service, which in turn take
+// precedence over the supplied defaults. If neither the HTTPProxy nor
+// the defaults set any thresholds, nil is returned so the annotations
+// of the service are used as-is.
+func circuitBreakerPolicy(s *Service, in *contour_api_v1.CircuitBreakerPolicy, defaults *CircuitBreakerPolicy) (*CircuitBreakerPolicy, error) {
+	if in == nil && defaults == nil {
+		return nil, nil
+	}
+
+	res := &CircuitBreakerPolicy{}
+	if defaults != nil {
+		*res = *defaults
+	}
+
+	override := func(field *uint32, v uint32) {
+		if v > 0 {
+			*field = v
+		}
+	}
+	override(&res.MaxConnections, s.MaxConnections)
+	override(&res.MaxPendingRequests, s.MaxPendingRequests)
+	override(&res.MaxRequests, s.MaxRequests)
+	override(&res.MaxRetries, s.MaxRetries)
+
+	if in == nil {
+		return res, nil
+	}
+
+	for _, t := range []struct {
+		name  string
+		value int64
+		field *uint32
+	}{
+		{"max connections", in.MaxConnections, &res.MaxConnections},
+		{"max pending requests", in.MaxPendingRequests, &res.MaxPendingRequests},
+		{"max requests", in.MaxRequests, &res.MaxRequests},
+		{"max retries", in.MaxRetries, &res.MaxRetries},
+	} {
+		if t.value < 0 || t.value > math.MaxUint32 {
+			return nil, fmt.Errorf("%s %d must be in the range 0-%d", t.name, t.value, uint32(math.MaxUint32))
+		}
+		override(t.field, uint32(t.value))
+	}
+
+	return res, nil
+}
+
+// ipFilterPolicy builds IP filter rules for the supplied allow and deny
+// policies, and returns whether the rules allow the requests that match
+// them. At most one of the policies may be specified.
+func ipFilterPolicy(allow, deny []contour_api_v1.IPFilterPolicy) (bool, []IPFilterRule, error) {
+	if len(allow) > 0 && len(deny) > 0 {
+		return false, nil, errors.New("cannot specify both ipAllowPolicy and ipDenyPolicy")
+	}
+
+	policies := deny
+	if len(allow) > 0 {
+		policies = allow
+	}
+
+	var rules []IPFilterRule
+	for _, p := range policies {
+		var remote bool
+		switch p.Source {
+		case contour_api_v1.IPFilterSourceRemote:
+			remote = true
+		case contour_api_v1.IPFilterSourcePeer:
+			remote = false
+		default:
+			return false, nil, fmt.Errorf("invalid IP filter source %q", p.Source)
+		}
+
+		cidr, err := parseCIDR(p.CIDR)
+		if err != nil {
+			return false, nil, err
+		}
+
+		rules = append(rules, IPFilterRule{
+			Remote: remote,
+			CIDR:   *cidr,
+		})
+	}
+
+	return len(allow) > 0, rules, nil
+}
+
+// parseCIDR parses a CIDR block. A bare IP address is
+// treated as a block containing only that address.
+func parseCIDR(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid CIDR %q", s)
+		}
+
+		bits := net.IPv6len * 8
+		if v4 := ip.To4(); v4 != nil {
+			ip = v4
+			bits = net.IPv4len * 8
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, cidr, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q", s)
+	}
+
+	return cidr, nil
+}
+
+// jwtIdentifierRegex matches the JWT provider and claim names that can
+// be safely used to look up verified claims in the request metadata.
+var jwtIdentifierRegex = regexp.MustCompile(`^[A-Za-z0-9_.:/-]+$`)
+
+// jwtProviders validates the supplied JWT providers and returns the
+// corresponding pre-validated providers. The clusters of remote JWKS
+// providers do not have their upstream validation set.
+func jwtProviders(providers []contour_api_v1.JWTProvider) ([]JWTProvider, error) {
+	var (
+		res         []JWTProvider
+		names       = sets.NewString()
+		defaultName string
+	)
+
+	for _, p := range providers {
+		if !jwtIdentifierRegex.MatchString(p.Name) {
+			return nil, fmt.Errorf("invalid provider name %q", p.Name)
+		}
+		if names.Has(p.Name) {
+			return nil, fmt.Errorf("duplicate provider name %q", p.Name)
+		}
+		names.Insert(p.Name)
+
+		if p.Default {
+			if len(defaultName) > 0 {
+				return nil, fmt.Errorf("providers %q and %q are both marked as the default", defaultName, p.Name)
+			}
+			defaultName = p.Name
+		}
+
+		provider := JWTProvider{
+			Name:       p.Name,
+			Issuer:     p.Issuer,
+			Audiences:  p.Audiences,
+			ForwardJWT: p.ForwardJWT,
+		}
+
+		switch {
+		case p.RemoteJWKS != nil && len(p.LocalJWKS) > 0:
+			return nil, fmt.Errorf("provider %q: cannot specify both remoteJWKS and localJWKS", p.Name)
+		case p.RemoteJWKS != nil:
+			jwks, err := remoteJWKS(p.RemoteJWKS)
+			if err != nil {
+				return nil, fmt.Errorf("provider %q: %s", p.Name, err)
+			}
+			provider.RemoteJWKS = jwks
+		case len(p.LocalJWKS) > 0:
+			if err := localJWKSValid(p.LocalJWKS); err != nil {
+				return nil, fmt.Errorf("provider %q: %s", p.Name, err)
+			}
+			provider.LocalJWKS = p.LocalJWKS
+		default:
+			return nil, fmt.Errorf("provider %q: must specify one of remoteJWKS or localJWKS", p.Name)
+		}
+
+		headers := sets.NewString()
+		for _, c := range p.ClaimsToHeaders {
+			if !jwtIdentifierRegex.MatchString(c.Claim) {
+				return nil, fmt.Errorf("provider %q: invalid claim name %q", p.Name, c.Claim)
+			}
+
+			key := http.CanonicalHeaderKey(c.Header)
+			if msgs := validation.IsHTTPHeaderName(key); len(msgs) != 0 {
+				return nil, fmt.Errorf("provider %q: invalid claim header %q: %v", p.Name, key, msgs)
+			}
+			if key == "Host" {
+				return nil, fmt.Errorf("provider %q: forwarding a claim as the %q header is not supported", p.Name, key)
+			}
+			if headers.Has(key) {
+				return nil, fmt.Errorf("provider %q: duplicate claim header %q", p.Name, key)
+			}
+			headers.Insert(key)
+
+			provider.ClaimsToHeaders = append(provider.ClaimsToHeaders, JWTClaimToHeader{
+				Claim:  c.Claim,
+				Header: key,
+			})
+		}
+
+		res = append(res, provider)
+	}
+
+	return res, nil
+}
+
+// remoteJWKS validates the supplied remote JWKS and returns the
+// corresponding RemoteJWKS. The default timeout is 1s.
+func remoteJWKS(in *contour_api_v1.RemoteJWKS) (*RemoteJWKS, error) {
+	uri, err := url.Parse(in.URI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote JWKS URI %q: %s", in.URI, err)
+	}
+
+	var port int
+	switch uri.Scheme {
+	case "http":
+		port = 80
+	case "https":
+		port = 443
+	default:
+		return nil, fmt.Errorf("remote JWKS URI %q must use the http or https scheme", in.URI)
+	}
+
+	if len(uri.Hostname()) == 0 {
+		return nil, fmt.Errorf("remote JWKS URI %q must specify a host", in.URI)
+	}
+
+	if len(uri.Port()) > 0 {
+		port, err = strconv.Atoi(uri.Port())
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("remote JWKS URI %q has an invalid port", in.URI)
+		}
+	}
+
+	if in.UpstreamValidation != nil && uri.Scheme != "https" {
+		return nil, fmt.Errorf("remote JWKS validation may only be specified for https URIs")
+	}
+
+	res := &RemoteJWKS{
+		URI:     in.URI,
+		Timeout: time.Second,
+		Cluster: DNSNameCluster{
+			Address: uri.Hostname(),
+			Scheme:  uri.Scheme,
+			Port:    port,
+		},
+	}
+
+	if len(in.Timeout) > 0 {
+		res.Timeout, err = time.ParseDuration(in.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote JWKS timeout: %s", err)
+		}
+		if res.Timeout <= 0 {
+			return nil, fmt.Errorf("remote JWKS timeout must be greater than zero")
+		}
+	}
+
+	if len(in.CacheDuration) > 0 {
+		res.CacheDuration, err = time.ParseDuration(in.CacheDuration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote JWKS cache duration: %s", err)
+		}
+		if res.CacheDuration <= 0 {
+			return nil, fmt.Errorf("remote JWKS cache duration must be greater than zero")
+		}
+	}
+
+	return res, nil
+}
+
+// localJWKSValid returns an error if the supplied inline
+// JWKS is not a JSON object with at least one key.
+func localJWKSValid(jwks string) error {
+	var keySet struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+
+	if err := json.Unmarshal([]byte(jwks), &keySet); err != nil {
+		return fmt.Errorf("invalid local JWKS: %s", err)
+	}
+
+	if len(keySet.Keys) == 0 {
+		return errors.New("local JWKS does not contain any keys")
+	}
+
+	return nil
+}
+
+// jwtVerificationProvider returns the name of the JWT provider that
+// verifies requests to a route with the supplied policy. The result
+// is empty if JWT verification is disabled for the route.
+func jwtVerificationProvider(policy *contour_api_v1.JWTVerificationPolicy, providers []contour_api_v1.JWTProvider) (string, error) {
+	var defaultName string
+	for _, p := range providers {
+		if p.Default {
+			defaultName = p.Name
+		}
+	}
+
+	if policy == nil {
+		return defaultName, nil
+	}
+
+	switch {
+	case len(policy.Require) > 0 && policy.Disabled:
+		return "", errors.New("cannot specify both require and disabled")
+	case policy.Disabled:
+		return "", nil
+	case len(policy.Require) > 0:
+		for _, p := range providers {
+			if p.Name == policy.Require {
+				return p.Name, nil
+			}
+		}
+		return "", fmt.Errorf("JWT provider %q is not defined on the root virtual host", policy.Require)
+	default:
+		return defaultName, nil
+	}
+}
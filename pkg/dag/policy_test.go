@@ -14,7 +14,10 @@
 package dag
 
 import (
+	"errors"
 	"io/ioutil"
+	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,6 +27,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	networking_v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 func TestRetryPolicyIngress(t *testing.T) {
@@ -117,8 +121,9 @@ func TestRetryPolicyIngress(t *testing.T) {
 
 func TestRetryPolicy(t *testing.T) {
 	tests := map[string]struct {
-		rp   *contour_api_v1.RetryPolicy
-		want *RetryPolicy
+		rp      *contour_api_v1.RetryPolicy
+		want    *RetryPolicy
+		wantErr bool
 	}{
 		"nil retry policy": {
 			rp:   nil,
@@ -179,11 +184,145 @@ func TestRetryPolicy(t *testing.T) {
 				NumRetries:           1,
 			},
 		},
+		"retry backoff": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryBackoff: &contour_api_v1.RetryBackoff{
+					BaseInterval: "50ms",
+					MaxInterval:  "1s",
+				},
+			},
+			want: &RetryPolicy{
+				RetryOn:    "5xx",
+				NumRetries: 1,
+				Backoff: &RetryBackoff{
+					BaseInterval: 50 * time.Millisecond,
+					MaxInterval:  time.Second,
+				},
+			},
+		},
+		"retry backoff without max interval": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryBackoff: &contour_api_v1.RetryBackoff{
+					BaseInterval: "50ms",
+				},
+			},
+			want: &RetryPolicy{
+				RetryOn:    "5xx",
+				NumRetries: 1,
+				Backoff: &RetryBackoff{
+					BaseInterval: 50 * time.Millisecond,
+				},
+			},
+		},
+		"retry backoff without base interval": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryBackoff: &contour_api_v1.RetryBackoff{
+					MaxInterval: "1s",
+				},
+			},
+			wantErr: true,
+		},
+		"retry backoff with zero base interval": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryBackoff: &contour_api_v1.RetryBackoff{
+					BaseInterval: "0s",
+				},
+			},
+			wantErr: true,
+		},
+		"retry backoff max interval less than base interval": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryBackoff: &contour_api_v1.RetryBackoff{
+					BaseInterval: "1s",
+					MaxInterval:  "50ms",
+				},
+			},
+			wantErr: true,
+		},
+		"retry host predicates": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryHostPredicate: []contour_api_v1.RetryHostPredicate{
+					"previous-hosts",
+					"omit-canary-hosts",
+				},
+				HostSelectionRetryMaxAttempts: 3,
+			},
+			want: &RetryPolicy{
+				RetryOn:    "5xx",
+				NumRetries: 1,
+				HostPredicates: []RetryHostPredicate{
+					RetryHostPredicatePreviousHosts,
+					RetryHostPredicateOmitCanaryHosts,
+				},
+				HostSelectionMaxAttempts: 3,
+			},
+		},
+		"invalid retry host predicate": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryHostPredicate: []contour_api_v1.RetryHostPredicate{"random-hosts"},
+			},
+			wantErr: true,
+		},
+		"duplicate retry host predicate": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryHostPredicate: []contour_api_v1.RetryHostPredicate{"previous-hosts", "previous-hosts"},
+			},
+			wantErr: true,
+		},
+		"negative host selection retry max attempts": {
+			rp: &contour_api_v1.RetryPolicy{
+				HostSelectionRetryMaxAttempts: -1,
+			},
+			wantErr: true,
+		},
+		"retriable headers": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetryOn: []contour_api_v1.RetryOn{"retriable-headers"},
+				RetriableHeaders: []contour_api_v1.HeaderMatchCondition{{
+					Name:  "x-upstream-retry",
+					Exact: "true",
+				}},
+				RetriableRequestHeaders: []contour_api_v1.HeaderMatchCondition{{
+					Name:     ":method",
+					NotExact: "POST",
+				}},
+			},
+			want: &RetryPolicy{
+				RetryOn:    "retriable-headers",
+				NumRetries: 1,
+				RetriableHeaders: []HeaderMatchCondition{{
+					Name:      "x-upstream-retry",
+					Value:     "true",
+					MatchType: HeaderMatchTypeExact,
+				}},
+				RetriableRequestHeaders: []HeaderMatchCondition{{
+					Name:      ":method",
+					Value:     "POST",
+					MatchType: HeaderMatchTypeExact,
+					Invert:    true,
+				}},
+			},
+		},
+		"invalid retriable header regex": {
+			rp: &contour_api_v1.RetryPolicy{
+				RetriableHeaders: []contour_api_v1.HeaderMatchCondition{{
+					Name:  "x-upstream-retry",
+					Regex: "[",
+				}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			got := retryPolicy(tc.rp)
+			got, err := retryPolicy(tc.rp)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
 			assert.Equal(t, tc.want, got)
 		})
 	}
@@ -351,20 +490,40 @@ func TestHeadersPolicy(t *testing.T) {
 				},
 			},
 		},
-		"unknown Envoy dynamic header is escaped": {
+		"unknown Envoy dynamic header is rejected": {
 			hp: &contour_api_v1.HeadersPolicy{
 				Set: []contour_api_v1.HeaderValue{{
 					Name:  "X-Envoy-Unknown",
 					Value: "%UNKNOWN%",
 				}},
 			},
+			dhp:     HeadersPolicy{},
+			wantErr: true,
+		},
+		"stray percentages are escaped": {
+			hp: &contour_api_v1.HeadersPolicy{
+				Set: []contour_api_v1.HeaderValue{{
+					Name:  "X-Discount",
+					Value: "50% off for %REQUESTED_SERVER_NAME%, 100%",
+				}},
+			},
 			dhp: HeadersPolicy{},
 			want: HeadersPolicy{
 				Set: map[string]string{
-					"X-Envoy-Unknown": "%%UNKNOWN%%",
+					"X-Discount": "50%% off for %REQUESTED_SERVER_NAME%, 100%%",
 				},
 			},
 		},
+		"known Envoy dynamic header with an argument is rejected": {
+			hp: &contour_api_v1.HeadersPolicy{
+				Set: []contour_api_v1.HeaderValue{{
+					Name:  "X-Envoy-Hostname",
+					Value: "%HOSTNAME(short)%",
+				}},
+			},
+			dhp:     HeadersPolicy{},
+			wantErr: true,
+		},
 		"valid Envoy REQ header unescaped": {
 			hp: &contour_api_v1.HeadersPolicy{
 				Set: []contour_api_v1.HeaderValue{{
@@ -379,19 +538,15 @@ func TestHeadersPolicy(t *testing.T) {
 				},
 			},
 		},
-		"invalid Envoy REQ header is escaped": {
+		"invalid Envoy REQ header is rejected": {
 			hp: &contour_api_v1.HeadersPolicy{
 				Set: []contour_api_v1.HeaderValue{{
 					Name:  "X-Request-Host",
 					Value: "%REQ(inv@lid-header)%",
 				}},
 			},
-			dhp: HeadersPolicy{},
-			want: HeadersPolicy{
-				Set: map[string]string{
-					"X-Request-Host": "%%REQ(inv@lid-header)%%",
-				},
-			},
+			dhp:     HeadersPolicy{},
+			wantErr: true,
 		},
 		"header value with dynamic and non-dynamic content and multiple dynamic fields": {
 			hp: &contour_api_v1.HeadersPolicy{
@@ -457,6 +612,24 @@ func TestHeadersPolicy(t *testing.T) {
 				},
 			},
 		},
+		"default header value with unknown variable escaped": {
+			dhp: HeadersPolicy{
+				Set: map[string]string{
+					"X-Unknown":     "%ABC%",
+					"X-Hostname":    "%HOSTNAME(foo)%",
+					"X-Service":     "%CONTOUR_SERVICE_NAME%",
+					"X-Request-Foo": "%REQ(Foo)%",
+				},
+			},
+			want: HeadersPolicy{
+				Set: map[string]string{
+					"X-Unknown":     "%%ABC%%",
+					"X-Hostname":    "%%HOSTNAME(foo)%%",
+					"X-Service":     "myservice",
+					"X-Request-Foo": "%REQ(Foo)%",
+				},
+			},
+		},
 		"same header removed in default and object": {
 			hp: &contour_api_v1.HeadersPolicy{
 				Remove: []string{"X-Sensitive-Header"},
@@ -848,3 +1021,1263 @@ func TestRateLimitPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestRedirectPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.HTTPRequestRedirectPolicy
+		want    *Redirect
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"empty policy defaults to 302": {
+			in: &contour_api_v1.HTTPRequestRedirectPolicy{},
+			want: &Redirect{
+				StatusCode: 302,
+			},
+		},
+		"all fields": {
+			in: &contour_api_v1.HTTPRequestRedirectPolicy{
+				Scheme:     pointer.StringPtr("https"),
+				Hostname:   pointer.StringPtr("envoyproxy.io"),
+				Port:       pointer.Int32Ptr(8443),
+				StatusCode: 301,
+				Prefix:     pointer.StringPtr("/blog"),
+			},
+			want: &Redirect{
+				Scheme:     "https",
+				Hostname:   "envoyproxy.io",
+				PortNumber: 8443,
+				StatusCode: 301,
+				Prefix:     "/blog",
+			},
+		},
+		"path": {
+			in: &contour_api_v1.HTTPRequestRedirectPolicy{
+				Path:       pointer.StringPtr("/new"),
+				StatusCode: 308,
+			},
+			want: &Redirect{
+				Path:       "/new",
+				StatusCode: 308,
+			},
+		},
+		"path and prefix": {
+			in: &contour_api_v1.HTTPRequestRedirectPolicy{
+				Path:   pointer.StringPtr("/new"),
+				Prefix: pointer.StringPtr("/blog"),
+			},
+			wantErr: "cannot specify both path and prefix",
+		},
+		"invalid port": {
+			in: &contour_api_v1.HTTPRequestRedirectPolicy{
+				Port: pointer.Int32Ptr(65536),
+			},
+			wantErr: "port 65536 must be in the range 1-65535",
+		},
+		"unsupported status code": {
+			in: &contour_api_v1.HTTPRequestRedirectPolicy{
+				StatusCode: 303,
+			},
+			wantErr: "status code 303 is not supported, must be one of 301, 302, 307 or 308",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := redirectPolicy(tc.in)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDirectResponsePolicy(t *testing.T) {
+	lookup := func(ref *contour_api_v1.ConfigMapKeyReference) (string, error) {
+		switch ref.Name {
+		case "body":
+			return "service unavailable", nil
+		case "large":
+			return strings.Repeat("a", 4097), nil
+		default:
+			return "", errors.New("ConfigMap not found")
+		}
+	}
+
+	tests := map[string]struct {
+		in      *contour_api_v1.HTTPDirectResponsePolicy
+		want    *DirectResponse
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"status code only": {
+			in: &contour_api_v1.HTTPDirectResponsePolicy{
+				StatusCode: 503,
+			},
+			want: &DirectResponse{
+				StatusCode: 503,
+			},
+		},
+		"inline body": {
+			in: &contour_api_v1.HTTPDirectResponsePolicy{
+				StatusCode: 200,
+				Body:       "ok",
+			},
+			want: &DirectResponse{
+				StatusCode: 200,
+				Body:       "ok",
+			},
+		},
+		"body from configmap": {
+			in: &contour_api_v1.HTTPDirectResponsePolicy{
+				StatusCode: 503,
+				BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+					Name: "body",
+					Key:  "body",
+				},
+			},
+			want: &DirectResponse{
+				StatusCode: 503,
+				Body:       "service unavailable",
+			},
+		},
+		"status code out of range": {
+			in: &contour_api_v1.HTTPDirectResponsePolicy{
+				StatusCode: 600,
+			},
+			wantErr: "status code 600 must be in the range 200-599",
+		},
+		"body and body from configmap": {
+			in: &contour_api_v1.HTTPDirectResponsePolicy{
+				StatusCode: 503,
+				Body:       "ok",
+				BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+					Name: "body",
+					Key:  "body",
+				},
+			},
+			wantErr: "cannot specify both body and bodyFromConfigMap",
+		},
+		"missing configmap": {
+			in: &contour_api_v1.HTTPDirectResponsePolicy{
+				StatusCode: 503,
+				BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+					Name: "missing",
+					Key:  "body",
+				},
+			},
+			wantErr: `unresolved ConfigMap "missing" key "body": ConfigMap not found`,
+		},
+		"inline body too large": {
+			in: &contour_api_v1.HTTPDirectResponsePolicy{
+				StatusCode: 200,
+				Body:       strings.Repeat("a", 4097),
+			},
+			wantErr: "body is 4097 bytes, must not be larger than 4096 bytes",
+		},
+		"body from configmap too large": {
+			in: &contour_api_v1.HTTPDirectResponsePolicy{
+				StatusCode: 200,
+				BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+					Name: "large",
+					Key:  "body",
+				},
+			},
+			wantErr: "body is 4097 bytes, must not be larger than 4096 bytes",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := directResponsePolicy(tc.in, lookup)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFaultInjectionPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.FaultInjectionPolicy
+		want    *FaultInjectionPolicy
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"delay and abort with default percentages": {
+			in: &contour_api_v1.FaultInjectionPolicy{
+				Delay: &contour_api_v1.FaultDelay{
+					Duration: "500ms",
+				},
+				Abort: &contour_api_v1.FaultAbort{
+					StatusCode: 503,
+				},
+			},
+			want: &FaultInjectionPolicy{
+				Delay: &FaultDelay{
+					Duration: 500 * time.Millisecond,
+					Percent:  100,
+				},
+				Abort: &FaultAbort{
+					StatusCode: 503,
+					Percent:    100,
+				},
+			},
+		},
+		"percentages and header trigger": {
+			in: &contour_api_v1.FaultInjectionPolicy{
+				Delay: &contour_api_v1.FaultDelay{
+					Duration:   "1s",
+					Percentage: 25,
+				},
+				Abort: &contour_api_v1.FaultAbort{
+					StatusCode: 500,
+					Percentage: 5,
+				},
+				Headers: []contour_api_v1.HeaderMatchCondition{{
+					Name:    "X-Chaos",
+					Present: true,
+				}},
+			},
+			want: &FaultInjectionPolicy{
+				Delay: &FaultDelay{
+					Duration: time.Second,
+					Percent:  25,
+				},
+				Abort: &FaultAbort{
+					StatusCode: 500,
+					Percent:    5,
+				},
+				HeaderMatchConditions: []HeaderMatchCondition{{
+					Name:      "X-Chaos",
+					MatchType: HeaderMatchTypePresent,
+				}},
+			},
+		},
+		"no faults": {
+			in:      &contour_api_v1.FaultInjectionPolicy{},
+			wantErr: "at least one of delay or abort must be specified",
+		},
+		"invalid delay duration": {
+			in: &contour_api_v1.FaultInjectionPolicy{
+				Delay: &contour_api_v1.FaultDelay{
+					Duration: "soon",
+				},
+			},
+			wantErr: `invalid delay duration "soon": time: invalid duration "soon"`,
+		},
+		"zero delay duration": {
+			in: &contour_api_v1.FaultInjectionPolicy{
+				Delay: &contour_api_v1.FaultDelay{
+					Duration: "0s",
+				},
+			},
+			wantErr: `delay duration "0s" must be greater than zero`,
+		},
+		"delay percentage out of range": {
+			in: &contour_api_v1.FaultInjectionPolicy{
+				Delay: &contour_api_v1.FaultDelay{
+					Duration:   "1s",
+					Percentage: 101,
+				},
+			},
+			wantErr: "invalid delay: percentage 101 must be in the range 0-100",
+		},
+		"abort status code out of range": {
+			in: &contour_api_v1.FaultInjectionPolicy{
+				Abort: &contour_api_v1.FaultAbort{
+					StatusCode: 200,
+				},
+			},
+			wantErr: "abort status code 200 must be in the range 400-599",
+		},
+		"abort percentage out of range": {
+			in: &contour_api_v1.FaultInjectionPolicy{
+				Abort: &contour_api_v1.FaultAbort{
+					StatusCode: 503,
+					Percentage: -1,
+				},
+			},
+			wantErr: "invalid abort: percentage -1 must be in the range 0-100",
+		},
+		"contradictory header trigger": {
+			in: &contour_api_v1.FaultInjectionPolicy{
+				Abort: &contour_api_v1.FaultAbort{
+					StatusCode: 503,
+				},
+				Headers: []contour_api_v1.HeaderMatchCondition{{
+					Name:  "X-Chaos",
+					Exact: "true",
+				}, {
+					Name:     "X-Chaos",
+					NotExact: "true",
+				}},
+			},
+			wantErr: "cannot specify contradictory 'exact' and 'notexact' conditions for the same route and header",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := faultInjectionPolicy(tc.in)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRegexRewritePolicy(t *testing.T) {
+	tests := map[string]struct {
+		in            *contour_api_v1.PathRewritePolicy
+		includePrefix string
+		want          *RegexRewrite
+		wantErr       string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"replace prefix only": {
+			in: &contour_api_v1.PathRewritePolicy{
+				ReplacePrefix: []contour_api_v1.ReplacePrefix{{Replacement: "/"}},
+			},
+			want: nil,
+		},
+		"regex rewrite": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{
+					Pattern:      `^/service/([^/]+)(/.*)$`,
+					Substitution: `\2/instance/\1`,
+				},
+			},
+			want: &RegexRewrite{
+				Pattern:      `^/service/([^/]+)(/.*)$`,
+				Substitution: `\2/instance/\1`,
+			},
+		},
+		"anchored regex rewrite with include prefix": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{
+					Pattern:      `^/v1/(.*)$`,
+					Substitution: `/v2/\1`,
+				},
+			},
+			includePrefix: "/api.v1/",
+			want: &RegexRewrite{
+				Pattern:      `^/api\.v1/v1/(.*)$`,
+				Substitution: `/api.v1/v2/\1`,
+			},
+		},
+		"anchored regex rewrite without leading slash and include prefix": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{
+					Pattern:      `^v1(.*)$`,
+					Substitution: `v2\1`,
+				},
+			},
+			includePrefix: "/api",
+			want: &RegexRewrite{
+				Pattern:      `^/api/v1(.*)$`,
+				Substitution: `/api/v2\1`,
+			},
+		},
+		"unanchored regex rewrite with include prefix": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{
+					Pattern:      `/old/`,
+					Substitution: `/new/`,
+				},
+			},
+			includePrefix: "/api",
+			want: &RegexRewrite{
+				Pattern:      `/old/`,
+				Substitution: `/new/`,
+			},
+		},
+		"anchored regex rewrite with root include prefix": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{
+					Pattern:      `^/v1/(.*)$`,
+					Substitution: `/v2/\1`,
+				},
+			},
+			includePrefix: "/",
+			want: &RegexRewrite{
+				Pattern:      `^/v1/(.*)$`,
+				Substitution: `/v2/\1`,
+			},
+		},
+		"replace full path": {
+			in: &contour_api_v1.PathRewritePolicy{
+				ReplaceFullPath: pointer.StringPtr(`/healthz`),
+			},
+			includePrefix: "/api",
+			want: &RegexRewrite{
+				Pattern:      `^.*$`,
+				Substitution: `/healthz`,
+			},
+		},
+		"replace full path escapes backslashes": {
+			in: &contour_api_v1.PathRewritePolicy{
+				ReplaceFullPath: pointer.StringPtr(`/a\1`),
+			},
+			want: &RegexRewrite{
+				Pattern:      `^.*$`,
+				Substitution: `/a\\1`,
+			},
+		},
+		"replace full path without leading slash": {
+			in: &contour_api_v1.PathRewritePolicy{
+				ReplaceFullPath: pointer.StringPtr(`healthz`),
+			},
+			wantErr: `replaceFullPath "healthz" must start with a leading slash`,
+		},
+		"multiple options": {
+			in: &contour_api_v1.PathRewritePolicy{
+				ReplacePrefix:   []contour_api_v1.ReplacePrefix{{Replacement: "/"}},
+				ReplaceFullPath: pointer.StringPtr(`/healthz`),
+			},
+			wantErr: "only one of replacePrefix, regexRewrite or replaceFullPath may be specified",
+		},
+		"empty pattern": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{},
+			},
+			wantErr: "regexRewrite pattern must not be empty",
+		},
+		"invalid pattern": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{
+					Pattern: `^/v[0-9+`,
+				},
+			},
+			wantErr: "invalid regexRewrite pattern \"^/v[0-9+\": error parsing regexp: missing closing ]: `[0-9+`",
+		},
+		"substitution references missing capture group": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{
+					Pattern:      `^/v1/(.*)$`,
+					Substitution: `/v2/\2`,
+				},
+			},
+			wantErr: `invalid regexRewrite substitution "/v2/\\2": capture group 2 does not exist`,
+		},
+		"substitution with invalid escape": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{
+					Pattern:      `^/v1/(.*)$`,
+					Substitution: `/v2/\n`,
+				},
+			},
+			wantErr: `invalid regexRewrite substitution "/v2/\\n": invalid escape sequence \n`,
+		},
+		"substitution with trailing backslash": {
+			in: &contour_api_v1.PathRewritePolicy{
+				RegexRewrite: &contour_api_v1.RegexRewrite{
+					Pattern:      `^/v1/(.*)$`,
+					Substitution: `/v2\`,
+				},
+			},
+			wantErr: `invalid regexRewrite substitution "/v2\\": must not end with a backslash`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := regexRewritePolicy(tc.in, tc.includePrefix)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCookieHashOptions(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.CookieHashOptions
+		want    *CookieHashOptions
+		wantErr string
+	}{
+		"nil input uses defaults": {
+			in: nil,
+			want: &CookieHashOptions{
+				CookieName: "X-Contour-Session-Affinity",
+				Path:       "/",
+			},
+		},
+		"empty options use defaults": {
+			in: &contour_api_v1.CookieHashOptions{},
+			want: &CookieHashOptions{
+				CookieName: "X-Contour-Session-Affinity",
+				Path:       "/",
+			},
+		},
+		"all options": {
+			in: &contour_api_v1.CookieHashOptions{
+				CookieName: "app_session",
+				TTL:        "30m",
+				Path:       "/app",
+			},
+			want: &CookieHashOptions{
+				CookieName: "app_session",
+				TTL:        30 * time.Minute,
+				Path:       "/app",
+			},
+		},
+		"invalid cookie name": {
+			in: &contour_api_v1.CookieHashOptions{
+				CookieName: "app session",
+			},
+			wantErr: `invalid cookie name "app session": must consist of RFC 7230 token characters`,
+		},
+		"invalid TTL": {
+			in: &contour_api_v1.CookieHashOptions{
+				TTL: "forever",
+			},
+			wantErr: `invalid cookie TTL "forever": time: invalid duration "forever"`,
+		},
+		"negative TTL": {
+			in: &contour_api_v1.CookieHashOptions{
+				TTL: "-1h",
+			},
+			wantErr: `cookie TTL "-1h" must not be negative`,
+		},
+		"path without leading slash": {
+			in: &contour_api_v1.CookieHashOptions{
+				Path: "app",
+			},
+			wantErr: `cookie path "app" must start with a leading slash`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := cookieHashOptions(tc.in)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadBalancerRequestHashPolicies(t *testing.T) {
+	tests := map[string]struct {
+		lbp          *contour_api_v1.LoadBalancerPolicy
+		want         []RequestHashPolicy
+		wantStrategy string
+		wantWarnings []string
+	}{
+		"nil policy": {
+			lbp: nil,
+		},
+		"random": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRandom,
+			},
+			wantStrategy: LoadBalancerPolicyRandom,
+		},
+		"header hash": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					Terminal: true,
+					HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+						HeaderName: "x-user-id",
+					},
+				}},
+			},
+			want: []RequestHashPolicy{{
+				Terminal: true,
+				HeaderHashOptions: &HeaderHashOptions{
+					HeaderName: "X-User-Id",
+				},
+			}},
+			wantStrategy: LoadBalancerPolicyRequestHash,
+		},
+		"duplicate header hash": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+						HeaderName: "x-user-id",
+					},
+				}, {
+					HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+						HeaderName: "X-User-Id",
+					},
+				}},
+			},
+			want: []RequestHashPolicy{{
+				HeaderHashOptions: &HeaderHashOptions{
+					HeaderName: "X-User-Id",
+				},
+			}},
+			wantStrategy: LoadBalancerPolicyRequestHash,
+			wantWarnings: []string{
+				"ignoring invalid header hash policy options with duplicated header name X-User-Id",
+			},
+		},
+		"cookie hash": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					CookieHashOptions: &contour_api_v1.CookieHashOptions{
+						CookieName: "app_session",
+					},
+				}},
+			},
+			want: []RequestHashPolicy{{
+				CookieHashOptions: &CookieHashOptions{
+					CookieName: "app_session",
+					Path:       "/",
+				},
+			}},
+			wantStrategy: LoadBalancerPolicyRequestHash,
+		},
+		"invalid cookie hash": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					CookieHashOptions: &contour_api_v1.CookieHashOptions{
+						CookieName: "app session",
+					},
+				}},
+			},
+			wantStrategy: LoadBalancerPolicyRoundRobin,
+			wantWarnings: []string{
+				`ignoring invalid cookie hash policy options: invalid cookie name "app session": must consist of RFC 7230 token characters`,
+				"ignoring invalid header hash policy options, setting load balancer strategy to default RoundRobin",
+			},
+		},
+		"source IP hash": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					Terminal:     true,
+					HashSourceIP: true,
+				}},
+			},
+			want: []RequestHashPolicy{{
+				Terminal:     true,
+				HashSourceIP: true,
+			}},
+			wantStrategy: LoadBalancerPolicyRequestHash,
+		},
+		"duplicate source IP hash": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					HashSourceIP: true,
+				}, {
+					Terminal:     true,
+					HashSourceIP: true,
+				}},
+			},
+			want: []RequestHashPolicy{{
+				HashSourceIP: true,
+			}},
+			wantStrategy: LoadBalancerPolicyRequestHash,
+			wantWarnings: []string{
+				"ignoring invalid duplicated source IP hash policy options",
+			},
+		},
+		"query parameter hash": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+						ParameterName: "id",
+					},
+				}},
+			},
+			want: []RequestHashPolicy{{
+				QueryParameterHashOptions: &QueryParameterHashOptions{
+					ParameterName: "id",
+				},
+			}},
+			wantStrategy: LoadBalancerPolicyRequestHash,
+		},
+		"query parameter hash without a name": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{},
+				}, {
+					HashSourceIP: true,
+				}},
+			},
+			want: []RequestHashPolicy{{
+				HashSourceIP: true,
+			}},
+			wantStrategy: LoadBalancerPolicyRequestHash,
+			wantWarnings: []string{
+				"ignoring invalid query parameter hash policy options with empty parameter name",
+			},
+		},
+		"duplicate query parameter hash": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+						ParameterName: "id",
+					},
+				}, {
+					QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+						ParameterName: "id",
+					},
+				}},
+			},
+			want: []RequestHashPolicy{{
+				QueryParameterHashOptions: &QueryParameterHashOptions{
+					ParameterName: "id",
+				},
+			}},
+			wantStrategy: LoadBalancerPolicyRequestHash,
+			wantWarnings: []string{
+				"ignoring invalid query parameter hash policy options with duplicated parameter name id",
+			},
+		},
+		"more than one hash option": {
+			lbp: &contour_api_v1.LoadBalancerPolicy{
+				Strategy: LoadBalancerPolicyRequestHash,
+				RequestHashPolicies: []contour_api_v1.RequestHashPolicy{{
+					HashSourceIP: true,
+					QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+						ParameterName: "id",
+					},
+				}},
+			},
+			wantStrategy: LoadBalancerPolicyRoundRobin,
+			wantWarnings: []string{
+				"ignoring invalid hash policy options with more than one hash option set",
+				"ignoring invalid header hash policy options, setting load balancer strategy to default RoundRobin",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			validCond := &contour_api_v1.DetailedCondition{}
+			got, gotStrategy := loadBalancerRequestHashPolicies(tc.lbp, validCond)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantStrategy, gotStrategy)
+
+			var gotWarnings []string
+			for _, w := range validCond.Warnings {
+				gotWarnings = append(gotWarnings, w.Message)
+			}
+			assert.Equal(t, tc.wantWarnings, gotWarnings)
+		})
+	}
+}
+
+func TestOutlierDetection(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.OutlierDetection
+		want    *OutlierDetection
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"empty policy": {
+			in:   &contour_api_v1.OutlierDetection{},
+			want: &OutlierDetection{},
+		},
+		"all fields": {
+			in: &contour_api_v1.OutlierDetection{
+				ConsecutiveServerErrors:  5,
+				ConsecutiveGatewayErrors: 3,
+				Interval:                 "10s",
+				BaseEjectionTime:         "30s",
+				MaxEjectionPercent:       50,
+			},
+			want: &OutlierDetection{
+				ConsecutiveServerErrors:  5,
+				ConsecutiveGatewayErrors: 3,
+				Interval:                 10 * time.Second,
+				BaseEjectionTime:         30 * time.Second,
+				MaxEjectionPercent:       50,
+			},
+		},
+		"negative consecutive server errors": {
+			in: &contour_api_v1.OutlierDetection{
+				ConsecutiveServerErrors: -1,
+			},
+			wantErr: "consecutive server errors -1 must not be negative",
+		},
+		"negative consecutive gateway errors": {
+			in: &contour_api_v1.OutlierDetection{
+				ConsecutiveGatewayErrors: -1,
+			},
+			wantErr: "consecutive gateway errors -1 must not be negative",
+		},
+		"max ejection percent out of range": {
+			in: &contour_api_v1.OutlierDetection{
+				MaxEjectionPercent: 101,
+			},
+			wantErr: "max ejection percent 101 must be in the range 0-100",
+		},
+		"invalid interval": {
+			in: &contour_api_v1.OutlierDetection{
+				Interval: "often",
+			},
+			wantErr: `invalid interval "often": time: invalid duration "often"`,
+		},
+		"negative base ejection time": {
+			in: &contour_api_v1.OutlierDetection{
+				BaseEjectionTime: "-5s",
+			},
+			wantErr: `base ejection time "-5s" must not be negative`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := outlierDetection(tc.in)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerPolicy(t *testing.T) {
+	annotated := &Service{
+		MaxConnections: 9000,
+		MaxRetries:     7,
+	}
+
+	tests := map[string]struct {
+		svc      *Service
+		in       *contour_api_v1.CircuitBreakerPolicy
+		defaults *CircuitBreakerPolicy
+		want     *CircuitBreakerPolicy
+		wantErr  string
+	}{
+		"no policy or defaults": {
+			svc:  annotated,
+			want: nil,
+		},
+		"defaults only": {
+			svc: &Service{},
+			defaults: &CircuitBreakerPolicy{
+				MaxConnections: 1024,
+				MaxRequests:    2048,
+			},
+			want: &CircuitBreakerPolicy{
+				MaxConnections: 1024,
+				MaxRequests:    2048,
+			},
+		},
+		"annotations override defaults": {
+			svc: annotated,
+			defaults: &CircuitBreakerPolicy{
+				MaxConnections: 1024,
+				MaxRequests:    2048,
+			},
+			want: &CircuitBreakerPolicy{
+				MaxConnections: 9000,
+				MaxRequests:    2048,
+				MaxRetries:     7,
+			},
+		},
+		"policy overrides annotations and defaults": {
+			svc: annotated,
+			in: &contour_api_v1.CircuitBreakerPolicy{
+				MaxConnections:     100,
+				MaxPendingRequests: 10,
+				MaxRequests:        50,
+			},
+			defaults: &CircuitBreakerPolicy{
+				MaxRequests: 2048,
+			},
+			want: &CircuitBreakerPolicy{
+				MaxConnections:     100,
+				MaxPendingRequests: 10,
+				MaxRequests:        50,
+				MaxRetries:         7,
+			},
+		},
+		"negative threshold": {
+			svc: annotated,
+			in: &contour_api_v1.CircuitBreakerPolicy{
+				MaxRetries: -1,
+			},
+			wantErr: "max retries -1 must be in the range 0-4294967295",
+		},
+		"threshold too large": {
+			svc: annotated,
+			in: &contour_api_v1.CircuitBreakerPolicy{
+				MaxPendingRequests: 1 << 32,
+			},
+			wantErr: "max pending requests 4294967296 must be in the range 0-4294967295",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := circuitBreakerPolicy(tc.svc, tc.in, tc.defaults)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestIPFilterPolicy(t *testing.T) {
+	tests := map[string]struct {
+		allow     []contour_api_v1.IPFilterPolicy
+		deny      []contour_api_v1.IPFilterPolicy
+		wantAllow bool
+		want      []IPFilterRule
+		wantErr   bool
+	}{
+		"no policies": {
+			want: nil,
+		},
+		"allow policy": {
+			allow: []contour_api_v1.IPFilterPolicy{{
+				Source: contour_api_v1.IPFilterSourceRemote,
+				CIDR:   "10.8.0.0/16",
+			}, {
+				Source: contour_api_v1.IPFilterSourcePeer,
+				CIDR:   "2001:db8::/32",
+			}},
+			wantAllow: true,
+			want: []IPFilterRule{{
+				Remote: true,
+				CIDR:   net.IPNet{IP: net.IP{10, 8, 0, 0}, Mask: net.CIDRMask(16, 32)},
+			}, {
+				Remote: false,
+				CIDR:   net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)},
+			}},
+		},
+		"deny policy with bare addresses": {
+			deny: []contour_api_v1.IPFilterPolicy{{
+				Source: contour_api_v1.IPFilterSourceRemote,
+				CIDR:   "192.168.1.1",
+			}, {
+				Source: contour_api_v1.IPFilterSourceRemote,
+				CIDR:   "2001:db8::1",
+			}},
+			wantAllow: false,
+			want: []IPFilterRule{{
+				Remote: true,
+				CIDR:   net.IPNet{IP: net.IP{192, 168, 1, 1}, Mask: net.CIDRMask(32, 32)},
+			}, {
+				Remote: true,
+				CIDR:   net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(128, 128)},
+			}},
+		},
+		"allow and deny policies": {
+			allow: []contour_api_v1.IPFilterPolicy{{
+				Source: contour_api_v1.IPFilterSourceRemote,
+				CIDR:   "10.8.0.0/16",
+			}},
+			deny: []contour_api_v1.IPFilterPolicy{{
+				Source: contour_api_v1.IPFilterSourceRemote,
+				CIDR:   "10.8.1.0/24",
+			}},
+			wantErr: true,
+		},
+		"invalid CIDR": {
+			allow: []contour_api_v1.IPFilterPolicy{{
+				Source: contour_api_v1.IPFilterSourceRemote,
+				CIDR:   "10.8.0.0/33",
+			}},
+			wantErr: true,
+		},
+		"invalid address": {
+			deny: []contour_api_v1.IPFilterPolicy{{
+				Source: contour_api_v1.IPFilterSourcePeer,
+				CIDR:   "vpn.example.com",
+			}},
+			wantErr: true,
+		},
+		"invalid source": {
+			allow: []contour_api_v1.IPFilterPolicy{{
+				Source: "Proxy",
+				CIDR:   "10.8.0.0/16",
+			}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			allow, got, err := ipFilterPolicy(tc.allow, tc.deny)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantAllow, allow)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestJWTProviders(t *testing.T) {
+	const jwks = `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`
+
+	tests := map[string]struct {
+		providers []contour_api_v1.JWTProvider
+		want      []JWTProvider
+		wantErr   string
+	}{
+		"no providers": {
+			want: nil,
+		},
+		"remote and local providers": {
+			providers: []contour_api_v1.JWTProvider{{
+				Name:       "remote",
+				Default:    true,
+				Issuer:     "https://issuer.example.com",
+				Audiences:  []string{"one"},
+				ForwardJWT: true,
+				RemoteJWKS: &contour_api_v1.RemoteJWKS{
+					URI:           "http://jwks.example.com:8080/keys.json",
+					Timeout:       "5s",
+					CacheDuration: "1h",
+				},
+				ClaimsToHeaders: []contour_api_v1.JWTClaimToHeader{{
+					Claim:  "sub",
+					Header: "x-jwt-sub",
+				}},
+			}, {
+				Name:      "local",
+				LocalJWKS: jwks,
+			}},
+			want: []JWTProvider{{
+				Name:       "remote",
+				Issuer:     "https://issuer.example.com",
+				Audiences:  []string{"one"},
+				ForwardJWT: true,
+				RemoteJWKS: &RemoteJWKS{
+					URI:           "http://jwks.example.com:8080/keys.json",
+					Timeout:       5 * time.Second,
+					CacheDuration: time.Hour,
+					Cluster: DNSNameCluster{
+						Address: "jwks.example.com",
+						Scheme:  "http",
+						Port:    8080,
+					},
+				},
+				ClaimsToHeaders: []JWTClaimToHeader{{
+					Claim:  "sub",
+					Header: "X-Jwt-Sub",
+				}},
+			}, {
+				Name:      "local",
+				LocalJWKS: jwks,
+			}},
+		},
+		"https remote JWKS with default port and timeout": {
+			providers: []contour_api_v1.JWTProvider{{
+				Name: "remote",
+				RemoteJWKS: &contour_api_v1.RemoteJWKS{
+					URI: "https://jwks.example.com/keys.json",
+				},
+			}},
+			want: []JWTProvider{{
+				Name: "remote",
+				RemoteJWKS: &RemoteJWKS{
+					URI:     "https://jwks.example.com/keys.json",
+					Timeout: time.Second,
+					Cluster: DNSNameCluster{
+						Address: "jwks.example.com",
+						Scheme:  "https",
+						Port:    443,
+					},
+				},
+			}},
+		},
+		"duplicate provider names": {
+			providers: []contour_api_v1.JWTProvider{
+				{Name: "provider", LocalJWKS: jwks},
+				{Name: "provider", LocalJWKS: jwks},
+			},
+			wantErr: `duplicate provider name "provider"`,
+		},
+		"multiple default providers": {
+			providers: []contour_api_v1.JWTProvider{
+				{Name: "one", Default: true, LocalJWKS: jwks},
+				{Name: "two", Default: true, LocalJWKS: jwks},
+			},
+			wantErr: `providers "one" and "two" are both marked as the default`,
+		},
+		"no JWKS": {
+			providers: []contour_api_v1.JWTProvider{{Name: "provider"}},
+			wantErr:   `provider "provider": must specify one of remoteJWKS or localJWKS`,
+		},
+		"remote and local JWKS": {
+			providers: []contour_api_v1.JWTProvider{{
+				Name:       "provider",
+				LocalJWKS:  jwks,
+				RemoteJWKS: &contour_api_v1.RemoteJWKS{URI: "https://jwks.example.com/keys.json"},
+			}},
+			wantErr: `provider "provider": cannot specify both remoteJWKS and localJWKS`,
+		},
+		"unsupported remote JWKS scheme": {
+			providers: []contour_api_v1.JWTProvider{{
+				Name:       "provider",
+				RemoteJWKS: &contour_api_v1.RemoteJWKS{URI: "ftp://jwks.example.com/keys.json"},
+			}},
+			wantErr: `provider "provider": remote JWKS URI "ftp://jwks.example.com/keys.json" must use the http or https scheme`,
+		},
+		"remote JWKS validation with http": {
+			providers: []contour_api_v1.JWTProvider{{
+				Name: "provider",
+				RemoteJWKS: &contour_api_v1.RemoteJWKS{
+					URI: "http://jwks.example.com/keys.json",
+					UpstreamValidation: &contour_api_v1.UpstreamValidation{
+						CACertificate: "ca",
+						SubjectName:   "jwks.example.com",
+					},
+				},
+			}},
+			wantErr: `provider "provider": remote JWKS validation may only be specified for https URIs`,
+		},
+		"invalid local JWKS": {
+			providers: []contour_api_v1.JWTProvider{{
+				Name:      "provider",
+				LocalJWKS: `{"keys":[]}`,
+			}},
+			wantErr: `provider "provider": local JWKS does not contain any keys`,
+		},
+		"invalid claim name": {
+			providers: []contour_api_v1.JWTProvider{{
+				Name:      "provider",
+				LocalJWKS: jwks,
+				ClaimsToHeaders: []contour_api_v1.JWTClaimToHeader{{
+					Claim:  `a"b`,
+					Header: "x-claim",
+				}},
+			}},
+			wantErr: `provider "provider": invalid claim name "a\"b"`,
+		},
+		"duplicate claim header": {
+			providers: []contour_api_v1.JWTProvider{{
+				Name:      "provider",
+				LocalJWKS: jwks,
+				ClaimsToHeaders: []contour_api_v1.JWTClaimToHeader{{
+					Claim:  "sub",
+					Header: "x-claim",
+				}, {
+					Claim:  "email",
+					Header: "X-Claim",
+				}},
+			}},
+			wantErr: `provider "provider": duplicate claim header "X-Claim"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := jwtProviders(tc.providers)
+			if len(tc.wantErr) > 0 {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestJWTVerificationProvider(t *testing.T) {
+	providers := []contour_api_v1.JWTProvider{
+		{Name: "one"},
+		{Name: "two", Default: true},
+	}
+
+	tests := map[string]struct {
+		policy    *contour_api_v1.JWTVerificationPolicy
+		providers []contour_api_v1.JWTProvider
+		want      string
+		wantErr   bool
+	}{
+		"no providers": {
+			want: "",
+		},
+		"no policy uses the default": {
+			providers: providers,
+			want:      "two",
+		},
+		"no policy and no default": {
+			providers: providers[:1],
+			want:      "",
+		},
+		"require overrides the default": {
+			policy:    &contour_api_v1.JWTVerificationPolicy{Require: "one"},
+			providers: providers,
+			want:      "one",
+		},
+		"disabled": {
+			policy:    &contour_api_v1.JWTVerificationPolicy{Disabled: true},
+			providers: providers,
+			want:      "",
+		},
+		"require and disabled": {
+			policy:    &contour_api_v1.JWTVerificationPolicy{Require: "one", Disabled: true},
+			providers: providers,
+			wantErr:   true,
+		},
+		"require undefined provider": {
+			policy:    &contour_api_v1.JWTVerificationPolicy{Require: "three"},
+			providers: providers,
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := jwtVerificationProvider(tc.policy, tc.providers)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
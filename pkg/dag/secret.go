@@ -16,17 +16,30 @@ package dag
 import (
 	"bytes"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
+	"golang.org/x/crypto/bcrypt"
 	v1 "k8s.io/api/core/v1"
 )
 
 // CACertificateKey is the key name for accessing TLS CA certificate bundles in Kubernetes Secrets.
 const CACertificateKey = "ca.crt"
 
+// CRLKey is the key name for accessing certificate revocation lists in Kubernetes Secrets.
+const CRLKey = "crl.pem"
+
+// BasicAuthKey is the key name for accessing htpasswd formatted basic
+// authentication credentials in Kubernetes Secrets.
+const BasicAuthKey = "auth"
+
+var bcryptHashRegex = regexp.MustCompile(`^\$2[aby]\$([0-9]{2})\$[./A-Za-z0-9]{53}$`)
+
 // isValidSecret returns true if the secret is interesting and well
 // formed. TLS certificate/key pairs must be secrets of type
 // "kubernetes.io/tls". Certificate bundles may be "kubernetes.io/tls"
@@ -53,7 +66,8 @@ func isValidSecret(secret *v1.Secret) (bool, error) {
 			return false, fmt.Errorf("invalid TLS private key: %v", err)
 		}
 
-	// Generic secrets may have a 'ca.crt' only.
+	// Generic secrets may have a 'ca.crt', a 'crl.pem' or basic
+	// authentication credentials only.
 	case v1.SecretTypeOpaque, "":
 		if _, ok := secret.Data[v1.TLSCertKey]; ok {
 			return false, nil
@@ -63,7 +77,7 @@ func isValidSecret(secret *v1.Secret) (bool, error) {
 			return false, nil
 		}
 
-		if data := secret.Data[CACertificateKey]; len(data) == 0 {
+		if len(secret.Data[CACertificateKey]) == 0 && len(secret.Data[CRLKey]) == 0 && len(secret.Data[BasicAuthKey]) == 0 {
 			return false, nil
 		}
 
@@ -82,6 +96,12 @@ func isValidSecret(secret *v1.Secret) (bool, error) {
 		}
 	}
 
+	if data := secret.Data[CRLKey]; len(data) > 0 {
+		if err := validateCRL(data); err != nil {
+			return false, fmt.Errorf("invalid CRL: %v", err)
+		}
+	}
+
 	return true, nil
 }
 
@@ -145,6 +165,34 @@ func validateCertificate(data []byte) error {
 	return nil
 }
 
+// validateCRL returns an error if data does not contain one or more
+// PEM encoded certificate revocation lists.
+func validateCRL(data []byte) error {
+	var exists bool
+
+	for containsPEMHeader(data) {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return errors.New("failed to parse PEM block")
+		}
+		if block.Type != "X509 CRL" {
+			return fmt.Errorf("unexpected block type '%s'", block.Type)
+		}
+		if _, err := x509.ParseDERCRL(block.Bytes); err != nil {
+			return err
+		}
+
+		exists = true
+	}
+
+	if !exists {
+		return errors.New("failed to locate CRL")
+	}
+
+	return nil
+}
+
 func hasCommonName(c *x509.Certificate) bool {
 	return strings.TrimSpace(c.Subject.CommonName) != ""
 }
@@ -194,3 +242,77 @@ func validatePrivateKey(data []byte) error {
 		return errors.New("multiple private keys")
 	}
 }
+
+// validBasicAuthSecret returns an error if the Secret does not
+// contain valid basic authentication credentials.
+func validBasicAuthSecret(s *v1.Secret) error {
+	if s.Type != v1.SecretTypeOpaque && s.Type != "" {
+		return fmt.Errorf("Secret type is not %q", v1.SecretTypeOpaque)
+	}
+
+	if len(s.Data[BasicAuthKey]) == 0 {
+		return fmt.Errorf("empty %q key", BasicAuthKey)
+	}
+
+	_, err := htpasswdUsers(s.Data[BasicAuthKey])
+	return err
+}
+
+// htpasswdUsers parses htpasswd formatted credentials into a map of
+// user names to password hashes. Only SHA1 and bcrypt password hashes
+// are supported.
+func htpasswdUsers(data []byte) (map[string]string, error) {
+	users := map[string]string{}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		user, hash := line, ""
+		if n := strings.IndexByte(line, ':'); n >= 0 {
+			user, hash = line[:n], line[n+1:]
+		}
+
+		if len(user) == 0 {
+			return nil, fmt.Errorf("line %d: missing user name", i+1)
+		}
+		if _, ok := users[user]; ok {
+			return nil, fmt.Errorf("line %d: duplicate user %q", i+1, user)
+		}
+		if err := validPasswordHash(hash); err != nil {
+			return nil, fmt.Errorf("line %d: user %q: %s", i+1, user, err)
+		}
+
+		users[user] = hash
+	}
+
+	if len(users) == 0 {
+		return nil, errors.New("no users defined")
+	}
+
+	return users, nil
+}
+
+func validPasswordHash(hash string) error {
+	if strings.HasPrefix(hash, "{SHA}") {
+		sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(hash, "{SHA}"))
+		if err != nil || len(sum) != 20 {
+			return errors.New("invalid SHA1 password hash")
+		}
+		return nil
+	}
+
+	m := bcryptHashRegex.FindStringSubmatch(hash)
+	if m == nil {
+		return errors.New("unsupported password hash, only bcrypt and SHA1 are supported")
+	}
+
+	cost, _ := strconv.Atoi(m[1])
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost %d is outside the supported range of %d to %d", cost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	return nil
+}
@@ -99,3 +99,161 @@ func caBundleData(cert ...string) map[string][]byte {
 		CACertificateKey: []byte(data),
 	}
 }
+
+func TestHtpasswdUsers(t *testing.T) {
+	const (
+		bcryptHash = "$2a$04$oy5cwhwvPXZpTV0.dRBbe.RCtOj3csGcPn/kyRHqpnFjoeYUYfc/u"
+		shaHash    = "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="
+	)
+
+	tests := map[string]struct {
+		data    string
+		want    map[string]string
+		wantErr error
+	}{
+		"bcrypt and SHA1 users": {
+			data: "# comment\nalice:" + bcryptHash + "\r\n\n  bob:" + shaHash + "\n",
+			want: map[string]string{
+				"alice": bcryptHash,
+				"bob":   shaHash,
+			},
+		},
+		"bcrypt 2y prefix": {
+			data: "alice:$2y" + bcryptHash[3:],
+			want: map[string]string{
+				"alice": "$2y" + bcryptHash[3:],
+			},
+		},
+		"bcrypt default cost": {
+			data: "alice:$2a$10" + bcryptHash[6:],
+			want: map[string]string{
+				"alice": "$2a$10" + bcryptHash[6:],
+			},
+		},
+		"no users": {
+			data:    "# nobody\n\n",
+			wantErr: errors.New("no users defined"),
+		},
+		"missing user name": {
+			data:    ":" + shaHash,
+			wantErr: errors.New("line 1: missing user name"),
+		},
+		"duplicate user": {
+			data:    "alice:" + shaHash + "\nalice:" + bcryptHash,
+			wantErr: errors.New(`line 2: duplicate user "alice"`),
+		},
+		"missing hash": {
+			data:    "alice",
+			wantErr: errors.New(`line 1: user "alice": unsupported password hash, only bcrypt and SHA1 are supported`),
+		},
+		"plain text password": {
+			data:    "alice:secret",
+			wantErr: errors.New(`line 1: user "alice": unsupported password hash, only bcrypt and SHA1 are supported`),
+		},
+		"apr1 hash": {
+			data:    "alice:$apr1$6qDuOKXO$0T5K7eL2HnDqQm6RxZ.MP/",
+			wantErr: errors.New(`line 1: user "alice": unsupported password hash, only bcrypt and SHA1 are supported`),
+		},
+		"truncated SHA1 hash": {
+			data:    "alice:{SHA}W6ph5Mm5Pz8GgiULbPgz",
+			wantErr: errors.New(`line 1: user "alice": invalid SHA1 password hash`),
+		},
+		"bcrypt cost too high": {
+			data:    "alice:$2a$32$lfkVTiLUoyGm7ThesNLNsuyS9KOBvSRGCJk616jaVjh5je.Aw0/jW",
+			wantErr: errors.New(`line 1: user "alice": bcrypt cost 32 is outside the supported range of 4 to 31`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := htpasswdUsers([]byte(tc.data))
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestValidBasicAuthSecret(t *testing.T) {
+	htpasswd := []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=")
+
+	tests := map[string]struct {
+		secret *v1.Secret
+		want   error
+	}{
+		"opaque secret": {
+			secret: &v1.Secret{
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{BasicAuthKey: htpasswd},
+			},
+			want: nil,
+		},
+		"TLS secret": {
+			secret: &v1.Secret{
+				Type: v1.SecretTypeTLS,
+				Data: map[string][]byte{BasicAuthKey: htpasswd},
+			},
+			want: errors.New(`Secret type is not "Opaque"`),
+		},
+		"missing key": {
+			secret: &v1.Secret{
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{"users": htpasswd},
+			},
+			want: errors.New(`empty "auth" key`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, validBasicAuthSecret(tc.secret))
+		})
+	}
+
+	// Generic secrets that only hold basic authentication
+	// credentials must be accepted by the cache.
+	valid, err := isValidSecret(tests["opaque secret"].secret)
+	assert.True(t, valid)
+	assert.NoError(t, err)
+}
+
+func TestValidateCRL(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want error
+	}{
+		"CRL": {
+			data: fixture.CRL,
+			want: nil,
+		},
+		"certificate": {
+			data: fixture.CERTIFICATE,
+			want: errors.New("unexpected block type 'CERTIFICATE'"),
+		},
+		"not PEM": {
+			data: "revoked",
+			want: errors.New("failed to locate CRL"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, validateCRL([]byte(tc.data)))
+		})
+	}
+
+	// Generic secrets that only hold a CRL must be accepted by
+	// the cache, and invalid CRLs rejected.
+	valid, err := isValidSecret(&v1.Secret{
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{CRLKey: []byte(fixture.CRL)},
+	})
+	assert.True(t, valid)
+	assert.NoError(t, err)
+
+	valid, err = isValidSecret(&v1.Secret{
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{CRLKey: []byte(fixture.CERTIFICATE)},
+	})
+	assert.False(t, valid)
+	assert.Error(t, err)
+}
@@ -1069,12 +1069,100 @@ func TestDAGStatus(t *testing.T) {
 		},
 	}
 
-	run(t, "proxy with two mirrors", testcase{
+	run(t, "proxy with duplicate mirrors", testcase{
 		objs: []interface{}{proxyInvalidTwoMirrors, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
 			{Name: proxyInvalidTwoMirrors.Name, Namespace: proxyInvalidTwoMirrors.Namespace}: fixture.NewValidCondition().
 				WithGeneration(proxyInvalidTwoMirrors.Generation).
-				WithError(contour_api_v1.ConditionTypeServiceError, "OnlyOneMirror", "only one service per route may be nominated as mirror"),
+				WithError(contour_api_v1.ConditionTypeServiceError, "DuplicateMirror", `service "kuard": port 8080 is nominated as mirror more than once`),
+		},
+	})
+
+	proxyInvalidMirrorPercentWithoutMirror := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name:          fixture.ServiceRootsKuard.Name,
+					Port:          8080,
+					MirrorPercent: 10,
+				}},
+			}},
+		},
+	}
+
+	run(t, "proxy with mirror percent on a service that is not a mirror", testcase{
+		objs: []interface{}{proxyInvalidMirrorPercentWithoutMirror, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidMirrorPercentWithoutMirror.Name, Namespace: proxyInvalidMirrorPercentWithoutMirror.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidMirrorPercentWithoutMirror.Generation).
+				WithError(contour_api_v1.ConditionTypeServiceError, "MirrorPercentInvalid", `service "kuard": mirror percent may only be set on a mirror service`),
+		},
+	})
+
+	proxyInvalidMirrorPercentOutOfRange := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}, {
+					Name:          fixture.ServiceRootsKuard.Name,
+					Port:          8080,
+					Mirror:        true,
+					MirrorPercent: 101,
+				}},
+			}},
+		},
+	}
+
+	run(t, "proxy with mirror percent out of range", testcase{
+		objs: []interface{}{proxyInvalidMirrorPercentOutOfRange, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidMirrorPercentOutOfRange.Name, Namespace: proxyInvalidMirrorPercentOutOfRange.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidMirrorPercentOutOfRange.Generation).
+				WithError(contour_api_v1.ConditionTypeServiceError, "MirrorPercentInvalid", `service "kuard": mirror percent must be in the range 0-100`),
+		},
+	})
+
+	proxyInvalidOnlyMirrors := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name:   fixture.ServiceRootsKuard.Name,
+					Port:   8080,
+					Mirror: true,
+				}},
+			}},
+		},
+	}
+
+	run(t, "proxy with only mirror services", testcase{
+		objs: []interface{}{proxyInvalidOnlyMirrors, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidOnlyMirrors.Name, Namespace: proxyInvalidOnlyMirrors.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidOnlyMirrors.Generation).
+				WithError(contour_api_v1.ConditionTypeServiceError, "MirrorWithoutServices", "route must have at least one service that is not a mirror"),
 		},
 	})
 
@@ -1378,462 +1466,443 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
-	proxyInvalidTCPProxyIncludeAndService := &contour_api_v1.HTTPProxy{
+	proxyInvalidRegexCondition := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "simple",
-			Namespace: "roots",
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "passthrough.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-				},
+				Fqdn: "example.com",
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{
-				Include: &contour_api_v1.TCPProxyInclude{
-					Name:      "foo",
-					Namespace: "roots",
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{
+					{
+						Regex: "/api/v[0-9+",
+					},
 				},
 				Services: []contour_api_v1.Service{{
 					Name: fixture.ServiceRootsKuard.Name,
 					Port: 8080,
 				}},
-			},
+			}},
 		},
 	}
 
-	run(t, "tcpproxy cannot specify services and include", testcase{
-		objs: []interface{}{proxyInvalidTCPProxyIncludeAndService, fixture.ServiceRootsKuard},
+	run(t, "proxy with invalid regex condition on route", testcase{
+		objs: []interface{}{proxyInvalidRegexCondition, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyInvalidTCPProxyIncludeAndService.Name, Namespace: proxyInvalidTCPProxyIncludeAndService.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTCPProxyError, "NoServicesAndInclude", "cannot specify services and include in the same httpproxy"),
+			{Name: proxyInvalidRegexCondition.Name, Namespace: proxyInvalidRegexCondition.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidRegexCondition.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "PathMatchConditionsNotValid", "route: invalid regex condition \"/api/v[0-9+\": error parsing regexp: missing closing ]: `[0-9+`"),
 		},
 	})
 
-	proxyTCPNoServiceOrInclusion := &contour_api_v1.HTTPProxy{
+	proxyInvalidIncludeExactCondition := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "simple",
-			Namespace: "roots",
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "passthrough.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-				},
+				Fqdn: "example.com",
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{},
+			Includes: []contour_api_v1.Include{{
+				Name:      "child",
+				Namespace: "teama",
+				Conditions: []contour_api_v1.MatchCondition{
+					{
+						Exact: "/api",
+					},
+				},
+			}},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
 		},
 	}
 
-	run(t, "tcpproxy empty", testcase{
-		objs: []interface{}{proxyTCPNoServiceOrInclusion, fixture.ServiceRootsKuard},
+	run(t, "proxy with exact condition on include orphans include", testcase{
+		objs: []interface{}{proxyInvalidIncludeExactCondition, proxyValidChildTeamA, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyTCPNoServiceOrInclusion.Name, Namespace: proxyTCPNoServiceOrInclusion.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTCPProxyError, "NothingDefined", "either services or inclusion must be specified"),
+			{Name: proxyInvalidIncludeExactCondition.Name, Namespace: proxyInvalidIncludeExactCondition.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidIncludeExactCondition.Generation).
+				WithError(contour_api_v1.ConditionTypeIncludeError, "PathMatchConditionsNotValid", "include: exact conditions are not allowed in includes"),
+			{Name: proxyValidChildTeamA.Name, Namespace: proxyValidChildTeamA.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyValidChildTeamA.Generation).
+				Orphaned(),
 		},
 	})
 
-	proxyTCPIncludesFoo := &contour_api_v1.HTTPProxy{
+	proxyInvalidHeaderRegexCondition := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "simple",
-			Namespace: "roots",
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "passthrough.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-				},
-			},
-			TCPProxy: &contour_api_v1.TCPProxy{
-				Include: &contour_api_v1.TCPProxyInclude{
-					Name:      "foo",
-					Namespace: fixture.ServiceRootsKuard.Namespace,
-				},
+				Fqdn: "example.com",
 			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:  "x-header",
+						Regex: "ba[rz",
+					},
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
 		},
 	}
 
-	run(t, "tcpproxy w/ missing include", testcase{
-		objs: []interface{}{proxyTCPIncludesFoo, fixture.ServiceRootsKuard},
+	run(t, "proxy with invalid regex header condition", testcase{
+		objs: []interface{}{proxyInvalidHeaderRegexCondition, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyTCPIncludesFoo.Name, Namespace: proxyTCPIncludesFoo.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTCPProxyIncludeError, "IncludeNotFound", "include roots/foo not found"),
+			{Name: proxyInvalidHeaderRegexCondition.Name, Namespace: proxyInvalidHeaderRegexCondition.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidHeaderRegexCondition.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "HeaderMatchConditionsNotValid", "invalid regex for header condition \"x-header\": error parsing regexp: missing closing ]: `[rz`"),
 		},
 	})
 
-	proxyValidTCPRoot := &contour_api_v1.HTTPProxy{
+	proxyInvalidQueryParamCondition := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "foo",
+			Name:      "www",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "www.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-				},
+				Fqdn: "example.com",
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					QueryParameter: &contour_api_v1.QueryParameterMatchCondition{
+						Name:    "api-version",
+						Exact:   "v1",
+						Present: true,
+					},
+				}},
 				Services: []contour_api_v1.Service{{
 					Name: fixture.ServiceRootsKuard.Name,
 					Port: 8080,
 				}},
-			},
+			}},
 		},
 	}
 
-	run(t, "tcpproxy includes another root", testcase{
-		objs: []interface{}{proxyTCPIncludesFoo, proxyValidTCPRoot, fixture.ServiceRootsKuard},
+	run(t, "proxy with query parameter condition specifying more than one match type", testcase{
+		objs: []interface{}{proxyInvalidQueryParamCondition, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyTCPIncludesFoo.Name, Namespace: proxyTCPIncludesFoo.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTCPProxyIncludeError, "RootIncludesRoot", "root httpproxy cannot include another root httpproxy"),
-			{Name: proxyValidTCPRoot.Name, Namespace: proxyValidTCPRoot.Namespace}: fixture.NewValidCondition().Valid(),
+			{Name: proxyInvalidQueryParamCondition.Name, Namespace: proxyInvalidQueryParamCondition.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidQueryParamCondition.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "QueryParamMatchConditionsNotValid", `query parameter condition for "api-version" must specify exactly one of exact, prefix, regex or present`),
 		},
 	})
 
-	proxyTCPValidChildFoo := &contour_api_v1.HTTPProxy{
+	proxyRequestRedirectAndServices := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "foo",
+			Name:      "www",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			TCPProxy: &contour_api_v1.TCPProxy{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
 				Services: []contour_api_v1.Service{{
 					Name: fixture.ServiceRootsKuard.Name,
 					Port: 8080,
 				}},
-			},
+				RequestRedirectPolicy: &contour_api_v1.HTTPRequestRedirectPolicy{
+					Hostname: pointer.StringPtr("envoyproxy.io"),
+				},
+			}},
 		},
 	}
 
-	run(t, "tcpproxy includes valid child", testcase{
-		objs: []interface{}{proxyTCPIncludesFoo, proxyTCPValidChildFoo, fixture.ServiceRootsKuard},
+	run(t, "proxy with request redirect policy and services", testcase{
+		objs: []interface{}{proxyRequestRedirectAndServices, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyTCPIncludesFoo.Name, Namespace: proxyTCPIncludesFoo.Namespace}:     fixture.NewValidCondition().Valid(),
-			{Name: proxyTCPValidChildFoo.Name, Namespace: proxyTCPValidChildFoo.Namespace}: fixture.NewValidCondition().Valid(),
+			{Name: proxyRequestRedirectAndServices.Name, Namespace: proxyRequestRedirectAndServices.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyRequestRedirectAndServices.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "RequestRedirectAndServices", "cannot specify services and requestRedirectPolicy in the same route"),
 		},
 	})
 
-	proxyInvalidConflictingIncludeConditions := &contour_api_v1.HTTPProxy{
+	proxyRequestRedirectPrefixWithRegex := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "roots",
-			Name:      "example",
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
-			Includes: []contour_api_v1.Include{{
-				Name:      "blogteama",
-				Namespace: "teama",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/blog",
-				}},
-			}, {
-				Name:      "blogteamb",
-				Namespace: "teamb",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/blog",
-				}},
-			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
-				Services: []contour_api_v1.Service{{
-					Name: "home",
-					Port: 8080,
+					Regex: "/v[0-9]+/.*",
 				}},
+				RequestRedirectPolicy: &contour_api_v1.HTTPRequestRedirectPolicy{
+					Prefix: pointer.StringPtr("/blog"),
+				},
 			}},
 		},
 	}
 
-	proxyValidBlogTeamA := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "blogteama",
-			Name:      "teama",
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/blog",
-				}},
-				Services: []contour_api_v1.Service{{
-					Name: fixture.ServiceTeamAKuard.Name,
-					Port: 8080,
-				}},
-			}},
+	run(t, "proxy with request redirect prefix on a regex route", testcase{
+		objs: []interface{}{proxyRequestRedirectPrefixWithRegex},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyRequestRedirectPrefixWithRegex.Name, Namespace: proxyRequestRedirectPrefixWithRegex.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyRequestRedirectPrefixWithRegex.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "RequestRedirectPolicyNotValid", "route.requestRedirectPolicy is invalid: cannot specify prefix on a route with a regex condition"),
 		},
-	}
+	})
 
-	proxyValidBlogTeamB := &contour_api_v1.HTTPProxy{
+	proxyDirectResponseAndServices := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "blogteamb",
-			Name:      "teamb",
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/blog",
-				}},
 				Services: []contour_api_v1.Service{{
-					Name: fixture.ServiceTeamBKuard.Name,
+					Name: fixture.ServiceRootsKuard.Name,
 					Port: 8080,
 				}},
+				DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+					StatusCode: 503,
+				},
 			}},
 		},
 	}
 
-	run(t, "duplicate path conditions on an include", testcase{
-		objs: []interface{}{proxyInvalidConflictingIncludeConditions, proxyValidBlogTeamA, proxyValidBlogTeamB, fixture.ServiceRootsHome, fixture.ServiceTeamAKuard, fixture.ServiceTeamBKuard},
+	run(t, "proxy with direct response policy and services", testcase{
+		objs: []interface{}{proxyDirectResponseAndServices, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyInvalidConflictingIncludeConditions.Name,
-				Namespace: proxyInvalidConflictingIncludeConditions.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeIncludeError, "DuplicateMatchConditions", "duplicate conditions defined on an include"),
-			{Name: proxyValidBlogTeamA.Name, Namespace: proxyValidBlogTeamA.Namespace}: fixture.NewValidCondition().
-				Orphaned(),
-			{Name: proxyValidBlogTeamB.Name, Namespace: proxyValidBlogTeamB.Namespace}: fixture.NewValidCondition().
-				Orphaned(),
+			{Name: proxyDirectResponseAndServices.Name, Namespace: proxyDirectResponseAndServices.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyDirectResponseAndServices.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponseAndServices", "cannot specify services and directResponsePolicy in the same route"),
 		},
 	})
 
-	proxyInvalidConflictHeaderConditions := &contour_api_v1.HTTPProxy{
+	proxyDirectResponseAndRequestRedirect := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "roots",
-			Name:      "example",
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
-			Includes: []contour_api_v1.Include{{
-				Name:      "blogteama",
-				Namespace: "teama",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Header: &contour_api_v1.HeaderMatchCondition{
-						Name:     "x-header",
-						Contains: "abc",
-					},
-				}},
-			}, {
-				Name:      "blogteamb",
-				Namespace: "teamb",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Header: &contour_api_v1.HeaderMatchCondition{
-						Name:     "x-header",
-						Contains: "abc",
-					},
-				}},
-			}},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
-				Services: []contour_api_v1.Service{{
-					Name: "home",
-					Port: 8080,
-				}},
+				RequestRedirectPolicy: &contour_api_v1.HTTPRequestRedirectPolicy{
+					Hostname: pointer.StringPtr("envoyproxy.io"),
+				},
+				DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+					StatusCode: 503,
+				},
 			}},
 		},
 	}
 
-	run(t, "duplicate header conditions on an include", testcase{
-		objs: []interface{}{proxyInvalidConflictHeaderConditions, proxyValidBlogTeamA, proxyValidBlogTeamB, fixture.ServiceRootsHome, fixture.ServiceTeamAKuard, fixture.ServiceTeamBKuard},
+	run(t, "proxy with direct response policy and request redirect policy", testcase{
+		objs: []interface{}{proxyDirectResponseAndRequestRedirect},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyInvalidConflictHeaderConditions.Name,
-				Namespace: proxyInvalidConflictHeaderConditions.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeIncludeError, "DuplicateMatchConditions", "duplicate conditions defined on an include"),
-			{Name: proxyValidBlogTeamA.Name,
-				Namespace: proxyValidBlogTeamA.Namespace}: fixture.NewValidCondition().Orphaned(),
-			{Name: proxyValidBlogTeamB.Name,
-				Namespace: proxyValidBlogTeamB.Namespace}: fixture.NewValidCondition().Orphaned(),
+			{Name: proxyDirectResponseAndRequestRedirect.Name, Namespace: proxyDirectResponseAndRequestRedirect.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyDirectResponseAndRequestRedirect.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponseAndRequestRedirect", "cannot specify requestRedirectPolicy and directResponsePolicy in the same route"),
 		},
 	})
 
-	proxyInvalidDuplicateHeaderAndPathConditions := &contour_api_v1.HTTPProxy{
+	proxyDirectResponseMissingConfigMap := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "roots",
-			Name:      "example",
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
-			Includes: []contour_api_v1.Include{{
-				Name:      "blogteama",
-				Namespace: "teama",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/blog",
-					Header: &contour_api_v1.HeaderMatchCondition{
-						Name:     "x-header",
-						Contains: "abc",
-					},
-				}},
-			}, {
-				Name:      "blogteamb",
-				Namespace: "teamb",
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/blog",
-					Header: &contour_api_v1.HeaderMatchCondition{
-						Name:     "x-header",
-						Contains: "abc",
-					},
-				}},
-			}},
 			Routes: []contour_api_v1.Route{{
-				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
-				}},
-				Services: []contour_api_v1.Service{{
-					Name: "home",
-					Port: 8080,
-				}},
+				DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+					StatusCode: 503,
+					BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+						Name: "maintenance",
+						Key:  "body",
+					},
+				},
 			}},
 		},
 	}
 
-	run(t, "duplicate header+path conditions on an include", testcase{
-		objs: []interface{}{proxyInvalidDuplicateHeaderAndPathConditions, proxyValidBlogTeamA, proxyValidBlogTeamB, fixture.ServiceRootsHome, fixture.ServiceTeamAKuard, fixture.ServiceTeamBKuard},
+	run(t, "proxy with direct response body from missing configmap", testcase{
+		objs: []interface{}{proxyDirectResponseMissingConfigMap},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyInvalidDuplicateHeaderAndPathConditions.Name,
-				Namespace: proxyInvalidDuplicateHeaderAndPathConditions.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeIncludeError, "DuplicateMatchConditions", "duplicate conditions defined on an include"),
-			{Name: proxyValidBlogTeamA.Name,
-				Namespace: proxyValidBlogTeamA.Namespace}: fixture.NewValidCondition().
-				Orphaned(),
-			{Name: proxyValidBlogTeamB.Name,
-				Namespace: proxyValidBlogTeamB.Namespace}: fixture.NewValidCondition().
-				Orphaned(),
+			{Name: proxyDirectResponseMissingConfigMap.Name, Namespace: proxyDirectResponseMissingConfigMap.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyDirectResponseMissingConfigMap.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid", `route.directResponsePolicy is invalid: unresolved ConfigMap "maintenance" key "body": ConfigMap not found`),
 		},
 	})
 
-	proxyInvalidMissingInclude := &contour_api_v1.HTTPProxy{
+	run(t, "proxy with direct response body from configmap with missing key", testcase{
+		objs: []interface{}{
+			proxyDirectResponseMissingConfigMap,
+			&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "maintenance",
+					Namespace: fixture.ServiceRootsKuard.Namespace,
+				},
+				Data: map[string]string{
+					"index.html": "down for maintenance",
+				},
+			},
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyDirectResponseMissingConfigMap.Name, Namespace: proxyDirectResponseMissingConfigMap.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyDirectResponseMissingConfigMap.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "DirectResponsePolicyNotValid", `route.directResponsePolicy is invalid: unresolved ConfigMap "maintenance" key "body": key "body" not found in ConfigMap`),
+		},
+	})
+
+	proxyInvalidRegexRewrite := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "roots",
-			Name:      "example",
+			Name:      "www",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
-			Includes: []contour_api_v1.Include{{
-				Name: "child",
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+				PathRewritePolicy: &contour_api_v1.PathRewritePolicy{
+					RegexRewrite: &contour_api_v1.RegexRewrite{
+						Pattern:      "^/v1/(.*)$",
+						Substitution: "/v2/\\2",
+					},
+				},
 			}},
 		},
 	}
 
-	run(t, "httpproxy w/ missing include", testcase{
-		objs: []interface{}{proxyInvalidMissingInclude, fixture.ServiceRootsKuard},
+	run(t, "proxy with invalid regex rewrite substitution", testcase{
+		objs: []interface{}{proxyInvalidRegexRewrite, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyInvalidMissingInclude.Name, Namespace: proxyInvalidMissingInclude.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeIncludeError, "IncludeNotFound", "include roots/child not found"),
+			{Name: proxyInvalidRegexRewrite.Name, Namespace: proxyInvalidRegexRewrite.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidRegexRewrite.Generation).
+				WithError(contour_api_v1.ConditionTypeRouteError, "PathRewritePolicyNotValid", `route.pathRewritePolicy is invalid: invalid regexRewrite substitution "/v2/\\2": capture group 2 does not exist`),
 		},
 	})
 
-	proxyTCPInvalidMissingService := &contour_api_v1.HTTPProxy{
+	proxyInvalidTCPProxyIncludeAndService := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "missing-tcp-proxy-service",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
+			Name:      "simple",
+			Namespace: "roots",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "tcpproxy.example.com",
+				Fqdn: "passthrough.example.com",
 				TLS: &contour_api_v1.TLS{
 					Passthrough: true,
 				},
 			},
 			TCPProxy: &contour_api_v1.TCPProxy{
+				Include: &contour_api_v1.TCPProxyInclude{
+					Name:      "foo",
+					Namespace: "roots",
+				},
 				Services: []contour_api_v1.Service{{
-					Name: "not-found",
+					Name: fixture.ServiceRootsKuard.Name,
 					Port: 8080,
 				}},
 			},
 		},
 	}
 
-	run(t, "httpproxy w/ tcpproxy w/ missing service", testcase{
-		objs: []interface{}{proxyTCPInvalidMissingService},
+	run(t, "tcpproxy cannot specify services and include", testcase{
+		objs: []interface{}{proxyInvalidTCPProxyIncludeAndService, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyTCPInvalidMissingService.Name, Namespace: proxyTCPInvalidMissingService.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTCPProxyError, "UnresolvedServiceRef", `Spec.TCPProxy unresolved service reference: service "roots/not-found" not found`),
+			{Name: proxyInvalidTCPProxyIncludeAndService.Name, Namespace: proxyInvalidTCPProxyIncludeAndService.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTCPProxyError, "NoServicesAndInclude", "cannot specify services and include in the same httpproxy"),
 		},
 	})
 
-	proxyTCPInvalidPortNotMatched := &contour_api_v1.HTTPProxy{
+	proxyTCPNoServiceOrInclusion := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "tcp-proxy-service-missing-port",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
+			Name:      "simple",
+			Namespace: "roots",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "tcpproxy.example.com",
+				Fqdn: "passthrough.example.com",
 				TLS: &contour_api_v1.TLS{
 					Passthrough: true,
 				},
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{
-				Services: []contour_api_v1.Service{{
-					Name: fixture.ServiceRootsKuard.Name,
-					Port: 9999,
-				}},
-			},
+			TCPProxy: &contour_api_v1.TCPProxy{},
 		},
 	}
 
-	run(t, "httpproxy w/ tcpproxy w/ service missing port", testcase{
-		objs: []interface{}{proxyTCPInvalidPortNotMatched, fixture.ServiceRootsKuard},
+	run(t, "tcpproxy empty", testcase{
+		objs: []interface{}{proxyTCPNoServiceOrInclusion, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyTCPInvalidPortNotMatched.Name, Namespace: proxyTCPInvalidPortNotMatched.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTCPProxyError, "UnresolvedServiceRef", `Spec.TCPProxy unresolved service reference: port "9999" on service "roots/kuard" not matched`),
+			{Name: proxyTCPNoServiceOrInclusion.Name, Namespace: proxyTCPNoServiceOrInclusion.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTCPProxyError, "NothingDefined", "either services or inclusion must be specified"),
 		},
 	})
 
-	proxyTCPInvalidMissingTLS := &contour_api_v1.HTTPProxy{
+	proxyTCPIncludesFoo := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "missing-tls",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
+			Name:      "simple",
+			Namespace: "roots",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "tcpproxy.example.com",
+				Fqdn: "passthrough.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: true,
+				},
 			},
 			TCPProxy: &contour_api_v1.TCPProxy{
-				Services: []contour_api_v1.Service{{
-					Name: fixture.ServiceRootsKuard.Name,
-					Port: 8080,
-				}},
+				Include: &contour_api_v1.TCPProxyInclude{
+					Name:      "foo",
+					Namespace: fixture.ServiceRootsKuard.Namespace,
+				},
 			},
 		},
 	}
 
-	run(t, "httpproxy w/ tcpproxy missing tls", testcase{
-		objs: []interface{}{proxyTCPInvalidMissingTLS},
+	run(t, "tcpproxy w/ missing include", testcase{
+		objs: []interface{}{proxyTCPIncludesFoo, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyTCPInvalidMissingTLS.Name, Namespace: proxyTCPInvalidMissingTLS.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTCPProxyError, "TLSMustBeConfigured", "Spec.TCPProxy requires that either Spec.TLS.Passthrough or Spec.TLS.SecretName be set"),
+			{Name: proxyTCPIncludesFoo.Name, Namespace: proxyTCPIncludesFoo.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTCPProxyIncludeError, "IncludeNotFound", "include roots/foo not found"),
 		},
 	})
 
-	proxyInvalidMissingServiceWithTCPProxy := &contour_api_v1.HTTPProxy{
+	proxyValidTCPRoot := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "missing-route-service",
+			Name:      "foo",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "tcpproxy.example.com",
+				Fqdn: "www.example.com",
 				TLS: &contour_api_v1.TLS{
-					SecretName: fixture.SecretRootsCert.Name,
+					Passthrough: true,
 				},
 			},
-			Routes: []contour_api_v1.Route{{
-				Services: []contour_api_v1.Service{
-					{Name: "missing", Port: 9000},
-				},
-			}},
 			TCPProxy: &contour_api_v1.TCPProxy{
 				Services: []contour_api_v1.Service{{
 					Name: fixture.ServiceRootsKuard.Name,
@@ -1843,31 +1912,21 @@ func TestDAGStatus(t *testing.T) {
 		},
 	}
 
-	run(t, "httpproxy w/ tcpproxy missing service", testcase{
-		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyInvalidMissingServiceWithTCPProxy},
+	run(t, "tcpproxy includes another root", testcase{
+		objs: []interface{}{proxyTCPIncludesFoo, proxyValidTCPRoot, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyInvalidMissingServiceWithTCPProxy.Name, Namespace: proxyInvalidMissingServiceWithTCPProxy.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeServiceError, "ServiceUnresolvedReference", `Spec.Routes unresolved service reference: service "roots/missing" not found`),
+			{Name: proxyTCPIncludesFoo.Name, Namespace: proxyTCPIncludesFoo.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTCPProxyIncludeError, "RootIncludesRoot", "root httpproxy cannot include another root httpproxy"),
+			{Name: proxyValidTCPRoot.Name, Namespace: proxyValidTCPRoot.Namespace}: fixture.NewValidCondition().Valid(),
 		},
 	})
 
-	proxyRoutePortNotMatchedWithTCP := &contour_api_v1.HTTPProxy{
+	proxyTCPValidChildFoo := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "missing-route-service-port",
+			Name:      "foo",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "tcpproxy.example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: fixture.SecretRootsCert.Name,
-				},
-			},
-			Routes: []contour_api_v1.Route{{
-				Services: []contour_api_v1.Service{
-					{Name: fixture.ServiceRootsKuard.Name, Port: 9999},
-				},
-			}},
 			TCPProxy: &contour_api_v1.TCPProxy{
 				Services: []contour_api_v1.Service{{
 					Name: fixture.ServiceRootsKuard.Name,
@@ -1877,119 +1936,98 @@ func TestDAGStatus(t *testing.T) {
 		},
 	}
 
-	run(t, "tcpproxy route unmatched service port", testcase{
-		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyRoutePortNotMatchedWithTCP},
+	run(t, "tcpproxy includes valid child", testcase{
+		objs: []interface{}{proxyTCPIncludesFoo, proxyTCPValidChildFoo, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyRoutePortNotMatchedWithTCP.Name, Namespace: proxyRoutePortNotMatchedWithTCP.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeServiceError, "ServiceUnresolvedReference", `Spec.Routes unresolved service reference: port "9999" on service "roots/kuard" not matched`),
+			{Name: proxyTCPIncludesFoo.Name, Namespace: proxyTCPIncludesFoo.Namespace}:     fixture.NewValidCondition().Valid(),
+			{Name: proxyTCPValidChildFoo.Name, Namespace: proxyTCPValidChildFoo.Namespace}: fixture.NewValidCondition().Valid(),
 		},
 	})
 
-	proxyTCPValidIncludeChild := &contour_api_v1.HTTPProxy{
+	proxyInvalidConflictingIncludeConditions := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "validtcpproxy",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
+			Namespace: "roots",
+			Name:      "example",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "tcpproxy.example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: fixture.SecretRootsCert.Name,
-				},
-			},
-			TCPProxy: &contour_api_v1.TCPProxy{
-				Include: &contour_api_v1.TCPProxyInclude{
-					Name:      "child",
-					Namespace: fixture.ServiceRootsKuard.Namespace,
-				},
-			},
-		},
-	}
-
-	proxyTCPValidIncludesChild := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "validtcpproxy",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "tcpproxy.example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName: fixture.SecretRootsCert.Name,
-				},
-			},
-			TCPProxy: &contour_api_v1.TCPProxy{
-				IncludesDeprecated: &contour_api_v1.TCPProxyInclude{
-					Name:      "child",
-					Namespace: fixture.ServiceRootsKuard.Namespace,
-				},
+				Fqdn: "example.com",
 			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "blogteama",
+				Namespace: "teama",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/blog",
+				}},
+			}, {
+				Name:      "blogteamb",
+				Namespace: "teamb",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/blog",
+				}},
+			}},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
 		},
 	}
 
-	proxyTCPValidChild := &contour_api_v1.HTTPProxy{
+	proxyValidBlogTeamA := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "child",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
+			Namespace: "blogteama",
+			Name:      "teama",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			TCPProxy: &contour_api_v1.TCPProxy{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/blog",
+				}},
 				Services: []contour_api_v1.Service{{
-					Name: fixture.ServiceRootsKuard.Name,
+					Name: fixture.ServiceTeamAKuard.Name,
 					Port: 8080,
 				}},
-			},
+			}},
 		},
 	}
 
-	run(t, "valid HTTPProxy.TCPProxy - plural", testcase{
-		objs: []interface{}{proxyTCPValidIncludesChild, proxyTCPValidChild, fixture.ServiceRootsKuard, fixture.SecretRootsCert},
-		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyTCPValidIncludesChild.Name,
-				Namespace: proxyTCPValidIncludesChild.Namespace}: fixture.NewValidCondition().Valid(),
-			{Name: proxyTCPValidChild.Name,
-				Namespace: proxyTCPValidChild.Namespace}: fixture.NewValidCondition().Valid(),
-		},
-	})
-
-	run(t, "valid HTTPProxy.TCPProxy", testcase{
-		objs: []interface{}{proxyTCPValidIncludeChild, proxyTCPValidChild, fixture.ServiceRootsKuard, fixture.SecretRootsCert},
-		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyTCPValidIncludeChild.Name,
-				Namespace: proxyTCPValidIncludeChild.Namespace}: fixture.NewValidCondition().Valid(),
-			{Name: proxyTCPValidChild.Name,
-				Namespace: proxyTCPValidChild.Namespace}: fixture.NewValidCondition().Valid(),
-		},
-	})
-
-	// issue 2309
-	proxyInvalidNoServices := &contour_api_v1.HTTPProxy{
+	proxyValidBlogTeamB := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "missing-service",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
+			Namespace: "blogteamb",
+			Name:      "teamb",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
-			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "missing-service.example.com",
-			},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/",
+					Prefix: "/blog",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceTeamBKuard.Name,
+					Port: 8080,
 				}},
-				Services: nil, // missing
 			}},
 		},
 	}
 
-	run(t, "invalid HTTPProxy due to empty route.service", testcase{
-		objs: []interface{}{proxyInvalidNoServices, fixture.ServiceRootsKuard},
+	run(t, "duplicate path conditions on an include", testcase{
+		objs: []interface{}{proxyInvalidConflictingIncludeConditions, proxyValidBlogTeamA, proxyValidBlogTeamB, fixture.ServiceRootsHome, fixture.ServiceTeamAKuard, fixture.ServiceTeamBKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: proxyInvalidNoServices.Name, Namespace: proxyInvalidNoServices.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeRouteError, "NoServicesPresent", "route.services must have at least one entry"),
+			{Name: proxyInvalidConflictingIncludeConditions.Name,
+				Namespace: proxyInvalidConflictingIncludeConditions.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIncludeError, "DuplicateMatchConditions", "duplicate conditions defined on an include"),
+			{Name: proxyValidBlogTeamA.Name, Namespace: proxyValidBlogTeamA.Namespace}: fixture.NewValidCondition().
+				Orphaned(),
+			{Name: proxyValidBlogTeamB.Name, Namespace: proxyValidBlogTeamB.Namespace}: fixture.NewValidCondition().
+				Orphaned(),
 		},
 	})
 
-	fallbackCertificate := &contour_api_v1.HTTPProxy{
+	proxyInvalidConflictHeaderConditions := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "roots",
 			Name:      "example",
@@ -1997,14 +2035,29 @@ func TestDAGStatus(t *testing.T) {
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName:                "ssl-cert",
-					EnableFallbackCertificate: true,
-				},
 			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "blogteama",
+				Namespace: "teama",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:     "x-header",
+						Contains: "abc",
+					},
+				}},
+			}, {
+				Name:      "blogteamb",
+				Namespace: "teamb",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:     "x-header",
+						Contains: "abc",
+					},
+				}},
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/foo",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
 					Name: "home",
@@ -2014,29 +2067,20 @@ func TestDAGStatus(t *testing.T) {
 		},
 	}
 
-	run(t, "invalid fallback certificate passed to contour", testcase{
-		fallbackCertificate: &types.NamespacedName{
-			Name:      "invalid",
-			Namespace: "invalid",
-		},
-		objs: []interface{}{fallbackCertificate, fixture.SecretRootsFallback, fixture.SecretRootsCert, fixture.ServiceRootsHome},
-		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: fallbackCertificate.Name,
-				Namespace: fallbackCertificate.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTLSError, "FallbackNotValid", `Spec.Virtualhost.TLS Secret "invalid/invalid" fallback certificate is invalid: Secret not found`),
-		},
-	})
-
-	run(t, "fallback certificate requested but cert not configured in contour", testcase{
-		objs: []interface{}{fallbackCertificate, fixture.SecretRootsFallback, fixture.SecretRootsCert, fixture.ServiceRootsHome},
+	run(t, "duplicate header conditions on an include", testcase{
+		objs: []interface{}{proxyInvalidConflictHeaderConditions, proxyValidBlogTeamA, proxyValidBlogTeamB, fixture.ServiceRootsHome, fixture.ServiceTeamAKuard, fixture.ServiceTeamBKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: fallbackCertificate.Name,
-				Namespace: fallbackCertificate.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTLSError, "FallbackNotPresent", "Spec.Virtualhost.TLS enabled fallback but the fallback Certificate Secret is not configured in Contour configuration file"),
+			{Name: proxyInvalidConflictHeaderConditions.Name,
+				Namespace: proxyInvalidConflictHeaderConditions.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIncludeError, "DuplicateMatchConditions", "duplicate conditions defined on an include"),
+			{Name: proxyValidBlogTeamA.Name,
+				Namespace: proxyValidBlogTeamA.Namespace}: fixture.NewValidCondition().Orphaned(),
+			{Name: proxyValidBlogTeamB.Name,
+				Namespace: proxyValidBlogTeamB.Namespace}: fixture.NewValidCondition().Orphaned(),
 		},
 	})
 
-	fallbackCertificateWithClientValidation := &contour_api_v1.HTTPProxy{
+	proxyInvalidDuplicateHeaderAndPathConditions := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "roots",
 			Name:      "example",
@@ -2044,17 +2088,31 @@ func TestDAGStatus(t *testing.T) {
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
-				TLS: &contour_api_v1.TLS{
-					SecretName:                "ssl-cert",
-					EnableFallbackCertificate: true,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						CACertificate: "something",
-					},
-				},
 			},
+			Includes: []contour_api_v1.Include{{
+				Name:      "blogteama",
+				Namespace: "teama",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/blog",
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:     "x-header",
+						Contains: "abc",
+					},
+				}},
+			}, {
+				Name:      "blogteamb",
+				Namespace: "teamb",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/blog",
+					Header: &contour_api_v1.HeaderMatchCondition{
+						Name:     "x-header",
+						Contains: "abc",
+					},
+				}},
+			}},
 			Routes: []contour_api_v1.Route{{
 				Conditions: []contour_api_v1.MatchCondition{{
-					Prefix: "/foo",
+					Prefix: "/",
 				}},
 				Services: []contour_api_v1.Service{{
 					Name: "home",
@@ -2064,45 +2122,47 @@ func TestDAGStatus(t *testing.T) {
 		},
 	}
 
-	run(t, "fallback certificate requested and clientValidation also configured", testcase{
-		objs: []interface{}{fallbackCertificateWithClientValidation, fixture.SecretRootsFallback, fixture.SecretRootsCert, fixture.ServiceRootsHome},
+	run(t, "duplicate header+path conditions on an include", testcase{
+		objs: []interface{}{proxyInvalidDuplicateHeaderAndPathConditions, proxyValidBlogTeamA, proxyValidBlogTeamB, fixture.ServiceRootsHome, fixture.ServiceTeamAKuard, fixture.ServiceTeamBKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: fallbackCertificateWithClientValidation.Name,
-				Namespace: fallbackCertificateWithClientValidation.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures", "Spec.Virtualhost.TLS fallback & client validation are incompatible"),
+			{Name: proxyInvalidDuplicateHeaderAndPathConditions.Name,
+				Namespace: proxyInvalidDuplicateHeaderAndPathConditions.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIncludeError, "DuplicateMatchConditions", "duplicate conditions defined on an include"),
+			{Name: proxyValidBlogTeamA.Name,
+				Namespace: proxyValidBlogTeamA.Namespace}: fixture.NewValidCondition().
+				Orphaned(),
+			{Name: proxyValidBlogTeamB.Name,
+				Namespace: proxyValidBlogTeamB.Namespace}: fixture.NewValidCondition().
+				Orphaned(),
 		},
 	})
 
-	tlsPassthroughAndValidation := &contour_api_v1.HTTPProxy{
+	proxyInvalidMissingInclude := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "invalid",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
+			Namespace: "roots",
+			Name:      "example",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
-				Fqdn: "tcpproxy.example.com",
-				TLS: &contour_api_v1.TLS{
-					Passthrough: true,
-					ClientValidation: &contour_api_v1.DownstreamValidation{
-						CACertificate: "aCAcert",
-					},
-				},
+				Fqdn: "example.com",
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{},
+			Includes: []contour_api_v1.Include{{
+				Name: "child",
+			}},
 		},
 	}
 
-	run(t, "passthrough and client auth are incompatible tlsPassthroughAndValidation", testcase{
-		objs: []interface{}{fixture.SecretRootsCert, tlsPassthroughAndValidation},
+	run(t, "httpproxy w/ missing include", testcase{
+		objs: []interface{}{proxyInvalidMissingInclude, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: tlsPassthroughAndValidation.Name, Namespace: tlsPassthroughAndValidation.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures", "Spec.VirtualHost.TLS passthrough cannot be combined with tls.clientValidation"),
+			{Name: proxyInvalidMissingInclude.Name, Namespace: proxyInvalidMissingInclude.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIncludeError, "IncludeNotFound", "include roots/child not found"),
 		},
 	})
 
-	tlsPassthroughAndSecretName := &contour_api_v1.HTTPProxy{
+	proxyTCPInvalidMissingService := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "invalid",
+			Name:      "missing-tcp-proxy-service",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
@@ -2110,148 +2170,1063 @@ func TestDAGStatus(t *testing.T) {
 				Fqdn: "tcpproxy.example.com",
 				TLS: &contour_api_v1.TLS{
 					Passthrough: true,
-					SecretName:  fixture.SecretRootsCert.Name,
 				},
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{
+					Name: "not-found",
+					Port: 8080,
+				}},
+			},
 		},
 	}
 
-	run(t, "tcpproxy with TLS passthrough and secret name both specified", testcase{
-		objs: []interface{}{
-			fixture.SecretRootsCert,
-			tlsPassthroughAndSecretName,
-		},
+	run(t, "httpproxy w/ tcpproxy w/ missing service", testcase{
+		objs: []interface{}{proxyTCPInvalidMissingService},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: both Passthrough and SecretName were specified"),
+			{Name: proxyTCPInvalidMissingService.Name, Namespace: proxyTCPInvalidMissingService.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTCPProxyError, "UnresolvedServiceRef", `Spec.TCPProxy unresolved service reference: service "roots/not-found" not found`),
 		},
 	})
 
-	tlsNoPassthroughOrSecretName := &contour_api_v1.HTTPProxy{
+	proxyTCPInvalidPortNotMatched := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "invalid",
+			Name:      "tcp-proxy-service-missing-port",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "tcpproxy.example.com",
 				TLS: &contour_api_v1.TLS{
-					Passthrough: false,
-					SecretName:  "",
+					Passthrough: true,
 				},
 			},
-			TCPProxy: &contour_api_v1.TCPProxy{},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 9999,
+				}},
+			},
 		},
 	}
 
-	run(t, "httpproxy w/ tcpproxy with neither TLS passthrough nor secret name specified", testcase{
-		objs: []interface{}{
-			fixture.SecretRootsCert,
-			tlsNoPassthroughOrSecretName,
+	run(t, "httpproxy w/ tcpproxy w/ service missing port", testcase{
+		objs: []interface{}{proxyTCPInvalidPortNotMatched, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyTCPInvalidPortNotMatched.Name, Namespace: proxyTCPInvalidPortNotMatched.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTCPProxyError, "UnresolvedServiceRef", `Spec.TCPProxy unresolved service reference: port "9999" on service "roots/kuard" not matched`),
+		},
+	})
+
+	proxyTCPInvalidMissingTLS := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "missing-tls",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			},
 		},
+	}
+
+	run(t, "httpproxy w/ tcpproxy missing tls", testcase{
+		objs: []interface{}{proxyTCPInvalidMissingTLS},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: neither Passthrough nor SecretName were specified"),
+			{Name: proxyTCPInvalidMissingTLS.Name, Namespace: proxyTCPInvalidMissingTLS.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTCPProxyError, "TLSMustBeConfigured", "Spec.TCPProxy requires that either Spec.TLS.Passthrough or Spec.TLS.SecretName be set"),
 		},
 	})
 
-	emptyProxy := &contour_api_v1.HTTPProxy{
+	proxyInvalidMissingServiceWithTCPProxy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "missing-route-service",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: fixture.SecretRootsCert.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{
+					{Name: "missing", Port: 9000},
+				},
+			}},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			},
+		},
+	}
+
+	run(t, "httpproxy w/ tcpproxy missing service", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyInvalidMissingServiceWithTCPProxy},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidMissingServiceWithTCPProxy.Name, Namespace: proxyInvalidMissingServiceWithTCPProxy.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "ServiceUnresolvedReference", `Spec.Routes unresolved service reference: service "roots/missing" not found`),
+		},
+	})
+
+	proxyRoutePortNotMatchedWithTCP := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "missing-route-service-port",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: fixture.SecretRootsCert.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{
+					{Name: fixture.ServiceRootsKuard.Name, Port: 9999},
+				},
+			}},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			},
+		},
+	}
+
+	run(t, "tcpproxy route unmatched service port", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyRoutePortNotMatchedWithTCP},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyRoutePortNotMatchedWithTCP.Name, Namespace: proxyRoutePortNotMatchedWithTCP.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "ServiceUnresolvedReference", `Spec.Routes unresolved service reference: port "9999" on service "roots/kuard" not matched`),
+		},
+	})
+
+	proxyTCPValidIncludeChild := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "validtcpproxy",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: fixture.SecretRootsCert.Name,
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Include: &contour_api_v1.TCPProxyInclude{
+					Name:      "child",
+					Namespace: fixture.ServiceRootsKuard.Namespace,
+				},
+			},
+		},
+	}
+
+	proxyTCPValidIncludesChild := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "validtcpproxy",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: fixture.SecretRootsCert.Name,
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				IncludesDeprecated: &contour_api_v1.TCPProxyInclude{
+					Name:      "child",
+					Namespace: fixture.ServiceRootsKuard.Namespace,
+				},
+			},
+		},
+	}
+
+	proxyTCPValidChild := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "child",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			},
+		},
+	}
+
+	run(t, "valid HTTPProxy.TCPProxy - plural", testcase{
+		objs: []interface{}{proxyTCPValidIncludesChild, proxyTCPValidChild, fixture.ServiceRootsKuard, fixture.SecretRootsCert},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyTCPValidIncludesChild.Name,
+				Namespace: proxyTCPValidIncludesChild.Namespace}: fixture.NewValidCondition().Valid(),
+			{Name: proxyTCPValidChild.Name,
+				Namespace: proxyTCPValidChild.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	run(t, "valid HTTPProxy.TCPProxy", testcase{
+		objs: []interface{}{proxyTCPValidIncludeChild, proxyTCPValidChild, fixture.ServiceRootsKuard, fixture.SecretRootsCert},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyTCPValidIncludeChild.Name,
+				Namespace: proxyTCPValidIncludeChild.Namespace}: fixture.NewValidCondition().Valid(),
+			{Name: proxyTCPValidChild.Name,
+				Namespace: proxyTCPValidChild.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	// issue 2309
+	proxyInvalidNoServices := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "missing-service",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "missing-service.example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: nil, // missing
+			}},
+		},
+	}
+
+	run(t, "invalid HTTPProxy due to empty route.service", testcase{
+		objs: []interface{}{proxyInvalidNoServices, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidNoServices.Name, Namespace: proxyInvalidNoServices.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "NoServicesPresent", "route.services must have at least one entry"),
+		},
+	})
+
+	fallbackCertificate := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "empty",
 			Namespace: "roots",
+			Name:      "example",
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName:                "ssl-cert",
+					EnableFallbackCertificate: true,
+				},
 			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
 		},
 	}
 
-	run(t, "proxy with no routes, includes, or tcpproxy is invalid", testcase{
-		objs: []interface{}{emptyProxy},
+	run(t, "invalid fallback certificate passed to contour", testcase{
+		fallbackCertificate: &types.NamespacedName{
+			Name:      "invalid",
+			Namespace: "invalid",
+		},
+		objs: []interface{}{fallbackCertificate, fixture.SecretRootsFallback, fixture.SecretRootsCert, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: fallbackCertificate.Name,
+				Namespace: fallbackCertificate.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "FallbackNotValid", `Spec.Virtualhost.TLS Secret "invalid/invalid" fallback certificate is invalid: Secret not found`),
+		},
+	})
+
+	run(t, "fallback certificate requested but cert not configured in contour", testcase{
+		objs: []interface{}{fallbackCertificate, fixture.SecretRootsFallback, fixture.SecretRootsCert, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: fallbackCertificate.Name,
+				Namespace: fallbackCertificate.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "FallbackNotPresent", "Spec.Virtualhost.TLS enabled fallback but the fallback Certificate Secret is not configured in Contour configuration file"),
+		},
+	})
+
+	fallbackCertificateWithClientValidation := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName:                "ssl-cert",
+					EnableFallbackCertificate: true,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate: "something",
+					},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "fallback certificate requested and clientValidation also configured", testcase{
+		objs: []interface{}{fallbackCertificateWithClientValidation, fixture.SecretRootsFallback, fixture.SecretRootsCert, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: fallbackCertificateWithClientValidation.Name,
+				Namespace: fallbackCertificateWithClientValidation.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures", "Spec.Virtualhost.TLS fallback & client validation are incompatible"),
+		},
+	})
+
+	tcpProxyWithClientSubjects := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "ssl-cert",
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate: "something",
+						Subjects:      []string{"CN=client"},
+					},
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			},
+		},
+	}
+
+	run(t, "tcpproxy with clientValidation subjects", testcase{
+		objs: []interface{}{tcpProxyWithClientSubjects, fixture.SecretRootsCert, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: tcpProxyWithClientSubjects.Name,
+				Namespace: tcpProxyWithClientSubjects.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures", "Spec.Virtualhost.TLS client validation subjects & forwardClientCertificate are incompatible with tcpproxy"),
+		},
+	})
+
+	tlsPassthroughAndValidation := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: true,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate: "aCAcert",
+					},
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{},
+		},
+	}
+
+	run(t, "passthrough and client auth are incompatible tlsPassthroughAndValidation", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, tlsPassthroughAndValidation},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: tlsPassthroughAndValidation.Name, Namespace: tlsPassthroughAndValidation.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures", "Spec.VirtualHost.TLS passthrough cannot be combined with tls.clientValidation"),
+		},
+	})
+
+	tlsPassthroughAndSecretName := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: true,
+					SecretName:  fixture.SecretRootsCert.Name,
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{},
+		},
+	}
+
+	run(t, "tcpproxy with TLS passthrough and secret name both specified", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			tlsPassthroughAndSecretName,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: both Passthrough and SecretName were specified"),
+		},
+	})
+
+	tlsNoPassthroughOrSecretName := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: false,
+					SecretName:  "",
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{},
+		},
+	}
+
+	run(t, "httpproxy w/ tcpproxy with neither TLS passthrough nor secret name specified", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			tlsNoPassthroughOrSecretName,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: neither Passthrough nor SecretName were specified"),
+		},
+	})
+
+	emptyProxy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "empty",
+			Namespace: "roots",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+		},
+	}
+
+	run(t, "proxy with no routes, includes, or tcpproxy is invalid", testcase{
+		objs: []interface{}{emptyProxy},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: emptyProxy.Name, Namespace: emptyProxy.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeSpecError, "NothingDefined", "HTTPProxy.Spec must have at least one Route, Include, or a TCPProxy"),
+		},
+	})
+
+	invalidRequestHeadersPolicyService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalidRHPService",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{
+					{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+						RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+							Set: []contour_api_v1.HeaderValue{{
+								Name:  "Host",
+								Value: "external.com",
+							}},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	run(t, "requestHeadersPolicy, Host header invalid on Service", testcase{
+		objs: []interface{}{invalidRequestHeadersPolicyService, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidRequestHeadersPolicyService.Name, Namespace: invalidRequestHeadersPolicyService.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "RequestHeadersPolicyInvalid", `rewriting "Host" header is not supported on request headers`),
+		},
+	})
+
+	invalidResponseHeadersPolicyService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalidRHPService",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{
+					{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+						ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+							Set: []contour_api_v1.HeaderValue{{
+								Name:  "Host",
+								Value: "external.com",
+							}},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	run(t, "responseHeadersPolicy, Host header invalid on Service", testcase{
+		objs: []interface{}{invalidResponseHeadersPolicyService, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidResponseHeadersPolicyService.Name, Namespace: invalidResponseHeadersPolicyService.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "ResponseHeadersPolicyInvalid", `rewriting "Host" header is not supported on response headers`),
+		},
+	})
+
+	invalidResponseHeadersPolicyRoute := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalidRHPRoute",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{
+					{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+					},
+				},
+				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "Host",
+						Value: "external.com",
+					}},
+				},
+			}},
+		},
+	}
+
+	run(t, "responseHeadersPolicy, Host header invalid on Route", testcase{
+		objs: []interface{}{invalidResponseHeadersPolicyRoute, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidResponseHeadersPolicyRoute.Name, Namespace: invalidResponseHeadersPolicyRoute.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "ResponseHeaderPolicyInvalid", `rewriting "Host" header is not supported on response headers`),
+		},
+	})
+
+	invalidRequestHeadersPolicyVariable := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalidRHPVariable",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{
+					{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+					},
+				},
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "X-Client-Address",
+						Value: "%DOWNSTREAM_CLIENT_ADDRESS%",
+					}},
+				},
+			}},
+		},
+	}
+
+	run(t, "requestHeadersPolicy, unknown header variable on Route", testcase{
+		objs: []interface{}{invalidRequestHeadersPolicyVariable, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidRequestHeadersPolicyVariable.Name, Namespace: invalidRequestHeadersPolicyVariable.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "RequestHeadersPolicyInvalid", `invalid set header "X-Client-Address": unknown header variable "%DOWNSTREAM_CLIENT_ADDRESS%" on request headers`),
+		},
+	})
+
+	invalidOutlierDetection := fixture.NewProxy("roots/invalid-outlier-detection").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+					OutlierDetection: &contour_api_v1.OutlierDetection{
+						MaxEjectionPercent: 110,
+					},
+				}},
+			}},
+		})
+
+	run(t, "outlierDetection, max ejection percent out of range", testcase{
+		objs: []interface{}{invalidOutlierDetection, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidOutlierDetection.Name, Namespace: invalidOutlierDetection.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "OutlierDetectionInvalid", `service "kuard": outlier detection is invalid: max ejection percent 110 must be in the range 0-100`),
+		},
+	})
+
+	invalidCircuitBreakerPolicy := fixture.NewProxy("roots/invalid-circuit-breaker-policy").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+					CircuitBreakerPolicy: &contour_api_v1.CircuitBreakerPolicy{
+						MaxConnections: -1,
+					},
+				}},
+			}},
+		})
+
+	run(t, "circuitBreakerPolicy, negative max connections", testcase{
+		objs: []interface{}{invalidCircuitBreakerPolicy, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidCircuitBreakerPolicy.Name, Namespace: invalidCircuitBreakerPolicy.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "CircuitBreakerPolicyInvalid", `service "kuard": circuit breaker policy is invalid: max connections -1 must be in the range 0-4294967295`),
+		},
+	})
+
+	invalidIPFilterVirtualHost := fixture.NewProxy("roots/invalid-ip-filter-vhost").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				IPAllowFilterPolicy: []contour_api_v1.IPFilterPolicy{{
+					Source: contour_api_v1.IPFilterSourceRemote,
+					CIDR:   "10.8.0.0/33",
+				}},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "ipAllowPolicy, invalid CIDR on virtual host", testcase{
+		objs: []interface{}{invalidIPFilterVirtualHost, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidIPFilterVirtualHost.Name, Namespace: invalidIPFilterVirtualHost.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIPFilterError, "PolicyDidNotParse", `Spec.VirtualHost.IPFilterPolicy: invalid CIDR "10.8.0.0/33"`),
+		},
+	})
+
+	invalidIPFilterRoute := fixture.NewProxy("roots/invalid-ip-filter-route").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				IPAllowFilterPolicy: []contour_api_v1.IPFilterPolicy{{
+					Source: contour_api_v1.IPFilterSourceRemote,
+					CIDR:   "10.8.0.0/16",
+				}},
+				IPDenyFilterPolicy: []contour_api_v1.IPFilterPolicy{{
+					Source: contour_api_v1.IPFilterSourceRemote,
+					CIDR:   "10.8.1.0/24",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "ipAllowPolicy and ipDenyPolicy on route", testcase{
+		objs: []interface{}{invalidIPFilterRoute, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidIPFilterRoute.Name, Namespace: invalidIPFilterRoute.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIPFilterError, "PolicyDidNotParse", `route.ipFilterPolicy is invalid: cannot specify both ipAllowPolicy and ipDenyPolicy`),
+		},
+	})
+
+	jwtProvidersWithoutTLS := fixture.NewProxy("roots/jwt-providers-without-tls").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				JWTProviders: []contour_api_v1.JWTProvider{{
+					Name:      "provider",
+					LocalJWKS: `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+				}},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "jwtProviders on a virtual host without TLS", testcase{
+		objs: []interface{}{jwtProvidersWithoutTLS, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: jwtProvidersWithoutTLS.Name, Namespace: jwtProvidersWithoutTLS.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTProvidersRequireTLS", "Spec.VirtualHost.JWTProviders can only be defined for root HTTPProxies that terminate TLS and route HTTP requests"),
+		},
+	})
+
+	invalidJWTProviders := fixture.NewProxy("roots/invalid-jwt-providers").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: fixture.SecretRootsCert.Name,
+				},
+				JWTProviders: []contour_api_v1.JWTProvider{{
+					Name: "provider",
+				}},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "jwtProviders without a JWKS", testcase{
+		objs: []interface{}{invalidJWTProviders, fixture.SecretRootsCert, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidJWTProviders.Name, Namespace: invalidJWTProviders.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTProvidersNotValid", `Spec.VirtualHost.JWTProviders is invalid: provider "provider": must specify one of remoteJWKS or localJWKS`),
+		},
+	})
+
+	invalidJWTVerificationPolicy := fixture.NewProxy("roots/invalid-jwt-verification-policy").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: fixture.SecretRootsCert.Name,
+				},
+				JWTProviders: []contour_api_v1.JWTProvider{{
+					Name:      "provider",
+					LocalJWKS: `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+				}},
+			},
+			Routes: []contour_api_v1.Route{{
+				JWTVerificationPolicy: &contour_api_v1.JWTVerificationPolicy{
+					Require:  "provider",
+					Disabled: true,
+				},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "jwtVerificationPolicy with require and disabled", testcase{
+		objs: []interface{}{invalidJWTVerificationPolicy, fixture.SecretRootsCert, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidJWTVerificationPolicy.Name, Namespace: invalidJWTVerificationPolicy.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationPolicyNotValid", "route.jwtVerificationPolicy is invalid: cannot specify both require and disabled"),
+		},
+	})
+
+	jwtVerificationPermitInsecure := fixture.NewProxy("roots/jwt-verification-permit-insecure").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: fixture.SecretRootsCert.Name,
+				},
+				JWTProviders: []contour_api_v1.JWTProvider{{
+					Name:      "provider",
+					Default:   true,
+					LocalJWKS: `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+				}},
+			},
+			Routes: []contour_api_v1.Route{{
+				PermitInsecure: true,
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "JWT verification on a route with permitInsecure", testcase{
+		objs: []interface{}{jwtVerificationPermitInsecure, fixture.SecretRootsCert, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: jwtVerificationPermitInsecure.Name, Namespace: jwtVerificationPermitInsecure.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationNotPermitted", "route.permitInsecure cannot be combined with JWT verification; disable route.jwtVerificationPolicy to serve the route over plain HTTP"),
+		},
+	})
+
+	basicAuthMissingSecret := fixture.NewProxy("roots/basic-auth-missing-secret").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "ssl-cert",
+				},
+				BasicAuth: &contour_api_v1.BasicAuthPolicy{
+					SecretName: "htpasswd",
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "basicAuth on a virtual host with a missing secret", testcase{
+		objs: []interface{}{basicAuthMissingSecret, fixture.SecretRootsCert, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: basicAuthMissingSecret.Name, Namespace: basicAuthMissingSecret.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthPolicyNotValid", `Spec.VirtualHost.BasicAuth is invalid: Secret "htpasswd" is invalid: Secret not found`),
+		},
+	})
+
+	basicAuthDisabledVhost := fixture.NewProxy("roots/basic-auth-disabled-vhost").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "ssl-cert",
+				},
+				BasicAuth: &contour_api_v1.BasicAuthPolicy{
+					Disabled: true,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "basicAuth disabled on a virtual host", testcase{
+		objs: []interface{}{basicAuthDisabledVhost, fixture.SecretRootsCert, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: basicAuthDisabledVhost.Name, Namespace: basicAuthDisabledVhost.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthPolicyNotValid", "Spec.VirtualHost.BasicAuth is invalid: disabled can only be specified on a route"),
+		},
+	})
+
+	htpasswdSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "htpasswd",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="),
+		},
+	}
+
+	basicAuthNotDelegated := fixture.NewProxy("roots/basic-auth-not-delegated").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "ssl-cert",
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				BasicAuth: &contour_api_v1.BasicAuthPolicy{
+					SecretName: "default/htpasswd",
+				},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "basicAuth on a route with an undelegated secret", testcase{
+		objs: []interface{}{basicAuthNotDelegated, htpasswdSecret, fixture.SecretRootsCert, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: basicAuthNotDelegated.Name, Namespace: basicAuthNotDelegated.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthPolicyNotValid", `route.basicAuth is invalid: Secret "default/htpasswd" delegation not permitted`),
+		},
+	})
+
+	basicAuthNoTLS := fixture.NewProxy("roots/basic-auth-no-tls").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				BasicAuth: &contour_api_v1.BasicAuthPolicy{
+					SecretName: "htpasswd",
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "basicAuth on a virtual host without TLS", testcase{
+		objs: []interface{}{basicAuthNoTLS, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: basicAuthNoTLS.Name, Namespace: basicAuthNoTLS.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthRequiresTLS", "Spec.VirtualHost.BasicAuth can only be defined for root HTTPProxies that terminate TLS and route HTTP requests"),
+		},
+	})
+
+	htpasswdRootsSecret := htpasswdSecret.DeepCopy()
+	htpasswdRootsSecret.Namespace = "roots"
+
+	basicAuthRouteNoTLS := fixture.NewProxy("roots/basic-auth-route-no-tls").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				BasicAuth: &contour_api_v1.BasicAuthPolicy{
+					SecretName: "htpasswd",
+				},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "basicAuth on a route without TLS", testcase{
+		objs: []interface{}{basicAuthRouteNoTLS, htpasswdRootsSecret, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: emptyProxy.Name, Namespace: emptyProxy.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeSpecError, "NothingDefined", "HTTPProxy.Spec must have at least one Route, Include, or a TCPProxy"),
+			{Name: basicAuthRouteNoTLS.Name, Namespace: basicAuthRouteNoTLS.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthRequiresTLS", "route.basicAuth is invalid: basic authentication can only be enabled on virtual hosts with TLS"),
 		},
 	})
 
-	invalidRequestHeadersPolicyService := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "invalidRHPService",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
+	basicAuthPermitInsecure := fixture.NewProxy("roots/basic-auth-permit-insecure").
+		WithSpec(contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "ssl-cert",
+				},
+				BasicAuth: &contour_api_v1.BasicAuthPolicy{
+					SecretName: "htpasswd",
+				},
 			},
 			Routes: []contour_api_v1.Route{{
-				Services: []contour_api_v1.Service{
-					{
-						Name: fixture.ServiceRootsKuard.Name,
-						Port: 8080,
-						RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
-							Set: []contour_api_v1.HeaderValue{{
-								Name:  "Host",
-								Value: "external.com",
-							}},
-						},
-					},
-				},
+				PermitInsecure: true,
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
 			}},
-		},
-	}
+		})
 
-	run(t, "requestHeadersPolicy, Host header invalid on Service", testcase{
-		objs: []interface{}{invalidRequestHeadersPolicyService, fixture.ServiceRootsKuard},
+	run(t, "basicAuth on a route that permits insecure requests", testcase{
+		objs: []interface{}{basicAuthPermitInsecure, htpasswdRootsSecret, fixture.SecretRootsCert, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: invalidRequestHeadersPolicyService.Name, Namespace: invalidRequestHeadersPolicyService.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeServiceError, "RequestHeadersPolicyInvalid", `rewriting "Host" header is not supported on request headers`),
+			{Name: basicAuthPermitInsecure.Name, Namespace: basicAuthPermitInsecure.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted", "route.permitInsecure cannot be combined with basic authentication; disable route.basicAuth to serve the route over plain HTTP"),
 		},
 	})
 
-	invalidResponseHeadersPolicyService := &contour_api_v1.HTTPProxy{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "invalidRHPService",
-			Namespace: fixture.ServiceRootsKuard.Namespace,
-		},
-		Spec: contour_api_v1.HTTPProxySpec{
+	invalidRetryBackoff := fixture.NewProxy("roots/invalid-retry-backoff").
+		WithSpec(contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
 			},
 			Routes: []contour_api_v1.Route{{
-				Services: []contour_api_v1.Service{
-					{
-						Name: fixture.ServiceRootsKuard.Name,
-						Port: 8080,
-						ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
-							Set: []contour_api_v1.HeaderValue{{
-								Name:  "Host",
-								Value: "external.com",
-							}},
-						},
+				RetryPolicy: &contour_api_v1.RetryPolicy{
+					RetryBackoff: &contour_api_v1.RetryBackoff{
+						BaseInterval: "1s",
+						MaxInterval:  "100ms",
 					},
 				},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
 			}},
+		})
+
+	run(t, "retryPolicy, backoff max interval less than base interval", testcase{
+		objs: []interface{}{invalidRetryBackoff, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidRetryBackoff.Name, Namespace: invalidRetryBackoff.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeRouteError, "RetryPolicyNotValid", `route.retryPolicy is invalid: retry backoff max interval "100ms" must not be less than the base interval "1s"`),
 		},
-	}
+	})
 
-	run(t, "responseHeadersPolicy, Host header invalid on Service", testcase{
-		objs: []interface{}{invalidResponseHeadersPolicyService, fixture.ServiceRootsKuard},
+	serviceInvalidCircuitBreakerAnnotation := fixture.NewService("roots/kuard").
+		Annotate("projectcontour.io/max-requests", "1e6").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)})
+
+	run(t, "circuit breaker annotation not a valid integer", testcase{
+		objs: []interface{}{fixture.NewProxy("roots/invalid-circuit-breaker-annotation").
+			WithSpec(contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: "example.com",
+				},
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{
+						Name: serviceInvalidCircuitBreakerAnnotation.Name,
+						Port: 8080,
+					}},
+				}},
+			}), serviceInvalidCircuitBreakerAnnotation},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: invalidResponseHeadersPolicyService.Name, Namespace: invalidResponseHeadersPolicyService.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeServiceError, "ResponseHeadersPolicyInvalid", `rewriting "Host" header is not supported on response headers`),
+			{Name: "invalid-circuit-breaker-annotation", Namespace: "roots"}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "CircuitBreakerPolicyInvalid", `service "kuard": circuit breaker policy is invalid: invalid annotation projectcontour.io/max-requests value "1e6": must be an integer in the range 0-4294967295`),
 		},
 	})
 
-	invalidResponseHeadersPolicyRoute := &contour_api_v1.HTTPProxy{
+	invalidResponseHeadersPolicyVirtualHost := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "invalidRHPRoute",
+			Name:      "invalidRHPVirtualHost",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "X-Frame-Options",
+						Value: "DENY",
+					}, {
+						Name:  "x-frame-options",
+						Value: "SAMEORIGIN",
+					}},
+				},
 			},
 			Routes: []contour_api_v1.Route{{
 				Services: []contour_api_v1.Service{
@@ -2260,21 +3235,15 @@ func TestDAGStatus(t *testing.T) {
 						Port: 8080,
 					},
 				},
-				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
-					Set: []contour_api_v1.HeaderValue{{
-						Name:  "Host",
-						Value: "external.com",
-					}},
-				},
 			}},
 		},
 	}
 
-	run(t, "responseHeadersPolicy, Host header invalid on Route", testcase{
-		objs: []interface{}{invalidResponseHeadersPolicyRoute, fixture.ServiceRootsKuard},
+	run(t, "responseHeadersPolicy, duplicate header invalid on VirtualHost", testcase{
+		objs: []interface{}{invalidResponseHeadersPolicyVirtualHost, fixture.ServiceRootsKuard},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: invalidResponseHeadersPolicyRoute.Name, Namespace: invalidResponseHeadersPolicyRoute.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeRouteError, "ResponseHeaderPolicyInvalid", `rewriting "Host" header is not supported on response headers`),
+			{Name: invalidResponseHeadersPolicyVirtualHost.Name, Namespace: invalidResponseHeadersPolicyVirtualHost.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ResponseHeadersPolicyInvalid", `Spec.VirtualHost.ResponseHeadersPolicy: duplicate header addition: "X-Frame-Options"`),
 		},
 	})
 
@@ -2306,6 +3275,99 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	routeAuthNoTLS := fixture.NewProxy("roots/route-auth-no-tls").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Authorization: &contour_api_v1.AuthorizationServer{
+					ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+						Namespace: "auth",
+						Name:      "extension",
+					},
+				},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "route authorization without TLS is invalid", testcase{
+		objs: []interface{}{routeAuthNoTLS, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: routeAuthNoTLS.Name, Namespace: routeAuthNoTLS.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeAuthError, "AuthNotPermitted", "route.authorization is invalid: authorization can only be enabled on virtual hosts with TLS"),
+		},
+	})
+
+	routeAuthPolicy := fixture.NewProxy("roots/route-auth-policy").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "ssl-cert",
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Authorization: &contour_api_v1.AuthorizationServer{
+					ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+						Namespace: "auth",
+						Name:      "extension",
+					},
+					AuthPolicy: &contour_api_v1.AuthorizationPolicy{
+						Context: map[string]string{"key": "value"},
+					},
+				},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "route authorization with an authPolicy is invalid", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, routeAuthPolicy, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: routeAuthPolicy.Name, Namespace: routeAuthPolicy.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeAuthError, "AuthPolicyNotValid", "route.authorization.authPolicy is not supported, use route.authPolicy instead"),
+		},
+	})
+
+	routeAuthDisabled := fixture.NewProxy("roots/route-auth-disabled").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "ssl-cert",
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Authorization: &contour_api_v1.AuthorizationServer{
+					ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+						Namespace: "auth",
+						Name:      "extension",
+					},
+				},
+				AuthPolicy: &contour_api_v1.AuthorizationPolicy{
+					Disabled: true,
+				},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		})
+
+	run(t, "route authorization with a disabled authPolicy is invalid", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, routeAuthDisabled, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: routeAuthDisabled.Name, Namespace: routeAuthDisabled.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeAuthError, "AuthPolicyNotValid", "route.authorization cannot be specified when route.authPolicy is disabled"),
+		},
+	})
+
 	invalidResponseTimeout := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: fixture.ServiceRootsKuard.Namespace,
@@ -2681,7 +3743,7 @@ func TestGatewayAPIDAGStatus(t *testing.T) {
 		}},
 	})
 
-	run(t, "RegularExpression header match not yet supported for httproute", testcase{
+	run(t, "ImplementationSpecific header match not yet supported for httproute", testcase{
 		objs: []interface{}{
 			gateway,
 			kuardService,
@@ -2704,7 +3766,7 @@ func TestGatewayAPIDAGStatus(t *testing.T) {
 								Value: "/",
 							},
 							Headers: &gatewayapi_v1alpha1.HTTPHeaderMatch{
-								Type:   "RegularExpression", // <---- RegularExpression type not yet supported
+								Type:   "ImplementationSpecific", // <---- ImplementationSpecific type not yet supported
 								Values: map[string]string{"foo": "bar"},
 							},
 						}},
@@ -2724,7 +3786,54 @@ func TestGatewayAPIDAGStatus(t *testing.T) {
 			Type:    string(status.ConditionNotImplemented),
 			Status:  contour_api_v1.ConditionTrue,
 			Reason:  string(status.ReasonHeaderMatchType),
-			Message: "HTTPRoute.Spec.Rules.HeaderMatch: Only Exact match type is supported.",
+			Message: "HTTPRoute.Spec.Rules.HeaderMatch: Only Exact and RegularExpression match types are supported.",
+		}},
+	})
+
+	run(t, "invalid RegularExpression header match for httproute", testcase{
+		objs: []interface{}{
+			gateway,
+			kuardService,
+			&gatewayapi_v1alpha1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "basic",
+					Namespace: "default",
+					Labels: map[string]string{
+						"app": "contour",
+					},
+				},
+				Spec: gatewayapi_v1alpha1.HTTPRouteSpec{
+					Hostnames: []gatewayapi_v1alpha1.Hostname{
+						"test.projectcontour.io",
+					},
+					Rules: []gatewayapi_v1alpha1.HTTPRouteRule{{
+						Matches: []gatewayapi_v1alpha1.HTTPRouteMatch{{
+							Path: gatewayapi_v1alpha1.HTTPPathMatch{
+								Type:  "Prefix",
+								Value: "/",
+							},
+							Headers: &gatewayapi_v1alpha1.HTTPHeaderMatch{
+								Type:   "RegularExpression",
+								Values: map[string]string{"foo": "ba[rz"},
+							},
+						}},
+						ForwardTo: []gatewayapi_v1alpha1.HTTPRouteForwardTo{{
+							ServiceName: pointer.StringPtr("kuard"),
+							Port:        gatewayPort(8080),
+						}},
+					}},
+				},
+			}},
+		want: []metav1.Condition{{
+			Type:    string(gatewayapi_v1alpha1.ConditionRouteAdmitted),
+			Status:  contour_api_v1.ConditionFalse,
+			Reason:  string(status.ReasonErrorsExist),
+			Message: "Errors found, check other Conditions for details.",
+		}, {
+			Type:    string(status.ConditionResolvedRefs),
+			Status:  contour_api_v1.ConditionFalse,
+			Reason:  string(status.ReasonDegraded),
+			Message: "HTTPRoute.Spec.Rules.HeaderMatch: invalid regex for header \"foo\": error parsing regexp: missing closing ]: `[rz`",
 		}},
 	})
 
@@ -3073,7 +4182,7 @@ func TestGatewayAPIDAGStatus(t *testing.T) {
 		}},
 	})
 
-	run(t, "HTTPRouteFilterRequestMirror not yet supported for httproute rule", testcase{
+	run(t, "HTTPRouteFilterExtensionRef not yet supported for httproute rule", testcase{
 		objs: []interface{}{
 			gateway,
 			kuardService,
@@ -3101,7 +4210,7 @@ func TestGatewayAPIDAGStatus(t *testing.T) {
 							Port:        gatewayPort(8080),
 						}},
 						Filters: []gatewayapi_v1alpha1.HTTPRouteFilter{{
-							Type: gatewayapi_v1alpha1.HTTPRouteFilterRequestMirror, // HTTPRouteFilterRequestMirror is not supported yet.
+							Type: gatewayapi_v1alpha1.HTTPRouteFilterExtensionRef, // HTTPRouteFilterExtensionRef is not supported yet.
 						}},
 					}},
 				},
@@ -3115,7 +4224,56 @@ func TestGatewayAPIDAGStatus(t *testing.T) {
 			Type:    string(status.ConditionNotImplemented),
 			Status:  contour_api_v1.ConditionTrue,
 			Reason:  string(status.ReasonHTTPRouteFilterType),
-			Message: "HTTPRoute.Spec.Rules.Filters: Only RequestHeaderModifier type is supported.",
+			Message: "HTTPRoute.Spec.Rules.Filters: Only RequestHeaderModifier and RequestMirror types are supported.",
+		}},
+	})
+
+	run(t, "HTTPRouteFilterRequestMirror for httproute rule without serviceName", testcase{
+		objs: []interface{}{
+			gateway,
+			kuardService,
+			&gatewayapi_v1alpha1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "basic",
+					Namespace: "default",
+					Labels: map[string]string{
+						"app": "contour",
+					},
+				},
+				Spec: gatewayapi_v1alpha1.HTTPRouteSpec{
+					Hostnames: []gatewayapi_v1alpha1.Hostname{
+						"test.projectcontour.io",
+					},
+					Rules: []gatewayapi_v1alpha1.HTTPRouteRule{{
+						Matches: []gatewayapi_v1alpha1.HTTPRouteMatch{{
+							Path: gatewayapi_v1alpha1.HTTPPathMatch{
+								Type:  "Prefix",
+								Value: "/",
+							},
+						}},
+						ForwardTo: []gatewayapi_v1alpha1.HTTPRouteForwardTo{{
+							ServiceName: pointer.StringPtr("kuard"),
+							Port:        gatewayPort(8080),
+						}},
+						Filters: []gatewayapi_v1alpha1.HTTPRouteFilter{{
+							Type: gatewayapi_v1alpha1.HTTPRouteFilterRequestMirror,
+							RequestMirror: &gatewayapi_v1alpha1.HTTPRequestMirrorFilter{
+								Port: gatewayPort(8080),
+							},
+						}},
+					}},
+				},
+			}},
+		want: []metav1.Condition{{
+			Type:    string(gatewayapi_v1alpha1.ConditionRouteAdmitted),
+			Status:  contour_api_v1.ConditionFalse,
+			Reason:  string(status.ReasonErrorsExist),
+			Message: "Errors found, check other Conditions for details.",
+		}, {
+			Type:    string(status.ConditionResolvedRefs),
+			Status:  contour_api_v1.ConditionFalse,
+			Reason:  string(status.ReasonDegraded),
+			Message: "Spec.Rules.Filters.RequestMirror.ServiceName must be specified.",
 		}},
 	})
 
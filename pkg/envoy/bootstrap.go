@@ -34,6 +34,10 @@ const SDSTLSCertificateFile = "xds-tls-certificate.json"
 // CA certificates for Envoy to use for the XDS gRPC connection.
 const SDSValidationContextFile = "xds-validation-context.json"
 
+// KubernetesNodeMetadataKey is the key of the Envoy node metadata
+// field that holds the name of the Kubernetes node Envoy runs on.
+const KubernetesNodeMetadataKey = "kubernetes_node_name"
+
 // BootstrapConfig holds configuration values for a Bootstrap configuration.
 type BootstrapConfig struct {
 	// AdminAccessLogPath is the path to write the access log for the administration server.
@@ -88,6 +92,10 @@ type BootstrapConfig struct {
 	// DNSLookupFamily specifies DNS Resolution Policy to use for Envoy -> Contour cluster name lookup.
 	// Either v4, v6 or auto.
 	DNSLookupFamily string
+
+	// NodeName is the name of the Kubernetes node Envoy runs on. Contour
+	// uses it to prioritize the endpoints that are closest to Envoy.
+	NodeName string
 }
 
 func (c *BootstrapConfig) GetXdsAddress() string { return stringOrDefault(c.XDSAddress, "127.0.0.1") }
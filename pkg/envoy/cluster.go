@@ -42,6 +42,15 @@ func Clustername(cluster *dag.Cluster) string {
 		}
 		buf += hc.Path
 	}
+	if od := cluster.OutlierDetection; od != nil {
+		buf += fmt.Sprintf("od:%d/%d/%s/%s/%d",
+			od.ConsecutiveServerErrors, od.ConsecutiveGatewayErrors,
+			od.Interval, od.BaseEjectionTime, od.MaxEjectionPercent)
+	}
+	if cb := cluster.CircuitBreakerPolicy; cb != nil {
+		buf += fmt.Sprintf("cb:%d/%d/%d/%d",
+			cb.MaxConnections, cb.MaxPendingRequests, cb.MaxRequests, cb.MaxRetries)
+	}
 	if uv := cluster.UpstreamValidation; uv != nil {
 		buf += uv.CACertificate.Object.ObjectMeta.Name
 		buf += uv.SubjectName
@@ -55,6 +64,21 @@ func Clustername(cluster *dag.Cluster) string {
 	return Hashname(60, ns, name, strconv.Itoa(int(service.Weighted.ServicePort.Port)), fmt.Sprintf("%x", hash[:5]))
 }
 
+// DNSNameClustername returns the name of the CDS cluster for this DNS name cluster.
+func DNSNameClustername(cluster *dag.DNSNameCluster) string {
+	buf := cluster.Scheme
+	if uv := cluster.UpstreamValidation; uv != nil {
+		buf += uv.CACertificate.Object.ObjectMeta.Namespace
+		buf += uv.CACertificate.Object.ObjectMeta.Name
+		buf += uv.SubjectName
+	}
+
+	// This isn't a crypto hash, we just want a unique name.
+	hash := sha1.Sum([]byte(buf)) // nolint:gosec
+
+	return Hashname(60, "dnsname", cluster.Address, strconv.Itoa(cluster.Port), fmt.Sprintf("%x", hash[:5]))
+}
+
 // AltStatName generates an alternative stat name for the service
 // using format ns_name_port
 func AltStatName(service *dag.Service) string {
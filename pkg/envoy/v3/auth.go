@@ -103,8 +103,30 @@ func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion envoy_v3_
 	if peerValidationContext.GetCACertificate() != nil {
 		vc := validationContext(peerValidationContext.GetCACertificate(), "")
 		if vc != nil {
+			if crl := peerValidationContext.GetCRL(); crl != nil {
+				vc.ValidationContext.Crl = &envoy_api_v3_core.DataSource{
+					Specifier: &envoy_api_v3_core.DataSource_InlineBytes{
+						InlineBytes: crl,
+					},
+				}
+			}
+
+			for _, san := range peerValidationContext.SubjectAltNames {
+				vc.ValidationContext.MatchSubjectAltNames = append(vc.ValidationContext.MatchSubjectAltNames,
+					&matcher.StringMatcher{
+						MatchPattern: &matcher.StringMatcher_Exact{
+							Exact: san,
+						},
+					})
+			}
+
 			context.CommonTlsContext.ValidationContextType = vc
-			context.RequireClientCertificate = protobuf.Bool(true)
+
+			// Clients that don't present a certificate are only
+			// accepted when client certificates are optional.
+			if !peerValidationContext.OptionalClientCertificate {
+				context.RequireClientCertificate = protobuf.Bool(true)
+			}
 		}
 	}
 
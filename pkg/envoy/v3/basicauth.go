@@ -0,0 +1,144 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"fmt"
+	"time"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	_struct "github.com/golang/protobuf/ptypes/struct"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+)
+
+// LuaFilterName is the name of the Lua HTTP filter. Envoy exposes
+// route metadata to Lua scripts from the namespace of this name.
+const LuaFilterName = "envoy.filters.http.lua"
+
+const (
+	// BasicAuthSecretMetadataKey is the key of the Secret that
+	// holds the basic authentication credentials of a route, in
+	// the Lua metadata namespace of the route and in the dynamic
+	// metadata of a request.
+	BasicAuthSecretMetadataKey = "basic_auth_secret"
+
+	// BasicAuthRealmMetadataKey is the key of the basic
+	// authentication realm of a route, in the Lua metadata
+	// namespace of the route and in the dynamic metadata of a
+	// request.
+	BasicAuthRealmMetadataKey = "basic_auth_realm"
+)
+
+// basicAuthTimeout is the time Envoy waits for Contour to verify
+// the credentials of a request. Verifying a bcrypt hash of a high
+// cost takes a significant fraction of a second.
+const basicAuthTimeout = 5 * time.Second
+
+// FilterBasicAuth returns the filters that require HTTP basic
+// authentication for routes that carry a basic authentication policy
+// in their metadata (see RouteBasicAuth). Requests to other routes
+// pass through the filters unchanged.
+//
+// A Lua filter stores the policy of the route in the dynamic metadata
+// of each request, and an `ext_authz` filter sends the requests that
+// have a policy to the basic authentication service of Contour, which
+// verifies their credentials.
+func FilterBasicAuth() []*http.HttpFilter {
+	authConfig := &envoy_config_filter_http_ext_authz_v3.ExtAuthz{
+		Services: &envoy_config_filter_http_ext_authz_v3.ExtAuthz_GrpcService{
+			GrpcService: &envoy_core_v3.GrpcService{
+				TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+					EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+						ClusterName: "contour",
+					},
+				},
+				Timeout: protobuf.Duration(basicAuthTimeout),
+			},
+		},
+		// Don't reuse the status of other authorization servers,
+		// which ExternalAuthzLocalReplyConfig may replace.
+		StatusOnError: &envoy_type.HttpStatus{
+			Code: envoy_type.StatusCode_ServiceUnavailable,
+		},
+		MetadataContextNamespaces: []string{LuaFilterName},
+		FilterEnabledMetadata: &matcher.MetadataMatcher{
+			Filter: LuaFilterName,
+			Path: []*matcher.MetadataMatcher_PathSegment{{
+				Segment: &matcher.MetadataMatcher_PathSegment_Key{
+					Key: BasicAuthSecretMetadataKey,
+				},
+			}},
+			Value: &matcher.ValueMatcher{
+				MatchPattern: &matcher.ValueMatcher_PresentMatch{
+					PresentMatch: true,
+				},
+			},
+		},
+		StatPrefix:          "basic_auth",
+		TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+	}
+
+	return []*http.HttpFilter{
+		{
+			Name: LuaFilterName,
+			ConfigType: &http.HttpFilter_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&lua.Lua{
+					InlineCode: fmt.Sprintf(basicAuthSelectorCode, LuaFilterName),
+				}),
+			},
+		},
+		filterExternalAuthz(authConfig),
+	}
+}
+
+// RouteBasicAuth returns the route metadata that enables the
+// FilterBasicAuth filters for the route. The credentials are not
+// part of the metadata; Contour looks them up by their Secret. It
+// returns nil if auth is nil.
+func RouteBasicAuth(auth *dag.BasicAuth) *envoy_core_v3.Metadata {
+	if auth == nil {
+		return nil
+	}
+
+	return &envoy_core_v3.Metadata{
+		FilterMetadata: map[string]*_struct.Struct{
+			LuaFilterName: {
+				Fields: map[string]*_struct.Value{
+					BasicAuthSecretMetadataKey: sv(auth.Secret.String()),
+					BasicAuthRealmMetadataKey:  sv(auth.Realm),
+				},
+			},
+		},
+	}
+}
+
+const basicAuthSelectorCode = `
+function envoy_on_request(request_handle)
+	local secret = request_handle:metadata():get("basic_auth_secret")
+	if secret ~= nil then
+		local metadata = request_handle:streamInfo():dynamicMetadata()
+		metadata:set(%[1]q, "basic_auth_secret", secret)
+		metadata:set(%[1]q, "basic_auth_realm", request_handle:metadata():get("basic_auth_realm"))
+	end
+end
+
+function envoy_on_response(response_handle)
+end
+`
@@ -0,0 +1,136 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	_struct "github.com/golang/protobuf/ptypes/struct"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestFilterBasicAuth(t *testing.T) {
+	want := []*http.HttpFilter{{
+		Name: "envoy.filters.http.lua",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&lua.Lua{
+				InlineCode: `
+function envoy_on_request(request_handle)
+	local secret = request_handle:metadata():get("basic_auth_secret")
+	if secret ~= nil then
+		local metadata = request_handle:streamInfo():dynamicMetadata()
+		metadata:set("envoy.filters.http.lua", "basic_auth_secret", secret)
+		metadata:set("envoy.filters.http.lua", "basic_auth_realm", request_handle:metadata():get("basic_auth_realm"))
+	end
+end
+
+function envoy_on_response(response_handle)
+end
+`,
+			}),
+		},
+	}, {
+		Name: "envoy.filters.http.ext_authz",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_http_ext_authz_v3.ExtAuthz{
+				Services: &envoy_config_filter_http_ext_authz_v3.ExtAuthz_GrpcService{
+					GrpcService: &envoy_core_v3.GrpcService{
+						TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+							EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+								ClusterName: "contour",
+							},
+						},
+						Timeout: protobuf.Duration(basicAuthTimeout),
+					},
+				},
+				StatusOnError: &envoy_type.HttpStatus{
+					Code: envoy_type.StatusCode_ServiceUnavailable,
+				},
+				MetadataContextNamespaces: []string{"envoy.filters.http.lua"},
+				FilterEnabledMetadata: &matcher.MetadataMatcher{
+					Filter: "envoy.filters.http.lua",
+					Path: []*matcher.MetadataMatcher_PathSegment{{
+						Segment: &matcher.MetadataMatcher_PathSegment_Key{
+							Key: "basic_auth_secret",
+						},
+					}},
+					Value: &matcher.ValueMatcher{
+						MatchPattern: &matcher.ValueMatcher_PresentMatch{
+							PresentMatch: true,
+						},
+					},
+				},
+				StatPrefix:          "basic_auth",
+				TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+			}),
+		},
+	}}
+
+	protobuf.ExpectEqual(t, want, FilterBasicAuth())
+}
+
+func TestRouteBasicAuth(t *testing.T) {
+	tests := map[string]struct {
+		auth *dag.BasicAuth
+		want *envoy_core_v3.Metadata
+	}{
+		"nil policy": {
+			auth: nil,
+			want: nil,
+		},
+		"policy with users": {
+			auth: &dag.BasicAuth{
+				Secret: types.NamespacedName{Namespace: "default", Name: "htpasswd"},
+				Realm:  "example.com",
+				Users: map[string]string{
+					"alice": "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=",
+				},
+			},
+			want: &envoy_core_v3.Metadata{
+				FilterMetadata: map[string]*_struct.Struct{
+					"envoy.filters.http.lua": {
+						Fields: map[string]*_struct.Value{
+							"basic_auth_secret": {
+								Kind: &_struct.Value_StringValue{StringValue: "default/htpasswd"},
+							},
+							"basic_auth_realm": {
+								Kind: &_struct.Value_StringValue{StringValue: "example.com"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := RouteBasicAuth(tc.auth)
+			if tc.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
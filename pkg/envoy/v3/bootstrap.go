@@ -33,6 +33,7 @@ import (
 	envoy_matcher_v3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/any"
+	_struct "github.com/golang/protobuf/ptypes/struct"
 	"github.com/projectcontour/contour/pkg/envoy"
 	"github.com/projectcontour/contour/pkg/protobuf"
 )
@@ -212,6 +213,25 @@ func bootstrapConfig(c *envoy.BootstrapConfig) *envoy_bootstrap_v3.Bootstrap {
 			AccessLogPath: c.GetAdminAccessLogPath(),
 			Address:       SocketAddress(c.GetAdminAddress(), c.GetAdminPort()),
 		},
+		Node: bootstrapNode(c),
+	}
+}
+
+// bootstrapNode returns the Envoy node, with the name of the Kubernetes
+// node that Envoy runs on in its metadata, or nil if that is unknown.
+func bootstrapNode(c *envoy.BootstrapConfig) *envoy_core_v3.Node {
+	if c.NodeName == "" {
+		return nil
+	}
+
+	return &envoy_core_v3.Node{
+		Metadata: &_struct.Struct{
+			Fields: map[string]*_struct.Value{
+				envoy.KubernetesNodeMetadataKey: {
+					Kind: &_struct.Value_StringValue{StringValue: c.NodeName},
+				},
+			},
+		},
 	}
 }
 
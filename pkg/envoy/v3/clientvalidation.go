@@ -0,0 +1,70 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"fmt"
+	"strings"
+
+	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+)
+
+// FilterClientCertificateSubjects returns a Lua filter that rejects
+// requests on connections whose client certificate subject is not
+// one of the subjects allowed by the peer validation context. Requests
+// on connections without a client certificate pass through the filter
+// unchanged. If no subjects are listed, FilterClientCertificateSubjects
+// returns nil.
+func FilterClientCertificateSubjects(pvc *dag.PeerValidationContext) *http.HttpFilter {
+	if pvc == nil || len(pvc.Subjects) == 0 {
+		return nil
+	}
+
+	var subjects strings.Builder
+	for _, subject := range pvc.Subjects {
+		fmt.Fprintf(&subjects, "\t[%q] = true,\n", subject)
+	}
+
+	return &http.HttpFilter{
+		Name: LuaFilterName,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&lua.Lua{
+				InlineCode: fmt.Sprintf(clientCertificateSubjectsCode, subjects.String()),
+			}),
+		},
+	}
+}
+
+// Envoy formats certificate subjects as RFC 2253 distinguished names.
+const clientCertificateSubjectsCode = `
+local subjects = {
+%s}
+
+function envoy_on_request(request_handle)
+	local ssl = request_handle:streamInfo():downstreamSslConnection()
+	if ssl == nil or not ssl:peerCertificatePresented() then
+		return
+	end
+
+	if not subjects[ssl:subjectPeerCertificate()] then
+		request_handle:respond({[":status"] = "403"}, "client certificate subject is not allowed")
+	end
+end
+
+function envoy_on_response(response_handle)
+end
+`
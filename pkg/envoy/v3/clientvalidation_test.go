@@ -0,0 +1,63 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterClientCertificateSubjects(t *testing.T) {
+	assert.Nil(t, FilterClientCertificateSubjects(nil))
+	assert.Nil(t, FilterClientCertificateSubjects(&dag.PeerValidationContext{}))
+
+	got := FilterClientCertificateSubjects(&dag.PeerValidationContext{
+		Subjects: []string{"CN=client,O=Example", `CN=quote\"d`},
+	})
+
+	want := &http.HttpFilter{
+		Name: "envoy.filters.http.lua",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&lua.Lua{
+				InlineCode: `
+local subjects = {
+	["CN=client,O=Example"] = true,
+	["CN=quote\\\"d"] = true,
+}
+
+function envoy_on_request(request_handle)
+	local ssl = request_handle:streamInfo():downstreamSslConnection()
+	if ssl == nil or not ssl:peerCertificatePresented() then
+		return
+	end
+
+	if not subjects[ssl:subjectPeerCertificate()] then
+		request_handle:respond({[":status"] = "403"}, "client certificate subject is not allowed")
+	end
+end
+
+function envoy_on_response(response_handle)
+end
+`,
+			}),
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, got)
+}
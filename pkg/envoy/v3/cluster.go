@@ -46,6 +46,7 @@ func Cluster(c *dag.Cluster) *envoy_cluster_v3.Cluster {
 	cluster.AltStatName = envoy.AltStatName(service)
 	cluster.LbPolicy = lbPolicy(c.LoadBalancerPolicy)
 	cluster.HealthChecks = edshealthcheck(c)
+	cluster.OutlierDetection = outlierDetection(c.OutlierDetection)
 	cluster.DnsLookupFamily = parseDNSLookupFamily(c.DNSLookupFamily)
 
 	switch len(service.ExternalName) {
@@ -64,17 +65,18 @@ func Cluster(c *dag.Cluster) *envoy_cluster_v3.Cluster {
 		cluster.IgnoreHealthOnHostRemoval = true
 	}
 
-	if envoy.AnyPositive(service.MaxConnections, service.MaxPendingRequests, service.MaxRequests, service.MaxRetries) {
-		cluster.CircuitBreakers = &envoy_cluster_v3.CircuitBreakers{
-			Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
-				MaxConnections:     protobuf.UInt32OrNil(service.MaxConnections),
-				MaxPendingRequests: protobuf.UInt32OrNil(service.MaxPendingRequests),
-				MaxRequests:        protobuf.UInt32OrNil(service.MaxRequests),
-				MaxRetries:         protobuf.UInt32OrNil(service.MaxRetries),
-			}},
-		}
+	cb := dag.CircuitBreakerPolicy{
+		MaxConnections:     service.MaxConnections,
+		MaxPendingRequests: service.MaxPendingRequests,
+		MaxRequests:        service.MaxRequests,
+		MaxRetries:         service.MaxRetries,
+	}
+	if c.CircuitBreakerPolicy != nil {
+		cb = *c.CircuitBreakerPolicy
 	}
 
+	cluster.CircuitBreakers = circuitBreakers(cb)
+
 	switch c.Protocol {
 	case "tls":
 		cluster.TransportSocket = UpstreamTLSTransportSocket(
@@ -128,6 +130,10 @@ func ExtensionCluster(ext *dag.ExtensionCluster) *envoy_cluster_v3.Cluster {
 
 	// TODO(jpeach): Externalname service support in https://github.com/projectcontour/contour/issues/2875
 
+	if ext.CircuitBreakerPolicy != nil {
+		cluster.CircuitBreakers = circuitBreakers(*ext.CircuitBreakerPolicy)
+	}
+
 	switch ext.Protocol {
 	case "h2":
 		cluster.TypedExtensionProtocolOptions = http2ProtocolOptions()
@@ -146,6 +152,40 @@ func ExtensionCluster(ext *dag.ExtensionCluster) *envoy_cluster_v3.Cluster {
 	return cluster
 }
 
+// circuitBreakers returns the circuit breaker thresholds for the
+// given policy, or nil if the policy selects the Envoy defaults.
+func circuitBreakers(cb dag.CircuitBreakerPolicy) *envoy_cluster_v3.CircuitBreakers {
+	if !envoy.AnyPositive(cb.MaxConnections, cb.MaxPendingRequests, cb.MaxRequests, cb.MaxRetries) {
+		return nil
+	}
+
+	return &envoy_cluster_v3.CircuitBreakers{
+		Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+			MaxConnections:     protobuf.UInt32OrNil(cb.MaxConnections),
+			MaxPendingRequests: protobuf.UInt32OrNil(cb.MaxPendingRequests),
+			MaxRequests:        protobuf.UInt32OrNil(cb.MaxRequests),
+			MaxRetries:         protobuf.UInt32OrNil(cb.MaxRetries),
+		}},
+	}
+}
+
+// DNSNameCluster builds a envoy_cluster_v3.Cluster for the given *dag.DNSNameCluster.
+func DNSNameCluster(c *dag.DNSNameCluster) *envoy_cluster_v3.Cluster {
+	cluster := clusterDefaults()
+
+	cluster.Name = envoy.DNSNameClustername(c)
+	cluster.ClusterDiscoveryType = ClusterDiscoveryTypeForAddress(c.Address, envoy_cluster_v3.Cluster_STRICT_DNS)
+	cluster.LoadAssignment = ClusterLoadAssignment(cluster.Name, SocketAddress(c.Address, c.Port))
+
+	if c.Scheme == "https" {
+		cluster.TransportSocket = UpstreamTLSTransportSocket(
+			UpstreamTLSContext(c.UpstreamValidation, c.Address, nil),
+		)
+	}
+
+	return cluster
+}
+
 // StaticClusterLoadAssignment creates a *envoy_endpoint_v3.ClusterLoadAssignment pointing to the external DNS address of the service
 func StaticClusterLoadAssignment(service *dag.Service) *envoy_endpoint_v3.ClusterLoadAssignment {
 	addr := SocketAddress(service.ExternalName, int(service.Weighted.ServicePort.Port))
@@ -197,6 +237,35 @@ func edshealthcheck(c *dag.Cluster) []*envoy_core_v3.HealthCheck {
 	}
 }
 
+// outlierDetection returns the Envoy outlier detection configuration
+// for the supplied policy. Unset fields select the Envoy defaults.
+func outlierDetection(od *dag.OutlierDetection) *envoy_cluster_v3.OutlierDetection {
+	if od == nil {
+		return nil
+	}
+
+	out := &envoy_cluster_v3.OutlierDetection{
+		Consecutive_5Xx:    protobuf.UInt32OrNil(od.ConsecutiveServerErrors),
+		MaxEjectionPercent: protobuf.UInt32OrNil(od.MaxEjectionPercent),
+	}
+
+	if od.Interval > 0 {
+		out.Interval = protobuf.Duration(od.Interval)
+	}
+	if od.BaseEjectionTime > 0 {
+		out.BaseEjectionTime = protobuf.Duration(od.BaseEjectionTime)
+	}
+
+	// Envoy does not enforce gateway failure ejection by default, so
+	// enable it whenever a threshold is given.
+	if od.ConsecutiveGatewayErrors > 0 {
+		out.ConsecutiveGatewayFailure = protobuf.UInt32(od.ConsecutiveGatewayErrors)
+		out.EnforcingConsecutiveGatewayFailure = protobuf.UInt32(100)
+	}
+
+	return out
+}
+
 // ClusterCommonLBConfig creates a *envoy_cluster_v3.Cluster_CommonLbConfig with HealthyPanicThreshold disabled.
 func ClusterCommonLBConfig() *envoy_cluster_v3.Cluster_CommonLbConfig {
 	return &envoy_cluster_v3.Cluster_CommonLbConfig{
@@ -489,6 +489,68 @@ func TestCluster(t *testing.T) {
 				}},
 			},
 		},
+		"tcp service with outlier detection": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1),
+				OutlierDetection: &dag.OutlierDetection{
+					ConsecutiveServerErrors:  5,
+					ConsecutiveGatewayErrors: 3,
+					Interval:                 10 * time.Second,
+					BaseEjectionTime:         30 * time.Second,
+					MaxEjectionPercent:       50,
+				},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/c412c849b1",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				OutlierDetection: &envoy_cluster_v3.OutlierDetection{
+					Consecutive_5Xx:                    protobuf.UInt32(5),
+					ConsecutiveGatewayFailure:          protobuf.UInt32(3),
+					EnforcingConsecutiveGatewayFailure: protobuf.UInt32(100),
+					Interval:                           protobuf.Duration(10 * time.Second),
+					BaseEjectionTime:                   protobuf.Duration(30 * time.Second),
+					MaxEjectionPercent:                 protobuf.UInt32(50),
+				},
+			},
+		},
+		"circuit breaker policy overrides service thresholds": {
+			cluster: &dag.Cluster{
+				Upstream: &dag.Service{
+					Weighted: dag.WeightedService{
+						Weight:           1,
+						ServiceName:      s1.Name,
+						ServiceNamespace: s1.Namespace,
+						ServicePort:      s1.Spec.Ports[0],
+					},
+					MaxConnections: 9000,
+					MaxRetries:     7,
+				},
+				CircuitBreakerPolicy: &dag.CircuitBreakerPolicy{
+					MaxConnections: 100,
+					MaxRequests:    50,
+				},
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/443/1e9f493e5b",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+					Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+						MaxConnections: protobuf.UInt32(100),
+						MaxRequests:    protobuf.UInt32(50),
+					}},
+				},
+			},
+		},
 		"use client certificate to authentication towards backend": {
 			cluster: &dag.Cluster{
 				Upstream:          service(s1, "tls"),
@@ -522,6 +584,52 @@ func TestCluster(t *testing.T) {
 	}
 }
 
+func TestDNSNameCluster(t *testing.T) {
+	tests := map[string]struct {
+		cluster *dag.DNSNameCluster
+		want    *envoy_cluster_v3.Cluster
+	}{
+		"http": {
+			cluster: &dag.DNSNameCluster{
+				Address: "jwks.example.com",
+				Scheme:  "http",
+				Port:    80,
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "dnsname/jwks.example.com/80/77b5f8e343",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_STRICT_DNS),
+				LoadAssignment:       ClusterLoadAssignment("dnsname/jwks.example.com/80/77b5f8e343", SocketAddress("jwks.example.com", 80)),
+			},
+		},
+		"https with an IP address": {
+			cluster: &dag.DNSNameCluster{
+				Address: "10.0.0.1",
+				Scheme:  "https",
+				Port:    8443,
+			},
+			want: &envoy_cluster_v3.Cluster{
+				Name:                 "dnsname/10.0.0.1/8443/c3437dbc7c",
+				ClusterDiscoveryType: ClusterDiscoveryType(envoy_cluster_v3.Cluster_STATIC),
+				LoadAssignment:       ClusterLoadAssignment("dnsname/10.0.0.1/8443/c3437dbc7c", SocketAddress("10.0.0.1", 8443)),
+				TransportSocket: UpstreamTLSTransportSocket(
+					UpstreamTLSContext(nil, "10.0.0.1", nil),
+				),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := DNSNameCluster(tc.cluster)
+			want := clusterDefaults()
+
+			proto.Merge(want, tc.want)
+
+			protobuf.ExpectEqual(t, want, got)
+		})
+	}
+}
+
 func TestClusterLoadAssignmentName(t *testing.T) {
 	assert.Equal(t, xds.ClusterLoadAssignmentName(types.NamespacedName{Namespace: "ns", Name: "svc"}, "port"), "ns/svc/port")
 	assert.Equal(t, xds.ClusterLoadAssignmentName(types.NamespacedName{Namespace: "ns", Name: "svc"}, ""), "ns/svc")
@@ -595,6 +703,50 @@ func TestClustername(t *testing.T) {
 			},
 			want: "default/backend/80/5c26077e1d",
 		},
+		"outlier detection": {
+			cluster: &dag.Cluster{
+				Upstream: &dag.Service{
+					Weighted: dag.WeightedService{
+						Weight:           1,
+						ServiceName:      "backend",
+						ServiceNamespace: "default",
+						ServicePort: v1.ServicePort{
+							Name:       "http",
+							Protocol:   "TCP",
+							Port:       80,
+							TargetPort: intstr.FromInt(6502),
+						},
+					},
+				},
+				OutlierDetection: &dag.OutlierDetection{
+					ConsecutiveServerErrors: 5,
+					Interval:                10 * time.Second,
+				},
+			},
+			want: "default/backend/80/cc4746b08a",
+		},
+		"circuit breaker policy": {
+			cluster: &dag.Cluster{
+				Upstream: &dag.Service{
+					Weighted: dag.WeightedService{
+						Weight:           1,
+						ServiceName:      "backend",
+						ServiceNamespace: "default",
+						ServicePort: v1.ServicePort{
+							Name:       "http",
+							Protocol:   "TCP",
+							Port:       80,
+							TargetPort: intstr.FromInt(6502),
+						},
+					},
+				},
+				CircuitBreakerPolicy: &dag.CircuitBreakerPolicy{
+					MaxConnections: 100,
+					MaxRetries:     5,
+				},
+			},
+			want: "default/backend/80/d02bf2e84a",
+		},
 		"upstream tls validation with subject alt name": {
 			cluster: &dag.Cluster{
 				Upstream: &dag.Service{
@@ -638,6 +790,28 @@ func TestClustername(t *testing.T) {
 	}
 }
 
+func TestDNSNameClustername(t *testing.T) {
+	cluster := &dag.DNSNameCluster{
+		Address: "jwks.example.com",
+		Scheme:  "https",
+		Port:    443,
+	}
+	assert.Equal(t, "dnsname/jwks.example.com/443/c3437dbc7c", envoy.DNSNameClustername(cluster))
+
+	cluster.UpstreamValidation = &dag.PeerValidationContext{
+		CACertificate: &dag.Secret{
+			Object: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "jwks-ca",
+					Namespace: "default",
+				},
+			},
+		},
+		SubjectName: "jwks.example.com",
+	}
+	assert.Equal(t, "dnsname/jwks.example.com/443/e7fdf8c442", envoy.DNSNameClustername(cluster))
+}
+
 func TestLBPolicy(t *testing.T) {
 	tests := map[string]envoy_cluster_v3.Cluster_LbPolicy{
 		"WeightedLeastRequest": envoy_cluster_v3.Cluster_LEAST_REQUEST,
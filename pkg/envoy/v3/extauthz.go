@@ -0,0 +1,372 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	_struct "github.com/golang/protobuf/ptypes/struct"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/envoy"
+	"github.com/projectcontour/contour/pkg/protobuf"
+)
+
+// authorizationServerMetadataKey is the key of the authorization
+// server in the Lua metadata namespace of a route, and in the dynamic
+// metadata of a request.
+const authorizationServerMetadataKey = "authorization_server"
+
+// FilterExternalAuthzServers returns the `ext_authz` filters for a
+// secure virtual host whose routes may override its authorization
+// server. If no route overrides the server of the virtual host, this
+// is just the filter for the virtual host (if any).
+//
+// Otherwise, a Lua filter stores the authorization server of the
+// route in the dynamic metadata of each request, and each server has
+// an `ext_authz` filter that is only enabled for the requests that
+// selected it. The per-route `ext_authz` configuration is shared by
+// all these filters.
+func FilterExternalAuthzServers(vhost *dag.ExternalAuthorization, routes []*dag.ExternalAuthorization) []*http.HttpFilter {
+	if len(routes) == 0 {
+		if vhost == nil {
+			return nil
+		}
+
+		return []*http.HttpFilter{FilterExternalAuthz(vhost)}
+	}
+
+	defaultServer, keys, servers := authorizationServers(vhost, routes)
+
+	filters := []*http.HttpFilter{
+		filterAuthorizationServerSelector(defaultServer),
+	}
+
+	for _, key := range keys {
+		ext := servers[key]
+
+		authConfig := externalAuthzConfig(ext)
+		authConfig.StatPrefix = strings.ReplaceAll(ext.AuthorizationService.Name, "/", "_")
+		authConfig.FilterEnabledMetadata = authorizationServerMatcher(key)
+
+		filters = append(filters, filterExternalAuthz(authConfig))
+	}
+
+	return filters
+}
+
+// externalAuthzErrorStatus is the status of the local replies that
+// authorization servers which set StatusOnDeny send when the check
+// request fails. Envoy sets the UAEX response flag on both denied and
+// failed check requests, so errors need a status of their own to
+// stop ExternalAuthzLocalReplyConfig replacing their status too. The
+// local reply configuration maps it back to 403 (Forbidden).
+const externalAuthzErrorStatus = envoy_type.StatusCode_NetworkAuthenticationRequired
+
+// externalAuthzRuntimeKey is the runtime key of the status code
+// filters of ExternalAuthzLocalReplyConfig. Envoy requires a runtime
+// key for status code comparisons; Contour does not set it, so the
+// filters always compare with their default value.
+const externalAuthzRuntimeKey = "contour.ext_authz.status"
+
+// ExternalAuthzLocalReplyConfig returns the local reply configuration
+// that replaces the status code of the requests that are denied by
+// authorization servers which set StatusOnDeny. The servers are those
+// of FilterExternalAuthzServers. It returns nil if no server sets
+// StatusOnDeny.
+//
+// Envoy denies requests with 403 (Forbidden) unless the authorization
+// server chose a status, so only denials with that status are
+// replaced. Envoy does not distinguish an explicit 403 from the
+// authorization server from its default, so both are replaced. The
+// replies to failed check requests keep their 403 status.
+func ExternalAuthzLocalReplyConfig(vhost *dag.ExternalAuthorization, routes []*dag.ExternalAuthorization) *http.LocalReplyConfig {
+	_, keys, servers := authorizationServers(vhost, routes)
+
+	var mappers []*http.ResponseMapper
+	for _, key := range keys {
+		ext := servers[key]
+		if ext.StatusOnDeny == 0 {
+			continue
+		}
+
+		mappers = append(mappers,
+			externalAuthzResponseMapper(key, len(routes) > 0, envoy_type.StatusCode_Forbidden, ext.StatusOnDeny),
+			externalAuthzResponseMapper(key, len(routes) > 0, externalAuthzErrorStatus, uint32(envoy_type.StatusCode_Forbidden)),
+		)
+	}
+
+	if len(mappers) == 0 {
+		return nil
+	}
+
+	return &http.LocalReplyConfig{
+		Mappers: mappers,
+	}
+}
+
+// externalAuthzResponseMapper returns a response mapper that replaces
+// the status of the local replies of the authorization server key.
+// If selected is true, requests select the authorization server by
+// metadata, because routes override the server of the virtual host.
+func externalAuthzResponseMapper(key string, selected bool, from envoy_type.StatusCode, to uint32) *http.ResponseMapper {
+	filters := []*accesslog.AccessLogFilter{{
+		FilterSpecifier: &accesslog.AccessLogFilter_ResponseFlagFilter{
+			ResponseFlagFilter: &accesslog.ResponseFlagFilter{
+				Flags: []string{"UAEX"},
+			},
+		},
+	}, {
+		FilterSpecifier: &accesslog.AccessLogFilter_StatusCodeFilter{
+			StatusCodeFilter: &accesslog.StatusCodeFilter{
+				Comparison: &accesslog.ComparisonFilter{
+					Op: accesslog.ComparisonFilter_EQ,
+					Value: &envoy_core_v3.RuntimeUInt32{
+						DefaultValue: uint32(from),
+						RuntimeKey:   externalAuthzRuntimeKey,
+					},
+				},
+			},
+		},
+	}}
+
+	if selected {
+		filters = append(filters, &accesslog.AccessLogFilter{
+			FilterSpecifier: &accesslog.AccessLogFilter_MetadataFilter{
+				MetadataFilter: &accesslog.MetadataFilter{
+					Matcher: authorizationServerMatcher(key),
+				},
+			},
+		})
+	}
+
+	return &http.ResponseMapper{
+		Filter: &accesslog.AccessLogFilter{
+			FilterSpecifier: &accesslog.AccessLogFilter_AndFilter{
+				AndFilter: &accesslog.AndFilter{
+					Filters: filters,
+				},
+			},
+		},
+		StatusCode: protobuf.UInt32(to),
+	}
+}
+
+// RouteExternalAuthorization adds the authorization server that
+// overrides the server of the virtual host to the route metadata
+// that is used by the filters of FilterExternalAuthzServers. It
+// returns md unchanged if ext is nil.
+func RouteExternalAuthorization(md *envoy_core_v3.Metadata, ext *dag.ExternalAuthorization) *envoy_core_v3.Metadata {
+	if ext == nil {
+		return md
+	}
+
+	if md == nil {
+		md = &envoy_core_v3.Metadata{}
+	}
+	if md.FilterMetadata == nil {
+		md.FilterMetadata = map[string]*_struct.Struct{}
+	}
+	if md.FilterMetadata[LuaFilterName] == nil {
+		md.FilterMetadata[LuaFilterName] = &_struct.Struct{}
+	}
+	if md.FilterMetadata[LuaFilterName].Fields == nil {
+		md.FilterMetadata[LuaFilterName].Fields = map[string]*_struct.Value{}
+	}
+
+	md.FilterMetadata[LuaFilterName].Fields[authorizationServerMetadataKey] = sv(authorizationServerKey(ext))
+
+	return md
+}
+
+// externalAuthzHTTPService returns the `ext_authz` service configuration
+// for an authorization server that is sent HTTP requests.
+func externalAuthzHTTPService(ext *dag.ExternalAuthorization) *envoy_config_filter_http_ext_authz_v3.ExtAuthz_HttpService {
+	// The HTTP service requires a timeout, so use the
+	// default timeout of the gRPC service.
+	responseTimeout := protobuf.Duration(200 * time.Millisecond)
+	if !ext.AuthorizationResponseTimeout.UseDefault() {
+		responseTimeout = envoy.Timeout(ext.AuthorizationResponseTimeout)
+	}
+
+	svc := &envoy_config_filter_http_ext_authz_v3.HttpService{
+		ServerUri: &envoy_core_v3.HttpUri{
+			// The URI is required, but Envoy sends authorization
+			// requests to the cluster with the client request
+			// host, so it is only informational.
+			Uri: "http://" + ext.AuthorizationService.Name,
+			HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{
+				Cluster: ext.AuthorizationService.Name,
+			},
+			Timeout: responseTimeout,
+		},
+		PathPrefix: ext.HTTPService.PathPrefix,
+	}
+
+	if len(ext.AllowedRequestHeaders) > 0 {
+		svc.AuthorizationRequest = &envoy_config_filter_http_ext_authz_v3.AuthorizationRequest{
+			AllowedHeaders: headerListMatcher(ext.AllowedRequestHeaders),
+		}
+	}
+
+	if len(ext.AllowedUpstreamHeaders) > 0 || len(ext.AllowedClientHeaders) > 0 {
+		svc.AuthorizationResponse = &envoy_config_filter_http_ext_authz_v3.AuthorizationResponse{
+			AllowedUpstreamHeaders: headerListMatcher(ext.AllowedUpstreamHeaders),
+			AllowedClientHeaders:   headerListMatcher(ext.AllowedClientHeaders),
+		}
+	}
+
+	return &envoy_config_filter_http_ext_authz_v3.ExtAuthz_HttpService{
+		HttpService: svc,
+	}
+}
+
+// headerListMatcher returns a matcher for the given header names,
+// or nil if there are none.
+func headerListMatcher(headers []string) *matcher.ListStringMatcher {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	list := &matcher.ListStringMatcher{}
+	for _, header := range headers {
+		list.Patterns = append(list.Patterns, &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Exact{
+				Exact: header,
+			},
+			IgnoreCase: true,
+		})
+	}
+
+	return list
+}
+
+// authorizationServers returns the distinct authorization servers of
+// a virtual host and its routes, indexed by their sorted keys, along
+// with the key of the virtual host server.
+func authorizationServers(vhost *dag.ExternalAuthorization, routes []*dag.ExternalAuthorization) (string, []string, map[string]*dag.ExternalAuthorization) {
+	servers := map[string]*dag.ExternalAuthorization{}
+
+	var defaultServer string
+	if vhost != nil {
+		defaultServer = authorizationServerKey(vhost)
+		servers[defaultServer] = vhost
+	}
+	for _, ext := range routes {
+		servers[authorizationServerKey(ext)] = ext
+	}
+
+	keys := make([]string, 0, len(servers))
+	for key := range servers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return defaultServer, keys, servers
+}
+
+// authorizationServerKey returns a key that identifies the
+// configuration of an authorization server.
+func authorizationServerKey(ext *dag.ExternalAuthorization) string {
+	timeout := "default"
+	switch t := ext.AuthorizationResponseTimeout; {
+	case t.IsDisabled():
+		timeout = "infinity"
+	case !t.UseDefault():
+		timeout = t.Duration().String()
+	}
+
+	body := "none"
+	if b := ext.WithRequestBody; b != nil {
+		body = fmt.Sprintf("%d,%t,%t", b.MaxRequestBytes, b.AllowPartialMessage, b.PackAsBytes)
+	}
+
+	protocol := "grpc"
+	if ext.HTTPService != nil {
+		protocol = "http," + ext.HTTPService.PathPrefix
+	}
+
+	options := strings.Join([]string{
+		protocol,
+		strconv.FormatBool(ext.AuthorizationFailOpen),
+		timeout,
+		body,
+		strings.Join(ext.AllowedRequestHeaders, ","),
+		strings.Join(ext.AllowedUpstreamHeaders, ","),
+		strings.Join(ext.AllowedClientHeaders, ","),
+		strconv.FormatUint(uint64(ext.StatusOnDeny), 10),
+		strconv.FormatBool(ext.IncludeClientCertificate),
+	}, ";")
+
+	return ext.AuthorizationService.Name + "/" + fmt.Sprintf("%x", sha256.Sum256([]byte(options)))[:10]
+}
+
+// authorizationServerMatcher returns a matcher for the dynamic
+// metadata of the requests that selected the authorization server
+// with the given key.
+func authorizationServerMatcher(key string) *matcher.MetadataMatcher {
+	return &matcher.MetadataMatcher{
+		Filter: LuaFilterName,
+		Path: []*matcher.MetadataMatcher_PathSegment{{
+			Segment: &matcher.MetadataMatcher_PathSegment_Key{
+				Key: authorizationServerMetadataKey,
+			},
+		}},
+		Value: &matcher.ValueMatcher{
+			MatchPattern: &matcher.ValueMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Exact{
+						Exact: key,
+					},
+				},
+			},
+		},
+	}
+}
+
+// filterAuthorizationServerSelector returns a Lua filter that stores
+// the authorization server from the route metadata in the dynamic
+// metadata of the request. Requests to routes that don't override the
+// server select defaultServer.
+func filterAuthorizationServerSelector(defaultServer string) *http.HttpFilter {
+	return &http.HttpFilter{
+		Name: LuaFilterName,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&lua.Lua{
+				InlineCode: fmt.Sprintf(authorizationServerSelectorCode, LuaFilterName, defaultServer),
+			}),
+		},
+	}
+}
+
+const authorizationServerSelectorCode = `
+function envoy_on_request(request_handle)
+	local server = request_handle:metadata():get("authorization_server") or %[2]q
+	request_handle:streamInfo():dynamicMetadata():set(%[1]q, "authorization_server", server)
+end
+
+function envoy_on_response(response_handle)
+end
+`
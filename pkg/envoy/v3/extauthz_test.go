@@ -0,0 +1,374 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	_struct "github.com/golang/protobuf/ptypes/struct"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+	"github.com/projectcontour/contour/pkg/timeout"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestFilterExternalAuthzServers(t *testing.T) {
+	vhost := &dag.ExternalAuthorization{
+		AuthorizationService:         &dag.ExtensionCluster{Name: "extension/auth/vhost"},
+		AuthorizationResponseTimeout: timeout.DurationSetting(10 * time.Second),
+	}
+	route := &dag.ExternalAuthorization{
+		AuthorizationService:         &dag.ExtensionCluster{Name: "extension/auth/route"},
+		AuthorizationResponseTimeout: timeout.DisabledSetting(),
+		AuthorizationFailOpen:        true,
+	}
+
+	selected := func(ext *dag.ExternalAuthorization, statPrefix string) *http.HttpFilter {
+		authConfig := externalAuthzConfig(ext)
+		authConfig.StatPrefix = statPrefix
+		authConfig.FilterEnabledMetadata = &matcher.MetadataMatcher{
+			Filter: "envoy.filters.http.lua",
+			Path: []*matcher.MetadataMatcher_PathSegment{{
+				Segment: &matcher.MetadataMatcher_PathSegment_Key{Key: "authorization_server"},
+			}},
+			Value: &matcher.ValueMatcher{
+				MatchPattern: &matcher.ValueMatcher_StringMatch{
+					StringMatch: &matcher.StringMatcher{
+						MatchPattern: &matcher.StringMatcher_Exact{Exact: authorizationServerKey(ext)},
+					},
+				},
+			},
+		}
+		return filterExternalAuthz(authConfig)
+	}
+
+	selector := func(defaultServer string) *http.HttpFilter {
+		return &http.HttpFilter{
+			Name: "envoy.filters.http.lua",
+			ConfigType: &http.HttpFilter_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&lua.Lua{
+					InlineCode: `
+function envoy_on_request(request_handle)
+	local server = request_handle:metadata():get("authorization_server") or "` + defaultServer + `"
+	request_handle:streamInfo():dynamicMetadata():set("envoy.filters.http.lua", "authorization_server", server)
+end
+
+function envoy_on_response(response_handle)
+end
+`,
+				}),
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		vhost  *dag.ExternalAuthorization
+		routes []*dag.ExternalAuthorization
+		want   []*http.HttpFilter
+	}{
+		"no authorization": {
+			want: nil,
+		},
+		"virtual host only": {
+			vhost: vhost,
+			want: []*http.HttpFilter{
+				FilterExternalAuthz(vhost),
+			},
+		},
+		"route overrides virtual host": {
+			vhost:  vhost,
+			routes: []*dag.ExternalAuthorization{route, route},
+			want: []*http.HttpFilter{
+				selector(authorizationServerKey(vhost)),
+				selected(route, "extension_auth_route"),
+				selected(vhost, "extension_auth_vhost"),
+			},
+		},
+		"route without virtual host": {
+			routes: []*dag.ExternalAuthorization{route},
+			want: []*http.HttpFilter{
+				selector(""),
+				selected(route, "extension_auth_route"),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := FilterExternalAuthzServers(tc.vhost, tc.routes)
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
+
+func TestRouteExternalAuthorization(t *testing.T) {
+	ext := &dag.ExternalAuthorization{
+		AuthorizationService: &dag.ExtensionCluster{Name: "extension/auth/route"},
+	}
+
+	tests := map[string]struct {
+		md   *envoy_core_v3.Metadata
+		ext  *dag.ExternalAuthorization
+		want *envoy_core_v3.Metadata
+	}{
+		"no authorization": {
+			md:   nil,
+			ext:  nil,
+			want: nil,
+		},
+		"no metadata": {
+			md:  nil,
+			ext: ext,
+			want: &envoy_core_v3.Metadata{
+				FilterMetadata: map[string]*_struct.Struct{
+					"envoy.filters.http.lua": {
+						Fields: map[string]*_struct.Value{
+							"authorization_server": sv(authorizationServerKey(ext)),
+						},
+					},
+				},
+			},
+		},
+		"merged with basic auth": {
+			md:  RouteBasicAuth(&dag.BasicAuth{Secret: types.NamespacedName{Namespace: "default", Name: "htpasswd"}, Realm: "example.com"}),
+			ext: ext,
+			want: &envoy_core_v3.Metadata{
+				FilterMetadata: map[string]*_struct.Struct{
+					"envoy.filters.http.lua": {
+						Fields: map[string]*_struct.Value{
+							"basic_auth_secret":    sv("default/htpasswd"),
+							"basic_auth_realm":     sv("example.com"),
+							"authorization_server": sv(authorizationServerKey(ext)),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := RouteExternalAuthorization(tc.md, tc.ext)
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
+
+func TestFilterExternalAuthzOptions(t *testing.T) {
+	got := externalAuthzConfig(&dag.ExternalAuthorization{
+		AuthorizationService: &dag.ExtensionCluster{Name: "extension/auth/vhost"},
+		WithRequestBody: &dag.AuthorizationBufferSettings{
+			MaxRequestBytes:     1024,
+			AllowPartialMessage: true,
+		},
+	})
+
+	want := &envoy_config_filter_http_ext_authz_v3.BufferSettings{
+		MaxRequestBytes:     1024,
+		AllowPartialMessage: true,
+	}
+
+	protobuf.ExpectEqual(t, want, got.WithRequestBody)
+	assert.False(t, got.IncludePeerCertificate)
+}
+
+func TestAuthorizationServerKey(t *testing.T) {
+	ext := dag.ExternalAuthorization{
+		AuthorizationService: &dag.ExtensionCluster{Name: "extension/auth/vhost"},
+	}
+
+	withBody := ext
+	withBody.WithRequestBody = &dag.AuthorizationBufferSettings{MaxRequestBytes: 1024}
+
+	withStatus := ext
+	withStatus.StatusOnDeny = 401
+
+	withHTTP := ext
+	withHTTP.HTTPService = &dag.AuthorizationHTTPService{PathPrefix: "/verify"}
+
+	key := authorizationServerKey(&ext)
+	assert.Equal(t, key, authorizationServerKey(&dag.ExternalAuthorization{
+		AuthorizationService: &dag.ExtensionCluster{Name: "extension/auth/vhost"},
+	}))
+	assert.NotEqual(t, key, authorizationServerKey(&withBody))
+	assert.NotEqual(t, key, authorizationServerKey(&withStatus))
+	assert.NotEqual(t, key, authorizationServerKey(&withHTTP))
+}
+
+func TestExternalAuthzLocalReplyConfig(t *testing.T) {
+	vhost := &dag.ExternalAuthorization{
+		AuthorizationService: &dag.ExtensionCluster{Name: "extension/auth/vhost"},
+		StatusOnDeny:         401,
+	}
+	route := &dag.ExternalAuthorization{
+		AuthorizationService: &dag.ExtensionCluster{Name: "extension/auth/route"},
+	}
+
+	statusFilters := func(status uint32) []*accesslog.AccessLogFilter {
+		return []*accesslog.AccessLogFilter{{
+			FilterSpecifier: &accesslog.AccessLogFilter_ResponseFlagFilter{
+				ResponseFlagFilter: &accesslog.ResponseFlagFilter{
+					Flags: []string{"UAEX"},
+				},
+			},
+		}, {
+			FilterSpecifier: &accesslog.AccessLogFilter_StatusCodeFilter{
+				StatusCodeFilter: &accesslog.StatusCodeFilter{
+					Comparison: &accesslog.ComparisonFilter{
+						Op: accesslog.ComparisonFilter_EQ,
+						Value: &envoy_core_v3.RuntimeUInt32{
+							DefaultValue: status,
+							RuntimeKey:   "contour.ext_authz.status",
+						},
+					},
+				},
+			},
+		}}
+	}
+
+	mapper := func(filters []*accesslog.AccessLogFilter, status uint32) *http.ResponseMapper {
+		return &http.ResponseMapper{
+			Filter: &accesslog.AccessLogFilter{
+				FilterSpecifier: &accesslog.AccessLogFilter_AndFilter{
+					AndFilter: &accesslog.AndFilter{
+						Filters: filters,
+					},
+				},
+			},
+			StatusCode: protobuf.UInt32(status),
+		}
+	}
+
+	selected := &accesslog.AccessLogFilter{
+		FilterSpecifier: &accesslog.AccessLogFilter_MetadataFilter{
+			MetadataFilter: &accesslog.MetadataFilter{
+				Matcher: authorizationServerMatcher(authorizationServerKey(vhost)),
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		vhost  *dag.ExternalAuthorization
+		routes []*dag.ExternalAuthorization
+		want   *http.LocalReplyConfig
+	}{
+		"no status on deny": {
+			vhost:  route,
+			routes: nil,
+			want:   nil,
+		},
+		// Denials with the default 403 status are replaced, and
+		// errors, which have their own status, are mapped back to 403.
+		"virtual host only": {
+			vhost: vhost,
+			want: &http.LocalReplyConfig{
+				Mappers: []*http.ResponseMapper{
+					mapper(statusFilters(403), 401),
+					mapper(statusFilters(511), 403),
+				},
+			},
+		},
+		"route overrides virtual host": {
+			vhost:  vhost,
+			routes: []*dag.ExternalAuthorization{route},
+			want: &http.LocalReplyConfig{
+				Mappers: []*http.ResponseMapper{
+					mapper(append(statusFilters(403), selected), 401),
+					mapper(append(statusFilters(511), selected), 403),
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ExternalAuthzLocalReplyConfig(tc.vhost, tc.routes)
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
+
+func TestFilterExternalAuthzHTTPService(t *testing.T) {
+	tests := map[string]struct {
+		ext  *dag.ExternalAuthorization
+		want *envoy_config_filter_http_ext_authz_v3.HttpService
+	}{
+		"default timeout": {
+			ext: &dag.ExternalAuthorization{
+				AuthorizationService: &dag.ExtensionCluster{Name: "extension/auth/forward"},
+				HTTPService:          &dag.AuthorizationHTTPService{},
+			},
+			want: &envoy_config_filter_http_ext_authz_v3.HttpService{
+				ServerUri: &envoy_core_v3.HttpUri{
+					Uri:              "http://extension/auth/forward",
+					HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{Cluster: "extension/auth/forward"},
+					Timeout:          protobuf.Duration(200 * time.Millisecond),
+				},
+			},
+		},
+		"allowed headers": {
+			ext: &dag.ExternalAuthorization{
+				AuthorizationService:         &dag.ExtensionCluster{Name: "extension/auth/forward"},
+				AuthorizationResponseTimeout: timeout.DurationSetting(time.Second),
+				HTTPService: &dag.AuthorizationHTTPService{
+					PathPrefix: "/verify",
+				},
+				AllowedRequestHeaders:  []string{"Cookie"},
+				AllowedUpstreamHeaders: []string{"X-Auth-User", "X-Auth-Email"},
+			},
+			want: &envoy_config_filter_http_ext_authz_v3.HttpService{
+				ServerUri: &envoy_core_v3.HttpUri{
+					Uri:              "http://extension/auth/forward",
+					HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{Cluster: "extension/auth/forward"},
+					Timeout:          protobuf.Duration(time.Second),
+				},
+				PathPrefix: "/verify",
+				AuthorizationRequest: &envoy_config_filter_http_ext_authz_v3.AuthorizationRequest{
+					AllowedHeaders: &matcher.ListStringMatcher{
+						Patterns: []*matcher.StringMatcher{{
+							MatchPattern: &matcher.StringMatcher_Exact{Exact: "Cookie"},
+							IgnoreCase:   true,
+						}},
+					},
+				},
+				AuthorizationResponse: &envoy_config_filter_http_ext_authz_v3.AuthorizationResponse{
+					AllowedUpstreamHeaders: &matcher.ListStringMatcher{
+						Patterns: []*matcher.StringMatcher{{
+							MatchPattern: &matcher.StringMatcher_Exact{Exact: "X-Auth-User"},
+							IgnoreCase:   true,
+						}, {
+							MatchPattern: &matcher.StringMatcher_Exact{Exact: "X-Auth-Email"},
+							IgnoreCase:   true,
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := externalAuthzConfig(tc.ext).GetHttpService()
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
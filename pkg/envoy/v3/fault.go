@@ -0,0 +1,61 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	envoy_fault_common_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/common/fault/v3"
+	envoy_fault_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/fault/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+)
+
+// FaultInjectionConfig returns a per-route config for the HTTP
+// fault filter.
+func FaultInjectionConfig(policy *dag.FaultInjectionPolicy) *any.Any {
+	if policy == nil {
+		return nil
+	}
+
+	c := &envoy_fault_v3.HTTPFault{
+		Headers: headerMatcher(policy.HeaderMatchConditions),
+	}
+
+	if policy.Delay != nil {
+		c.Delay = &envoy_fault_common_v3.FaultDelay{
+			FaultDelaySecifier: &envoy_fault_common_v3.FaultDelay_FixedDelay{
+				FixedDelay: protobuf.Duration(policy.Delay.Duration),
+			},
+			Percentage: &envoy_type_v3.FractionalPercent{
+				Numerator:   policy.Delay.Percent,
+				Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+			},
+		}
+	}
+
+	if policy.Abort != nil {
+		c.Abort = &envoy_fault_v3.FaultAbort{
+			ErrorType: &envoy_fault_v3.FaultAbort_HttpStatus{
+				HttpStatus: policy.Abort.StatusCode,
+			},
+			Percentage: &envoy_type_v3.FractionalPercent{
+				Numerator:   policy.Abort.Percent,
+				Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+			},
+		}
+	}
+
+	return protobuf.MustMarshalAny(c)
+}
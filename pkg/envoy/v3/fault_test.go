@@ -0,0 +1,92 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_fault_common_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/common/fault/v3"
+	envoy_fault_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/fault/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestFaultInjectionConfig(t *testing.T) {
+	tests := map[string]struct {
+		policy *dag.FaultInjectionPolicy
+		want   *anypb.Any
+	}{
+		"nil config": {
+			policy: nil,
+			want:   nil,
+		},
+		"delay": {
+			policy: &dag.FaultInjectionPolicy{
+				Delay: &dag.FaultDelay{
+					Duration: 250 * time.Millisecond,
+					Percent:  50,
+				},
+			},
+			want: protobuf.MustMarshalAny(&envoy_fault_v3.HTTPFault{
+				Delay: &envoy_fault_common_v3.FaultDelay{
+					FaultDelaySecifier: &envoy_fault_common_v3.FaultDelay_FixedDelay{
+						FixedDelay: protobuf.Duration(250 * time.Millisecond),
+					},
+					Percentage: &envoy_type_v3.FractionalPercent{
+						Numerator:   50,
+						Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+					},
+				},
+			}),
+		},
+		"abort with header trigger": {
+			policy: &dag.FaultInjectionPolicy{
+				Abort: &dag.FaultAbort{
+					StatusCode: 503,
+					Percent:    100,
+				},
+				HeaderMatchConditions: []dag.HeaderMatchCondition{{
+					Name:      "X-Chaos",
+					MatchType: dag.HeaderMatchTypePresent,
+				}},
+			},
+			want: protobuf.MustMarshalAny(&envoy_fault_v3.HTTPFault{
+				Abort: &envoy_fault_v3.FaultAbort{
+					ErrorType: &envoy_fault_v3.FaultAbort_HttpStatus{
+						HttpStatus: 503,
+					},
+					Percentage: &envoy_type_v3.FractionalPercent{
+						Numerator:   100,
+						Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+					},
+				},
+				Headers: []*envoy_route_v3.HeaderMatcher{{
+					Name:                 "X-Chaos",
+					HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_PresentMatch{PresentMatch: true},
+				}},
+			}),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := FaultInjectionConfig(tc.policy)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
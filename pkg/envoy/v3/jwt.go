@@ -0,0 +1,121 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"fmt"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_jwt_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/envoy"
+	"github.com/projectcontour/contour/pkg/protobuf"
+)
+
+// JWTAuthnFilterName is the name of the JWT authentication filter, which
+// is also the namespace of the dynamic metadata that verified JWT payloads
+// are stored in.
+const JWTAuthnFilterName = "envoy.filters.http.jwt_authn"
+
+// FilterJWTAuthN returns a `jwt_authn` filter configured with the
+// supplied providers. Each provider has a requirement of the same
+// name, which routes select with RouteJWTProvider. Requests to
+// routes that do not select a requirement are not verified.
+func FilterJWTAuthN(providers []dag.JWTProvider) *http.HttpFilter {
+	if len(providers) == 0 {
+		return nil
+	}
+
+	jwtConfig := envoy_jwt_v3.JwtAuthentication{
+		Providers:      map[string]*envoy_jwt_v3.JwtProvider{},
+		RequirementMap: map[string]*envoy_jwt_v3.JwtRequirement{},
+	}
+
+	for _, provider := range providers {
+		jwtProvider := &envoy_jwt_v3.JwtProvider{
+			Issuer:            provider.Issuer,
+			Audiences:         provider.Audiences,
+			Forward:           provider.ForwardJWT,
+			PayloadInMetadata: provider.Name,
+		}
+
+		if provider.RemoteJWKS != nil {
+			remoteJWKS := &envoy_jwt_v3.RemoteJwks{
+				HttpUri: &envoy_core_v3.HttpUri{
+					Uri: provider.RemoteJWKS.URI,
+					HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{
+						Cluster: envoy.DNSNameClustername(&provider.RemoteJWKS.Cluster),
+					},
+					Timeout: protobuf.Duration(provider.RemoteJWKS.Timeout),
+				},
+			}
+			if provider.RemoteJWKS.CacheDuration > 0 {
+				remoteJWKS.CacheDuration = protobuf.Duration(provider.RemoteJWKS.CacheDuration)
+			}
+
+			jwtProvider.JwksSourceSpecifier = &envoy_jwt_v3.JwtProvider_RemoteJwks{
+				RemoteJwks: remoteJWKS,
+			}
+		} else {
+			jwtProvider.JwksSourceSpecifier = &envoy_jwt_v3.JwtProvider_LocalJwks{
+				LocalJwks: &envoy_core_v3.DataSource{
+					Specifier: &envoy_core_v3.DataSource_InlineString{
+						InlineString: provider.LocalJWKS,
+					},
+				},
+			}
+		}
+
+		jwtConfig.Providers[provider.Name] = jwtProvider
+		jwtConfig.RequirementMap[provider.Name] = &envoy_jwt_v3.JwtRequirement{
+			RequiresType: &envoy_jwt_v3.JwtRequirement_ProviderName{
+				ProviderName: provider.Name,
+			},
+		}
+	}
+
+	return &http.HttpFilter{
+		Name: JWTAuthnFilterName,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&jwtConfig),
+		},
+	}
+}
+
+// RouteJWTProvider returns a per-route config for the `jwt_authn`
+// filter that requires a JWT verified by the named provider.
+func RouteJWTProvider(name string) *any.Any {
+	return protobuf.MustMarshalAny(
+		&envoy_jwt_v3.PerRouteConfig{
+			RequirementSpecifier: &envoy_jwt_v3.PerRouteConfig_RequirementName{
+				RequirementName: name,
+			},
+		},
+	)
+}
+
+// JWTClaimHeaders returns the request headers that forward the claims
+// of a JWT verified by the supplied provider. Since the verified
+// payload is stored in the dynamic metadata of the `jwt_authn` filter,
+// each header value is a reference to the corresponding claim.
+func JWTClaimHeaders(provider *dag.JWTProvider) []*envoy_core_v3.HeaderValueOption {
+	headers := map[string]string{}
+	for _, c := range provider.ClaimsToHeaders {
+		headers[c.Header] = fmt.Sprintf("%%DYNAMIC_METADATA([%q, %q, %q])%%", JWTAuthnFilterName, provider.Name, c.Claim)
+	}
+
+	return HeaderValueList(headers, false)
+}
@@ -0,0 +1,155 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_jwt_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterJWTAuthN(t *testing.T) {
+	tests := map[string]struct {
+		providers []dag.JWTProvider
+		want      *http.HttpFilter
+	}{
+		"no providers": {
+			providers: nil,
+			want:      nil,
+		},
+		"remote and local providers": {
+			providers: []dag.JWTProvider{{
+				Name:       "remote",
+				Issuer:     "https://issuer.example.com",
+				Audiences:  []string{"one", "two"},
+				ForwardJWT: true,
+				RemoteJWKS: &dag.RemoteJWKS{
+					URI:           "https://jwks.example.com/keys.json",
+					Timeout:       time.Second,
+					CacheDuration: 10 * time.Minute,
+					Cluster: dag.DNSNameCluster{
+						Address: "jwks.example.com",
+						Scheme:  "https",
+						Port:    443,
+					},
+				},
+			}, {
+				Name:      "local",
+				LocalJWKS: `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+			}},
+			want: &http.HttpFilter{
+				Name: "envoy.filters.http.jwt_authn",
+				ConfigType: &http.HttpFilter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_jwt_v3.JwtAuthentication{
+						Providers: map[string]*envoy_jwt_v3.JwtProvider{
+							"remote": {
+								Issuer:    "https://issuer.example.com",
+								Audiences: []string{"one", "two"},
+								Forward:   true,
+								JwksSourceSpecifier: &envoy_jwt_v3.JwtProvider_RemoteJwks{
+									RemoteJwks: &envoy_jwt_v3.RemoteJwks{
+										HttpUri: &envoy_core_v3.HttpUri{
+											Uri: "https://jwks.example.com/keys.json",
+											HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{
+												Cluster: "dnsname/jwks.example.com/443/c3437dbc7c",
+											},
+											Timeout: protobuf.Duration(time.Second),
+										},
+										CacheDuration: protobuf.Duration(10 * time.Minute),
+									},
+								},
+								PayloadInMetadata: "remote",
+							},
+							"local": {
+								JwksSourceSpecifier: &envoy_jwt_v3.JwtProvider_LocalJwks{
+									LocalJwks: &envoy_core_v3.DataSource{
+										Specifier: &envoy_core_v3.DataSource_InlineString{
+											InlineString: `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+										},
+									},
+								},
+								PayloadInMetadata: "local",
+							},
+						},
+						RequirementMap: map[string]*envoy_jwt_v3.JwtRequirement{
+							"remote": {
+								RequiresType: &envoy_jwt_v3.JwtRequirement_ProviderName{
+									ProviderName: "remote",
+								},
+							},
+							"local": {
+								RequiresType: &envoy_jwt_v3.JwtRequirement_ProviderName{
+									ProviderName: "local",
+								},
+							},
+						},
+					}),
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			protobuf.ExpectEqual(t, tc.want, FilterJWTAuthN(tc.providers))
+		})
+	}
+}
+
+func TestRouteJWTProvider(t *testing.T) {
+	want := protobuf.MustMarshalAny(&envoy_jwt_v3.PerRouteConfig{
+		RequirementSpecifier: &envoy_jwt_v3.PerRouteConfig_RequirementName{
+			RequirementName: "provider-1",
+		},
+	})
+
+	assert.Equal(t, want, RouteJWTProvider("provider-1"))
+}
+
+func TestJWTClaimHeaders(t *testing.T) {
+	provider := &dag.JWTProvider{
+		Name: "provider-1",
+		ClaimsToHeaders: []dag.JWTClaimToHeader{{
+			Claim:  "sub",
+			Header: "X-Jwt-Sub",
+		}, {
+			Claim:  "email",
+			Header: "X-Jwt-Email",
+		}},
+	}
+
+	want := []*envoy_core_v3.HeaderValueOption{{
+		Header: &envoy_core_v3.HeaderValue{
+			Key:   "X-Jwt-Email",
+			Value: `%DYNAMIC_METADATA(["envoy.filters.http.jwt_authn", "provider-1", "email"])%`,
+		},
+		Append: &wrappers.BoolValue{Value: false},
+	}, {
+		Header: &envoy_core_v3.HeaderValue{
+			Key:   "X-Jwt-Sub",
+			Value: `%DYNAMIC_METADATA(["envoy.filters.http.jwt_authn", "provider-1", "sub"])%`,
+		},
+		Append: &wrappers.BoolValue{Value: false},
+	}}
+
+	assert.Equal(t, want, JWTClaimHeaders(provider))
+	assert.Empty(t, JWTClaimHeaders(&dag.JWTProvider{Name: "provider-2"}))
+}
@@ -26,8 +26,10 @@ import (
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	envoy_compressor_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/compressor/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	envoy_fault_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/fault/v3"
 	envoy_config_filter_http_local_ratelimit_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
 	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	envoy_extensions_filters_http_router_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	tcp "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
@@ -36,6 +38,7 @@ import (
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
+	"github.com/projectcontour/contour/pkg/config"
 	"github.com/projectcontour/contour/pkg/dag"
 	"github.com/projectcontour/contour/pkg/envoy"
 	"github.com/projectcontour/contour/pkg/protobuf"
@@ -55,6 +58,7 @@ const (
 	HTTPFilterCORS    = "type.googleapis.com/envoy.extensions.filters.http.cors.v3.Cors"
 	HTTPFilterGrpcWeb = "type.googleapis.com/envoy.extensions.filters.http.grpc_web.v3.GrpcWeb"
 	HTTPFilterGzip    = "type.googleapis.com/envoy.extensions.compression.gzip.compressor.v3.Gzip"
+	HTTPFilterBrotli  = "type.googleapis.com/envoy.extensions.compression.brotli.compressor.v3.Brotli"
 )
 
 // ProtoNamesForVersions returns the slice of ALPN protocol names for the give HTTP versions.
@@ -154,6 +158,9 @@ type httpConnectionManagerBuilder struct {
 	codec                         HTTPVersionType // Note the zero value is AUTO, which is the default we want.
 	allowChunkedLength            bool
 	numTrustedHops                uint32
+	compression                   config.CompressionParameters
+	localReplyConfig              *http.LocalReplyConfig
+	forwardClientCertificate      *dag.ClientCertificateDetails
 }
 
 // RouteConfigName sets the name of the RDS element that contains
@@ -226,30 +233,40 @@ func (b *httpConnectionManagerBuilder) AllowChunkedLength(enabled bool) *httpCon
 	return b
 }
 
+// LocalReplyConfig sets the configuration that modifies the local
+// replies of the connection manager. It may be nil.
+func (b *httpConnectionManagerBuilder) LocalReplyConfig(cfg *http.LocalReplyConfig) *httpConnectionManagerBuilder {
+	b.localReplyConfig = cfg
+	return b
+}
+
+// ForwardClientCertificate sets the details of client certificates
+// that are forwarded in the x-forwarded-client-cert header. If details
+// is nil, the header is removed from requests.
+func (b *httpConnectionManagerBuilder) ForwardClientCertificate(details *dag.ClientCertificateDetails) *httpConnectionManagerBuilder {
+	b.forwardClientCertificate = details
+	return b
+}
+
 func (b *httpConnectionManagerBuilder) NumTrustedHops(num uint32) *httpConnectionManagerBuilder {
 	b.numTrustedHops = num
 	return b
 }
 
+// Compression sets the response compression settings used by the
+// compressor filters. It must be called before DefaultFilters.
+func (b *httpConnectionManagerBuilder) Compression(c config.CompressionParameters) *httpConnectionManagerBuilder {
+	b.compression = c
+	return b
+}
+
 func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBuilder {
 
 	// Add a default set of ordered http filters.
 	// The names are not required to match anything and are
 	// identified by the TypeURL of each filter.
+	b.filters = append(b.filters, compressorFilters(b.compression)...)
 	b.filters = append(b.filters,
-		&http.HttpFilter{
-			Name: "compressor",
-			ConfigType: &http.HttpFilter_TypedConfig{
-				TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
-					CompressorLibrary: &envoy_core_v3.TypedExtensionConfig{
-						Name: "gzip",
-						TypedConfig: &any.Any{
-							TypeUrl: HTTPFilterGzip,
-						},
-					},
-				}),
-			},
-		},
 		&http.HttpFilter{
 			Name: "grpcweb",
 			ConfigType: &http.HttpFilter_TypedConfig{
@@ -266,6 +283,26 @@ func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBu
 				},
 			},
 		},
+		&http.HttpFilter{
+			Name: "rbac",
+			ConfigType: &http.HttpFilter_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(
+					// since no rules are defined here, the filter allows all
+					// requests but can be enabled on a per-vhost/route basis.
+					&envoy_rbac_v3.RBAC{},
+				),
+			},
+		},
+		&http.HttpFilter{
+			Name: "fault",
+			ConfigType: &http.HttpFilter_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(
+					// since no faults are defined here, the filter is disabled
+					// globally but can be enabled on a per-route basis.
+					&envoy_fault_v3.HTTPFault{},
+				),
+			},
+		},
 		&http.HttpFilter{
 			Name: "local_ratelimit",
 			ConfigType: &http.HttpFilter_TypedConfig{
@@ -291,6 +328,66 @@ func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBu
 	return b
 }
 
+// compressorFilters returns a compressor filter for each configured
+// compression algorithm, or gzip if none are configured.
+func compressorFilters(c config.CompressionParameters) []*http.HttpFilter {
+	if c.Disabled {
+		return nil
+	}
+
+	algorithms := c.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []config.CompressionAlgorithm{config.GzipCompression}
+	}
+
+	var responseConfig *envoy_compressor_v3.Compressor_ResponseDirectionConfig
+	if c.MinContentLength != nil || len(c.ContentTypes) > 0 {
+		responseConfig = &envoy_compressor_v3.Compressor_ResponseDirectionConfig{
+			CommonConfig: &envoy_compressor_v3.Compressor_CommonDirectionConfig{
+				ContentType: c.ContentTypes,
+			},
+		}
+		if c.MinContentLength != nil {
+			responseConfig.CommonConfig.MinContentLength = protobuf.UInt32(*c.MinContentLength)
+		}
+	}
+
+	var filters []*http.HttpFilter
+	for _, algorithm := range algorithms {
+		name, typeURL := "compressor", HTTPFilterGzip
+		if algorithm == config.BrotliCompression {
+			name, typeURL = "compressor_brotli", HTTPFilterBrotli
+		}
+
+		filters = append(filters, &http.HttpFilter{
+			Name: name,
+			ConfigType: &http.HttpFilter_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
+					CompressorLibrary: &envoy_core_v3.TypedExtensionConfig{
+						Name: string(algorithm),
+						TypedConfig: &any.Any{
+							TypeUrl: typeURL,
+						},
+					},
+					ResponseDirectionConfig: responseConfig,
+				}),
+			},
+		})
+	}
+
+	return filters
+}
+
+// AddFilters appends each of filters to the list of filters for this
+// HTTPConnectionManager, as if by AddFilter.
+func (b *httpConnectionManagerBuilder) AddFilters(filters ...*http.HttpFilter) *httpConnectionManagerBuilder {
+	for _, f := range filters {
+		b.AddFilter(f)
+	}
+
+	return b
+}
+
 // AddFilter appends f to the list of filters for this HTTPConnectionManager. f
 // may be nil, in which case it is ignored. Note that Router filters
 // (filters with TypeUrl `type.googleapis.com/envoy.extensions.filters.http.router.v3.Router`)
@@ -406,6 +503,7 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 		DrainTimeout:        envoy.Timeout(b.connectionShutdownGracePeriod),
 		DelayedCloseTimeout: envoy.Timeout(b.delayedCloseTimeout),
 		XffNumTrustedHops:   b.numTrustedHops,
+		LocalReplyConfig:    b.localReplyConfig,
 	}
 
 	// Max connection duration is infinite/disabled by default in Envoy, so if the timeout setting
@@ -416,6 +514,17 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 		cm.CommonHttpProtocolOptions.MaxConnectionDuration = protobuf.Duration(b.maxConnectionDuration.Duration())
 	}
 
+	if b.forwardClientCertificate != nil {
+		cm.ForwardClientCertDetails = http.HttpConnectionManager_SANITIZE_SET
+		cm.SetCurrentClientCertDetails = &http.HttpConnectionManager_SetCurrentClientCertDetails{
+			Subject: protobuf.Bool(b.forwardClientCertificate.Subject),
+			Cert:    b.forwardClientCertificate.Cert,
+			Chain:   b.forwardClientCertificate.Chain,
+			Dns:     b.forwardClientCertificate.DNS,
+			Uri:     b.forwardClientCertificate.URI,
+		}
+	}
+
 	if len(b.accessLoggers) > 0 {
 		cm.AccessLog = b.accessLoggers
 	}
@@ -585,7 +694,7 @@ end
 	`
 
 	return &http.HttpFilter{
-		Name: "envoy.filters.http.lua",
+		Name: LuaFilterName,
 		ConfigType: &http.HttpFilter_TypedConfig{
 			TypedConfig: protobuf.MustMarshalAny(&lua.Lua{
 				InlineCode: fmt.Sprintf(code, strings.ToLower(fqdn)),
@@ -596,16 +705,22 @@ end
 
 // FilterExternalAuthz returns an `ext_authz` filter configured with the
 // requested parameters.
-func FilterExternalAuthz(authzClusterName string, failOpen bool, timeout timeout.Setting) *http.HttpFilter {
-	authConfig := envoy_config_filter_http_ext_authz_v3.ExtAuthz{
+func FilterExternalAuthz(ext *dag.ExternalAuthorization) *http.HttpFilter {
+	return filterExternalAuthz(externalAuthzConfig(ext))
+}
+
+// externalAuthzConfig returns the `ext_authz` filter configuration for
+// the requested parameters.
+func externalAuthzConfig(ext *dag.ExternalAuthorization) *envoy_config_filter_http_ext_authz_v3.ExtAuthz {
+	authConfig := &envoy_config_filter_http_ext_authz_v3.ExtAuthz{
 		Services: &envoy_config_filter_http_ext_authz_v3.ExtAuthz_GrpcService{
 			GrpcService: &envoy_core_v3.GrpcService{
 				TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
 					EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
-						ClusterName: authzClusterName,
+						ClusterName: ext.AuthorizationService.Name,
 					},
 				},
-				Timeout: envoy.Timeout(timeout),
+				Timeout: envoy.Timeout(ext.AuthorizationResponseTimeout),
 				// We don't need to configure metadata here, since we allow
 				// operators to specify authorization context parameters at
 				// the virtual host and route.
@@ -616,21 +731,41 @@ func FilterExternalAuthz(authzClusterName string, failOpen bool, timeout timeout
 		// external auth service if it is not going to affect
 		// routing decisions?
 		ClearRouteCache:  true,
-		FailureModeAllow: failOpen,
+		FailureModeAllow: ext.AuthorizationFailOpen,
 		StatusOnError: &envoy_type.HttpStatus{
 			Code: envoy_type.StatusCode_Forbidden,
 		},
 		MetadataContextNamespaces: []string{},
-		IncludePeerCertificate:    true,
+		IncludePeerCertificate:    ext.IncludeClientCertificate,
 		// TODO(jpeach): When we move to the Envoy v4 API, propagate the
 		// `transport_api_version` from ExtensionServiceSpec ProtocolVersion.
 		TransportApiVersion: envoy_core_v3.ApiVersion_V3,
 	}
 
+	if ext.StatusOnDeny != 0 {
+		authConfig.StatusOnError.Code = externalAuthzErrorStatus
+	}
+
+	if ext.HTTPService != nil {
+		authConfig.Services = externalAuthzHTTPService(ext)
+	}
+
+	if body := ext.WithRequestBody; body != nil {
+		authConfig.WithRequestBody = &envoy_config_filter_http_ext_authz_v3.BufferSettings{
+			MaxRequestBytes:     body.MaxRequestBytes,
+			AllowPartialMessage: body.AllowPartialMessage,
+			PackAsBytes:         body.PackAsBytes,
+		}
+	}
+
+	return authConfig
+}
+
+func filterExternalAuthz(authConfig *envoy_config_filter_http_ext_authz_v3.ExtAuthz) *http.HttpFilter {
 	return &http.HttpFilter{
 		Name: "envoy.filters.http.ext_authz",
 		ConfigType: &http.HttpFilter_TypedConfig{
-			TypedConfig: protobuf.MustMarshalAny(&authConfig),
+			TypedConfig: protobuf.MustMarshalAny(authConfig),
 		},
 	}
 }
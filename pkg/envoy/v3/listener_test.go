@@ -21,12 +21,16 @@ import (
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	envoy_compressor_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/compressor/v3"
+	envoy_fault_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/fault/v3"
 	envoy_config_filter_http_local_ratelimit_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_tcp_proxy_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes/any"
+	"github.com/projectcontour/contour/pkg/config"
 	"github.com/projectcontour/contour/pkg/dag"
 	"github.com/projectcontour/contour/pkg/envoy"
 	"github.com/projectcontour/contour/pkg/protobuf"
@@ -268,6 +272,25 @@ func TestDownstreamTLSContext(t *testing.T) {
 		SubjectName: subjectName,
 	}
 
+	crl := []byte("client-crl")
+
+	peerValidationContextWithOptions := &dag.PeerValidationContext{
+		CACertificate: peerValidationContext.CACertificate,
+		CRL: &dag.Secret{
+			Object: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "crl",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					dag.CRLKey: crl,
+				},
+			},
+		},
+		OptionalClientCertificate: true,
+		SubjectAltNames:           []string{"client.example.com", "spiffe://example.com/client"},
+	}
+
 	tests := map[string]struct {
 		got  *envoy_tls_v3.DownstreamTlsContext
 		want *envoy_tls_v3.DownstreamTlsContext
@@ -306,6 +329,39 @@ func TestDownstreamTLSContext(t *testing.T) {
 				RequireClientCertificate: protobuf.Bool(true),
 			},
 		},
+		"TLS context with optional client authentication, CRL and subject alt names": {
+			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextWithOptions, "h2", "http/1.1"),
+			&envoy_tls_v3.DownstreamTlsContext{
+				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
+					TlsParams:                      tlsParams,
+					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
+					AlpnProtocols:                  alpnProtocols,
+					ValidationContextType: &envoy_tls_v3.CommonTlsContext_ValidationContext{
+						ValidationContext: &envoy_tls_v3.CertificateValidationContext{
+							TrustedCa: &envoy_core_v3.DataSource{
+								Specifier: &envoy_core_v3.DataSource_InlineBytes{
+									InlineBytes: ca,
+								},
+							},
+							Crl: &envoy_core_v3.DataSource{
+								Specifier: &envoy_core_v3.DataSource_InlineBytes{
+									InlineBytes: crl,
+								},
+							},
+							MatchSubjectAltNames: []*matcher.StringMatcher{{
+								MatchPattern: &matcher.StringMatcher_Exact{
+									Exact: "client.example.com",
+								},
+							}, {
+								MatchPattern: &matcher.StringMatcher_Exact{
+									Exact: "spiffe://example.com/client",
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -327,6 +383,7 @@ func TestHTTPConnectionManager(t *testing.T) {
 		connectionShutdownGracePeriod timeout.Setting
 		allowChunkedLength            bool
 		xffNumTrustedHops             uint32
+		forwardClientCertificate      *dag.ClientCertificateDetails
 		want                          *envoy_listener_v3.Filter
 	}{
 		"default": {
@@ -384,6 +441,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -475,6 +546,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -567,6 +652,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -660,6 +759,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -752,6 +865,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -845,6 +972,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -936,6 +1077,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -1028,6 +1183,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -1121,6 +1290,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -1215,6 +1398,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -1252,6 +1449,126 @@ func TestHTTPConnectionManager(t *testing.T) {
 				},
 			},
 		},
+
+		"forward client certificate": {
+			routename:                     "default/kuard",
+			accesslogger:                  FileAccessLogEnvoy("/dev/stdout"),
+			connectionShutdownGracePeriod: timeout.DurationSetting(90 * time.Second),
+			xffNumTrustedHops:             1,
+			forwardClientCertificate: &dag.ClientCertificateDetails{
+				Subject: true,
+				Cert:    true,
+				DNS:     true,
+			},
+			want: &envoy_listener_v3.Filter{
+				Name: wellknown.HTTPConnectionManager,
+				ConfigType: &envoy_listener_v3.Filter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&http.HttpConnectionManager{
+						StatPrefix: "default/kuard",
+						RouteSpecifier: &http.HttpConnectionManager_Rds{
+							Rds: &http.Rds{
+								RouteConfigName: "default/kuard",
+								ConfigSource: &envoy_core_v3.ConfigSource{
+									ResourceApiVersion: envoy_core_v3.ApiVersion_V3,
+									ConfigSourceSpecifier: &envoy_core_v3.ConfigSource_ApiConfigSource{
+										ApiConfigSource: &envoy_core_v3.ApiConfigSource{
+											ApiType:             envoy_core_v3.ApiConfigSource_GRPC,
+											TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+											GrpcServices: []*envoy_core_v3.GrpcService{{
+												TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+													EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+														ClusterName: "contour",
+													},
+												},
+											}},
+										},
+									},
+								},
+							},
+						},
+						HttpFilters: []*http.HttpFilter{{
+							Name: "compressor",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
+									CompressorLibrary: &envoy_core_v3.TypedExtensionConfig{
+										Name: "gzip",
+										TypedConfig: &any.Any{
+											TypeUrl: HTTPFilterGzip,
+										},
+									},
+								}),
+							},
+						}, {
+							Name: "grpcweb",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: &any.Any{
+									TypeUrl: HTTPFilterGrpcWeb,
+								},
+							},
+						}, {
+							Name: "cors",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: &any.Any{
+									TypeUrl: HTTPFilterCORS,
+								},
+							},
+						}, {
+							Name: "rbac",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_rbac_v3.RBAC{},
+								),
+							},
+						}, {
+							Name: "fault",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_fault_v3.HTTPFault{},
+								),
+							},
+						}, {
+							Name: "local_ratelimit",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_local_ratelimit_v3.LocalRateLimit{
+										StatPrefix: "http",
+									},
+								),
+							},
+						}, {
+							Name: "router",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: &any.Any{
+									TypeUrl: HTTPFilterRouter,
+								},
+							},
+						}},
+						HttpProtocolOptions: &envoy_core_v3.Http1ProtocolOptions{
+							// Enable support for HTTP/1.0 requests that carry
+							// a Host: header. See #537.
+							AcceptHttp_10: true,
+						},
+						CommonHttpProtocolOptions: &envoy_core_v3.HttpProtocolOptions{},
+						AccessLog:                 FileAccessLogEnvoy("/dev/stdout"),
+						UseRemoteAddress:          protobuf.Bool(true),
+						NormalizePath:             protobuf.Bool(true),
+						StripPortMode: &http.HttpConnectionManager_StripAnyHostPort{
+							StripAnyHostPort: true,
+						},
+						PreserveExternalRequestId: true,
+						MergeSlashes:              true,
+						DrainTimeout:              protobuf.Duration(90 * time.Second),
+						XffNumTrustedHops:         1,
+						ForwardClientCertDetails:  http.HttpConnectionManager_SANITIZE_SET,
+						SetCurrentClientCertDetails: &http.HttpConnectionManager_SetCurrentClientCertDetails{
+							Subject: protobuf.Bool(true),
+							Cert:    true,
+							Dns:     true,
+						},
+					}),
+				},
+			},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -1267,6 +1584,7 @@ func TestHTTPConnectionManager(t *testing.T) {
 				ConnectionShutdownGracePeriod(tc.connectionShutdownGracePeriod).
 				AllowChunkedLength(tc.allowChunkedLength).
 				NumTrustedHops(tc.xffNumTrustedHops).
+				ForwardClientCertificate(tc.forwardClientCertificate).
 				DefaultFilters().
 				Get()
 
@@ -1492,7 +1810,10 @@ func TestAddFilter(t *testing.T) {
 		},
 		"Add to the default filters": {
 			builder: HTTPConnectionManagerBuilder().DefaultFilters(),
-			add:     FilterExternalAuthz("test", false, timeout.Setting{}),
+			add: FilterExternalAuthz(&dag.ExternalAuthorization{
+				AuthorizationService:     &dag.ExtensionCluster{Name: "test"},
+				IncludeClientCertificate: true,
+			}),
 			want: []*http.HttpFilter{
 				{
 					Name: "compressor",
@@ -1523,6 +1844,22 @@ func TestAddFilter(t *testing.T) {
 						},
 					},
 				},
+				{
+					Name: "rbac",
+					ConfigType: &http.HttpFilter_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(
+							&envoy_rbac_v3.RBAC{},
+						),
+					},
+				},
+				{
+					Name: "fault",
+					ConfigType: &http.HttpFilter_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(
+							&envoy_fault_v3.HTTPFault{},
+						),
+					},
+				},
 				{
 					Name: "local_ratelimit",
 					ConfigType: &http.HttpFilter_TypedConfig{
@@ -1533,7 +1870,10 @@ func TestAddFilter(t *testing.T) {
 						),
 					},
 				},
-				FilterExternalAuthz("test", false, timeout.Setting{}),
+				FilterExternalAuthz(&dag.ExternalAuthorization{
+					AuthorizationService:     &dag.ExtensionCluster{Name: "test"},
+					IncludeClientCertificate: true,
+				}),
 				{
 					Name: "router",
 					ConfigType: &http.HttpFilter_TypedConfig{
@@ -1564,3 +1904,81 @@ func TestAddFilter(t *testing.T) {
 		})
 	})
 }
+
+func TestCompressorFilters(t *testing.T) {
+	gzip := func(response *envoy_compressor_v3.Compressor_ResponseDirectionConfig) *http.HttpFilter {
+		return &http.HttpFilter{
+			Name: "compressor",
+			ConfigType: &http.HttpFilter_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
+					CompressorLibrary: &envoy_core_v3.TypedExtensionConfig{
+						Name: "gzip",
+						TypedConfig: &any.Any{
+							TypeUrl: HTTPFilterGzip,
+						},
+					},
+					ResponseDirectionConfig: response,
+				}),
+			},
+		}
+	}
+	brotli := func(response *envoy_compressor_v3.Compressor_ResponseDirectionConfig) *http.HttpFilter {
+		return &http.HttpFilter{
+			Name: "compressor_brotli",
+			ConfigType: &http.HttpFilter_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&envoy_compressor_v3.Compressor{
+					CompressorLibrary: &envoy_core_v3.TypedExtensionConfig{
+						Name: "brotli",
+						TypedConfig: &any.Any{
+							TypeUrl: HTTPFilterBrotli,
+						},
+					},
+					ResponseDirectionConfig: response,
+				}),
+			},
+		}
+	}
+
+	minContentLength := uint32(1024)
+
+	tests := map[string]struct {
+		compression config.CompressionParameters
+		want        []*http.HttpFilter
+	}{
+		"default": {
+			compression: config.CompressionParameters{},
+			want:        []*http.HttpFilter{gzip(nil)},
+		},
+		"disabled": {
+			compression: config.CompressionParameters{
+				Disabled:   true,
+				Algorithms: []config.CompressionAlgorithm{config.GzipCompression},
+			},
+			want: nil,
+		},
+		"brotli preferred over gzip": {
+			compression: config.CompressionParameters{
+				Algorithms: []config.CompressionAlgorithm{config.BrotliCompression, config.GzipCompression},
+			},
+			want: []*http.HttpFilter{brotli(nil), gzip(nil)},
+		},
+		"minimum content length and content types": {
+			compression: config.CompressionParameters{
+				MinContentLength: &minContentLength,
+				ContentTypes:     []string{"application/json", "text/html"},
+			},
+			want: []*http.HttpFilter{gzip(&envoy_compressor_v3.Compressor_ResponseDirectionConfig{
+				CommonConfig: &envoy_compressor_v3.Compressor_CommonDirectionConfig{
+					MinContentLength: protobuf.UInt32(1024),
+					ContentType:      []string{"application/json", "text/html"},
+				},
+			})},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			protobuf.ExpectEqual(t, tc.want, compressorFilters(tc.compression))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_config_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+)
+
+// IPFilterConfig returns a per-vhost or per-route config for the
+// HTTP RBAC filter that allows or denies requests matching the
+// supplied IP filter rules.
+func IPFilterConfig(allow bool, rules []dag.IPFilterRule) *any.Any {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	action := envoy_config_rbac_v3.RBAC_DENY
+	if allow {
+		action = envoy_config_rbac_v3.RBAC_ALLOW
+	}
+
+	var principals []*envoy_config_rbac_v3.Principal
+	for _, rule := range rules {
+		prefixLen, _ := rule.CIDR.Mask.Size()
+		cidr := &envoy_core_v3.CidrRange{
+			AddressPrefix: rule.CIDR.IP.String(),
+			PrefixLen:     protobuf.UInt32(uint32(prefixLen)),
+		}
+
+		if rule.Remote {
+			principals = append(principals, &envoy_config_rbac_v3.Principal{
+				Identifier: &envoy_config_rbac_v3.Principal_RemoteIp{RemoteIp: cidr},
+			})
+		} else {
+			principals = append(principals, &envoy_config_rbac_v3.Principal{
+				Identifier: &envoy_config_rbac_v3.Principal_DirectRemoteIp{DirectRemoteIp: cidr},
+			})
+		}
+	}
+
+	return protobuf.MustMarshalAny(&envoy_rbac_v3.RBACPerRoute{
+		Rbac: &envoy_rbac_v3.RBAC{
+			Rules: &envoy_config_rbac_v3.RBAC{
+				Action: action,
+				Policies: map[string]*envoy_config_rbac_v3.Policy{
+					"ip-rules": {
+						Permissions: []*envoy_config_rbac_v3.Permission{{
+							Rule: &envoy_config_rbac_v3.Permission_Any{Any: true},
+						}},
+						Principals: principals,
+					},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"net"
+	"testing"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_config_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/protobuf"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestIPFilterConfig(t *testing.T) {
+	cidr := func(s string) net.IPNet {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *n
+	}
+
+	tests := map[string]struct {
+		allow bool
+		rules []dag.IPFilterRule
+		want  *anypb.Any
+	}{
+		"no rules": {
+			allow: true,
+			rules: nil,
+			want:  nil,
+		},
+		"allow remote and peer": {
+			allow: true,
+			rules: []dag.IPFilterRule{{
+				Remote: true,
+				CIDR:   cidr("10.8.0.0/16"),
+			}, {
+				Remote: false,
+				CIDR:   cidr("2001:db8::/32"),
+			}},
+			want: protobuf.MustMarshalAny(&envoy_rbac_v3.RBACPerRoute{
+				Rbac: &envoy_rbac_v3.RBAC{
+					Rules: &envoy_config_rbac_v3.RBAC{
+						Action: envoy_config_rbac_v3.RBAC_ALLOW,
+						Policies: map[string]*envoy_config_rbac_v3.Policy{
+							"ip-rules": {
+								Permissions: []*envoy_config_rbac_v3.Permission{{
+									Rule: &envoy_config_rbac_v3.Permission_Any{Any: true},
+								}},
+								Principals: []*envoy_config_rbac_v3.Principal{{
+									Identifier: &envoy_config_rbac_v3.Principal_RemoteIp{
+										RemoteIp: &envoy_core_v3.CidrRange{
+											AddressPrefix: "10.8.0.0",
+											PrefixLen:     protobuf.UInt32(16),
+										},
+									},
+								}, {
+									Identifier: &envoy_config_rbac_v3.Principal_DirectRemoteIp{
+										DirectRemoteIp: &envoy_core_v3.CidrRange{
+											AddressPrefix: "2001:db8::",
+											PrefixLen:     protobuf.UInt32(32),
+										},
+									},
+								}},
+							},
+						},
+					},
+				},
+			}),
+		},
+		"deny": {
+			allow: false,
+			rules: []dag.IPFilterRule{{
+				Remote: true,
+				CIDR:   cidr("192.168.1.1/32"),
+			}},
+			want: protobuf.MustMarshalAny(&envoy_rbac_v3.RBACPerRoute{
+				Rbac: &envoy_rbac_v3.RBAC{
+					Rules: &envoy_config_rbac_v3.RBAC{
+						Action: envoy_config_rbac_v3.RBAC_DENY,
+						Policies: map[string]*envoy_config_rbac_v3.Policy{
+							"ip-rules": {
+								Permissions: []*envoy_config_rbac_v3.Permission{{
+									Rule: &envoy_config_rbac_v3.Permission_Any{Any: true},
+								}},
+								Principals: []*envoy_config_rbac_v3.Principal{{
+									Identifier: &envoy_config_rbac_v3.Principal_RemoteIp{
+										RemoteIp: &envoy_core_v3.CidrRange{
+											AddressPrefix: "192.168.1.1",
+											PrefixLen:     protobuf.UInt32(32),
+										},
+									},
+								}},
+							},
+						},
+					},
+				},
+			}),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := IPFilterConfig(tc.allow, tc.rules)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -15,6 +15,7 @@ package v3
 
 import (
 	"fmt"
+	"net/http"
 	"regexp"
 	"sort"
 	"strings"
@@ -23,6 +24,7 @@ import (
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/golang/protobuf/ptypes/any"
 	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/projectcontour/contour/pkg/dag"
@@ -68,7 +70,8 @@ func RouteMatch(route *dag.Route) *envoy_route_v3.RouteMatch {
 			PathSpecifier: &envoy_route_v3.RouteMatch_SafeRegex{
 				SafeRegex: SafeRegexMatch(c.Regex),
 			},
-			Headers: headerMatcher(route.HeaderMatchConditions),
+			Headers:         headerMatcher(route.HeaderMatchConditions),
+			QueryParameters: queryParamMatcher(route.QueryParamMatchConditions),
 		}
 	case *dag.PrefixMatchCondition:
 		switch c.PrefixMatchType {
@@ -77,7 +80,8 @@ func RouteMatch(route *dag.Route) *envoy_route_v3.RouteMatch {
 				PathSpecifier: &envoy_route_v3.RouteMatch_SafeRegex{
 					SafeRegex: SafeRegexMatch(regexp.QuoteMeta(c.Prefix) + prefixPathMatchSegmentRegex),
 				},
-				Headers: headerMatcher(route.HeaderMatchConditions),
+				Headers:         headerMatcher(route.HeaderMatchConditions),
+				QueryParameters: queryParamMatcher(route.QueryParamMatchConditions),
 			}
 		case dag.PrefixMatchString:
 			fallthrough
@@ -86,7 +90,8 @@ func RouteMatch(route *dag.Route) *envoy_route_v3.RouteMatch {
 				PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{
 					Prefix: c.Prefix,
 				},
-				Headers: headerMatcher(route.HeaderMatchConditions),
+				Headers:         headerMatcher(route.HeaderMatchConditions),
+				QueryParameters: queryParamMatcher(route.QueryParamMatchConditions),
 			}
 		}
 	case *dag.ExactMatchCondition:
@@ -94,24 +99,37 @@ func RouteMatch(route *dag.Route) *envoy_route_v3.RouteMatch {
 			PathSpecifier: &envoy_route_v3.RouteMatch_Path{
 				Path: c.Path,
 			},
-			Headers: headerMatcher(route.HeaderMatchConditions),
+			Headers:         headerMatcher(route.HeaderMatchConditions),
+			QueryParameters: queryParamMatcher(route.QueryParamMatchConditions),
 		}
 	default:
 		return &envoy_route_v3.RouteMatch{
-			Headers: headerMatcher(route.HeaderMatchConditions),
+			Headers:         headerMatcher(route.HeaderMatchConditions),
+			QueryParameters: queryParamMatcher(route.QueryParamMatchConditions),
 		}
 	}
 }
 
 // Route_DirectResponse creates a *envoy_route_v3.Route_DirectResponse for the
-// http status code supplied. This allows a direct response to a route request
-// with an HTTP status code without needing to route to a specific cluster.
+// http status code and optional body supplied. This allows a direct response
+// to a route request with an HTTP status code without needing to route to a
+// specific cluster.
 func RouteDirectResponse(response *dag.DirectResponse) *envoy_route_v3.Route_DirectResponse {
-	return &envoy_route_v3.Route_DirectResponse{
+	r := &envoy_route_v3.Route_DirectResponse{
 		DirectResponse: &envoy_route_v3.DirectResponseAction{
 			Status: response.StatusCode,
 		},
 	}
+
+	if len(response.Body) > 0 {
+		r.DirectResponse.Body = &envoy_core_v3.DataSource{
+			Specifier: &envoy_core_v3.DataSource_InlineString{
+				InlineString: response.Body,
+			},
+		}
+	}
+
+	return r
 }
 
 // RouteRoute creates a *envoy_route_v3.Route_Route for the services supplied.
@@ -127,6 +145,13 @@ func RouteRoute(r *dag.Route) *envoy_route_v3.Route_Route {
 		RequestMirrorPolicies: mirrorPolicy(r),
 	}
 
+	if r.RegexRewrite != nil {
+		ra.RegexRewrite = &matcher.RegexMatchAndSubstitute{
+			Pattern:      SafeRegexMatch(r.RegexRewrite.Pattern),
+			Substitution: r.RegexRewrite.Substitution,
+		}
+	}
+
 	if r.RateLimitPolicy != nil && r.RateLimitPolicy.Global != nil {
 		ra.RateLimits = GlobalRateLimits(r.RateLimitPolicy.Global.Descriptors)
 	}
@@ -188,19 +213,48 @@ func hashPolicy(requestHashPolicies []dag.RequestHashPolicy) []*envoy_route_v3.R
 				},
 			}
 		}
+		if rhp.QueryParameterHashOptions != nil {
+			newHP.PolicySpecifier = &envoy_route_v3.RouteAction_HashPolicy_QueryParameter_{
+				QueryParameter: &envoy_route_v3.RouteAction_HashPolicy_QueryParameter{
+					Name: rhp.QueryParameterHashOptions.ParameterName,
+				},
+			}
+		}
+		if rhp.HashSourceIP {
+			newHP.PolicySpecifier = &envoy_route_v3.RouteAction_HashPolicy_ConnectionProperties_{
+				ConnectionProperties: &envoy_route_v3.RouteAction_HashPolicy_ConnectionProperties{
+					SourceIp: true,
+				},
+			}
+		}
 		hashPolicies = append(hashPolicies, newHP)
 	}
 	return hashPolicies
 }
 
 func mirrorPolicy(r *dag.Route) []*envoy_route_v3.RouteAction_RequestMirrorPolicy {
-	if r.MirrorPolicy == nil {
+	if len(r.MirrorPolicies) == 0 {
 		return nil
 	}
 
-	return []*envoy_route_v3.RouteAction_RequestMirrorPolicy{{
-		Cluster: envoy.Clustername(r.MirrorPolicy.Cluster),
-	}}
+	var policies []*envoy_route_v3.RouteAction_RequestMirrorPolicy
+	for _, mp := range r.MirrorPolicies {
+		policy := &envoy_route_v3.RouteAction_RequestMirrorPolicy{
+			Cluster: envoy.Clustername(mp.Cluster),
+		}
+		// Mirroring all requests is Envoy's default, so only
+		// set a runtime fraction for partial mirrors.
+		if mp.Percent > 0 && mp.Percent < 100 {
+			policy.RuntimeFraction = &envoy_core_v3.RuntimeFractionalPercent{
+				DefaultValue: &envoy_type_v3.FractionalPercent{
+					Numerator:   mp.Percent,
+					Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+				},
+			}
+		}
+		policies = append(policies, policy)
+	}
+	return policies
 }
 
 func retryPolicy(r *dag.Route) *envoy_route_v3.RetryPolicy {
@@ -219,10 +273,38 @@ func retryPolicy(r *dag.Route) *envoy_route_v3.RetryPolicy {
 		rp.NumRetries = protobuf.UInt32(r.RetryPolicy.NumRetries)
 	}
 	rp.PerTryTimeout = envoy.Timeout(r.RetryPolicy.PerTryTimeout)
+	rp.RetriableHeaders = headerMatcher(r.RetryPolicy.RetriableHeaders)
+	rp.RetriableRequestHeaders = headerMatcher(r.RetryPolicy.RetriableRequestHeaders)
+
+	if b := r.RetryPolicy.Backoff; b != nil {
+		rp.RetryBackOff = &envoy_route_v3.RetryPolicy_RetryBackOff{
+			BaseInterval: protobuf.Duration(b.BaseInterval),
+		}
+		if b.MaxInterval > 0 {
+			rp.RetryBackOff.MaxInterval = protobuf.Duration(b.MaxInterval)
+		}
+	}
+
+	for _, p := range r.RetryPolicy.HostPredicates {
+		rp.RetryHostPredicate = append(rp.RetryHostPredicate, retryHostPredicate(p))
+	}
+	rp.HostSelectionRetryMaxAttempts = r.RetryPolicy.HostSelectionMaxAttempts
 
 	return rp
 }
 
+// retryHostPredicate returns the Envoy retry host predicate
+// for the given predicate. The predicates have no configuration,
+// so they are referenced by their extension name.
+func retryHostPredicate(p dag.RetryHostPredicate) *envoy_route_v3.RetryPolicy_RetryHostPredicate {
+	switch p {
+	case dag.RetryHostPredicateOmitCanaryHosts:
+		return &envoy_route_v3.RetryPolicy_RetryHostPredicate{Name: "envoy.retry_host_predicates.omit_canary_hosts"}
+	default:
+		return &envoy_route_v3.RetryPolicy_RetryHostPredicate{Name: "envoy.retry_host_predicates.previous_hosts"}
+	}
+}
+
 // UpgradeHTTPS returns a route Action that redirects the request to HTTPS.
 func UpgradeHTTPS() *envoy_route_v3.Route_Redirect {
 	return &envoy_route_v3.Route_Redirect{
@@ -234,6 +316,45 @@ func UpgradeHTTPS() *envoy_route_v3.Route_Redirect {
 	}
 }
 
+// RouteRedirect returns a route Action that redirects the request
+// as described by the supplied *dag.Redirect.
+func RouteRedirect(redirect *dag.Redirect) *envoy_route_v3.Route_Redirect {
+	r := &envoy_route_v3.RedirectAction{
+		HostRedirect: redirect.Hostname,
+		PortRedirect: redirect.PortNumber,
+	}
+
+	if len(redirect.Scheme) > 0 {
+		r.SchemeRewriteSpecifier = &envoy_route_v3.RedirectAction_SchemeRedirect{
+			SchemeRedirect: redirect.Scheme,
+		}
+	}
+
+	switch {
+	case len(redirect.Path) > 0:
+		r.PathRewriteSpecifier = &envoy_route_v3.RedirectAction_PathRedirect{
+			PathRedirect: redirect.Path,
+		}
+	case len(redirect.Prefix) > 0:
+		r.PathRewriteSpecifier = &envoy_route_v3.RedirectAction_PrefixRewrite{
+			PrefixRewrite: redirect.Prefix,
+		}
+	}
+
+	switch redirect.StatusCode {
+	case http.StatusMovedPermanently:
+		r.ResponseCode = envoy_route_v3.RedirectAction_MOVED_PERMANENTLY
+	case http.StatusFound:
+		r.ResponseCode = envoy_route_v3.RedirectAction_FOUND
+	case http.StatusTemporaryRedirect:
+		r.ResponseCode = envoy_route_v3.RedirectAction_TEMPORARY_REDIRECT
+	case http.StatusPermanentRedirect:
+		r.ResponseCode = envoy_route_v3.RedirectAction_PERMANENT_REDIRECT
+	}
+
+	return &envoy_route_v3.Route_Redirect{Redirect: r}
+}
+
 // HeaderValueList creates a list of Envoy HeaderValueOptions from the provided map.
 func HeaderValueList(hvm map[string]string, app bool) []*envoy_core_v3.HeaderValueOption {
 	var hvs []*envoy_core_v3.HeaderValueOption
@@ -307,6 +428,22 @@ func CORSVirtualHost(hostname string, corspolicy *envoy_route_v3.CorsPolicy, rou
 	return vh
 }
 
+// VirtualHostHeaders sets the request and response header manipulations
+// described by the supplied policies on a route.VirtualHost. Envoy
+// evaluates virtual host headers after route and cluster headers, so
+// they take precedence over headers set by a route or service.
+func VirtualHostHeaders(vh *envoy_route_v3.VirtualHost, request, response *dag.HeadersPolicy) *envoy_route_v3.VirtualHost {
+	if request != nil {
+		vh.RequestHeadersToAdd = append(HeaderValueList(request.Set, false), HeaderValueList(request.Add, true)...)
+		vh.RequestHeadersToRemove = request.Remove
+	}
+	if response != nil {
+		vh.ResponseHeadersToAdd = HeaderValueList(response.Set, false)
+		vh.ResponseHeadersToRemove = response.Remove
+	}
+	return vh
+}
+
 // RouteConfiguration returns a *envoy_route_v3.RouteConfiguration.
 func RouteConfiguration(name string, virtualhosts ...*envoy_route_v3.VirtualHost) *envoy_route_v3.RouteConfiguration {
 	return &envoy_route_v3.RouteConfiguration{
@@ -378,6 +515,10 @@ func headerMatcher(headers []dag.HeaderMatchCondition) []*envoy_route_v3.HeaderM
 			header.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_ExactMatch{ExactMatch: h.Value}
 		case dag.HeaderMatchTypeContains:
 			header.HeaderMatchSpecifier = containsMatch(h.Value)
+		case dag.HeaderMatchTypeRegex:
+			header.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_SafeRegexMatch{
+				SafeRegexMatch: SafeRegexMatch(h.Value),
+			}
 		case dag.HeaderMatchTypePresent:
 			header.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_PresentMatch{PresentMatch: true}
 		}
@@ -386,6 +527,39 @@ func headerMatcher(headers []dag.HeaderMatchCondition) []*envoy_route_v3.HeaderM
 	return envoyHeaders
 }
 
+func queryParamMatcher(queryParams []dag.QueryParamMatchCondition) []*envoy_route_v3.QueryParameterMatcher {
+	var envoyQueryParams []*envoy_route_v3.QueryParameterMatcher
+
+	for _, q := range queryParams {
+		queryParam := &envoy_route_v3.QueryParameterMatcher{
+			Name: q.Name,
+		}
+
+		switch q.MatchType {
+		case dag.QueryParamMatchTypeExact:
+			queryParam.QueryParameterMatchSpecifier = stringMatch(&matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_Exact{Exact: q.Value},
+			})
+		case dag.QueryParamMatchTypePrefix:
+			queryParam.QueryParameterMatchSpecifier = stringMatch(&matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_Prefix{Prefix: q.Value},
+			})
+		case dag.QueryParamMatchTypeRegex:
+			queryParam.QueryParameterMatchSpecifier = stringMatch(&matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_SafeRegex{SafeRegex: SafeRegexMatch(q.Value)},
+			})
+		case dag.QueryParamMatchTypePresent:
+			queryParam.QueryParameterMatchSpecifier = &envoy_route_v3.QueryParameterMatcher_PresentMatch{PresentMatch: true}
+		}
+		envoyQueryParams = append(envoyQueryParams, queryParam)
+	}
+	return envoyQueryParams
+}
+
+func stringMatch(m *matcher.StringMatcher) *envoy_route_v3.QueryParameterMatcher_StringMatch {
+	return &envoy_route_v3.QueryParameterMatcher_StringMatch{StringMatch: m}
+}
+
 // containsMatch returns a HeaderMatchSpecifier which will match the
 // supplied substring
 func containsMatch(s string) *envoy_route_v3.HeaderMatcher_SafeRegexMatch {
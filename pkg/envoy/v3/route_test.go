@@ -20,6 +20,7 @@ import (
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/projectcontour/contour/pkg/dag"
 	"github.com/projectcontour/contour/pkg/fixture"
@@ -83,6 +84,26 @@ func TestRouteRoute(t *testing.T) {
 				},
 			},
 		},
+		"regex rewrite": {
+			route: &dag.Route{
+				Clusters: []*dag.Cluster{c1},
+				RegexRewrite: &dag.RegexRewrite{
+					Pattern:      "^/v1/(.*)$",
+					Substitution: "/v2/\\1",
+				},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RegexRewrite: &matcher.RegexMatchAndSubstitute{
+						Pattern:      SafeRegexMatch("^/v1/(.*)$"),
+						Substitution: "/v2/\\1",
+					},
+				},
+			},
+		},
 		"websocket": {
 			route: &dag.Route{
 				Websocket: true,
@@ -320,6 +341,62 @@ func TestRouteRoute(t *testing.T) {
 				},
 			},
 		},
+		"retry backoff, host predicates and retriable headers": {
+			route: &dag.Route{
+				RetryPolicy: &dag.RetryPolicy{
+					RetryOn:    "retriable-headers",
+					NumRetries: 3,
+					RetriableHeaders: []dag.HeaderMatchCondition{{
+						Name:      "x-upstream-retry",
+						Value:     "true",
+						MatchType: dag.HeaderMatchTypeExact,
+					}},
+					RetriableRequestHeaders: []dag.HeaderMatchCondition{{
+						Name:      ":method",
+						Value:     "GET",
+						MatchType: dag.HeaderMatchTypeExact,
+					}},
+					Backoff: &dag.RetryBackoff{
+						BaseInterval: 50 * time.Millisecond,
+						MaxInterval:  time.Second,
+					},
+					HostPredicates: []dag.RetryHostPredicate{
+						dag.RetryHostPredicatePreviousHosts,
+						dag.RetryHostPredicateOmitCanaryHosts,
+					},
+					HostSelectionMaxAttempts: 5,
+				},
+				Clusters: []*dag.Cluster{c1},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RetryPolicy: &envoy_route_v3.RetryPolicy{
+						RetryOn:    "retriable-headers",
+						NumRetries: protobuf.UInt32(3),
+						RetriableHeaders: []*envoy_route_v3.HeaderMatcher{{
+							Name:                 "x-upstream-retry",
+							HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_ExactMatch{ExactMatch: "true"},
+						}},
+						RetriableRequestHeaders: []*envoy_route_v3.HeaderMatcher{{
+							Name:                 ":method",
+							HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_ExactMatch{ExactMatch: "GET"},
+						}},
+						RetryBackOff: &envoy_route_v3.RetryPolicy_RetryBackOff{
+							BaseInterval: protobuf.Duration(50 * time.Millisecond),
+							MaxInterval:  protobuf.Duration(time.Second),
+						},
+						RetryHostPredicate: []*envoy_route_v3.RetryPolicy_RetryHostPredicate{
+							{Name: "envoy.retry_host_predicates.previous_hosts"},
+							{Name: "envoy.retry_host_predicates.omit_canary_hosts"},
+						},
+						HostSelectionRetryMaxAttempts: 5,
+					},
+				},
+			},
+		},
 		"timeout 90s": {
 			route: &dag.Route{
 				TimeoutPolicy: dag.TimeoutPolicy{
@@ -491,6 +568,46 @@ func TestRouteRoute(t *testing.T) {
 				},
 			},
 		},
+		"single service w/ source ip and query parameter hashing": {
+			route: &dag.Route{
+				Clusters: []*dag.Cluster{c3},
+				RequestHashPolicies: []dag.RequestHashPolicy{
+					{
+						QueryParameterHashOptions: &dag.QueryParameterHashOptions{
+							ParameterName: "tenant",
+						},
+					},
+					{
+						Terminal:     true,
+						HashSourceIP: true,
+					},
+				},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/1a2ffc1fef",
+					},
+					HashPolicy: []*envoy_route_v3.RouteAction_HashPolicy{
+						{
+							PolicySpecifier: &envoy_route_v3.RouteAction_HashPolicy_QueryParameter_{
+								QueryParameter: &envoy_route_v3.RouteAction_HashPolicy_QueryParameter{
+									Name: "tenant",
+								},
+							},
+						},
+						{
+							Terminal: true,
+							PolicySpecifier: &envoy_route_v3.RouteAction_HashPolicy_ConnectionProperties_{
+								ConnectionProperties: &envoy_route_v3.RouteAction_HashPolicy_ConnectionProperties{
+									SourceIp: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		"host header rewrite": {
 			route: &dag.Route{
 				RequestHeadersPolicy: &dag.HeadersPolicy{
@@ -520,7 +637,7 @@ func TestRouteRoute(t *testing.T) {
 					},
 					Weight: 90,
 				}},
-				MirrorPolicy: &dag.MirrorPolicy{
+				MirrorPolicies: []*dag.MirrorPolicy{{
 					Cluster: &dag.Cluster{
 						Upstream: &dag.Service{
 							Weighted: dag.WeightedService{
@@ -531,7 +648,8 @@ func TestRouteRoute(t *testing.T) {
 							},
 						},
 					},
-				},
+					Percent: 100,
+				}},
 			},
 			want: &envoy_route_v3.Route_Route{
 				Route: &envoy_route_v3.RouteAction{
@@ -544,6 +662,63 @@ func TestRouteRoute(t *testing.T) {
 				},
 			},
 		},
+		"multiple partial mirrors": {
+			route: &dag.Route{
+				Clusters: []*dag.Cluster{{
+					Upstream: &dag.Service{
+						Weighted: dag.WeightedService{
+							Weight:           1,
+							ServiceName:      s1.Name,
+							ServiceNamespace: s1.Namespace,
+							ServicePort:      s1.Spec.Ports[0],
+						},
+					},
+				}},
+				MirrorPolicies: []*dag.MirrorPolicy{{
+					Cluster: &dag.Cluster{
+						Upstream: &dag.Service{
+							Weighted: dag.WeightedService{
+								Weight:           1,
+								ServiceName:      "mirror1",
+								ServiceNamespace: s1.Namespace,
+								ServicePort:      s1.Spec.Ports[0],
+							},
+						},
+					},
+					Percent: 5,
+				}, {
+					Cluster: &dag.Cluster{
+						Upstream: &dag.Service{
+							Weighted: dag.WeightedService{
+								Weight:           1,
+								ServiceName:      "mirror2",
+								ServiceNamespace: s1.Namespace,
+								ServicePort:      s1.Spec.Ports[0],
+							},
+						},
+					},
+					Percent: 100,
+				}},
+			},
+			want: &envoy_route_v3.Route_Route{
+				Route: &envoy_route_v3.RouteAction{
+					ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RequestMirrorPolicies: []*envoy_route_v3.RouteAction_RequestMirrorPolicy{{
+						Cluster: "default/mirror1/8080/da39a3ee5e",
+						RuntimeFraction: &envoy_core_v3.RuntimeFractionalPercent{
+							DefaultValue: &envoy_type_v3.FractionalPercent{
+								Numerator:   5,
+								Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+							},
+						},
+					}, {
+						Cluster: "default/mirror2/8080/da39a3ee5e",
+					}},
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -575,6 +750,19 @@ func TestRouteDirectResponse(t *testing.T) {
 				},
 			},
 		},
+		"503 with body": {
+			directResponse: &dag.DirectResponse{StatusCode: 503, Body: "down for maintenance"},
+			want: &envoy_route_v3.Route_DirectResponse{
+				DirectResponse: &envoy_route_v3.DirectResponseAction{
+					Status: 503,
+					Body: &envoy_core_v3.DataSource{
+						Specifier: &envoy_core_v3.DataSource_InlineString{
+							InlineString: "down for maintenance",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -853,6 +1041,58 @@ func TestCORSVirtualHost(t *testing.T) {
 	}
 }
 
+func TestVirtualHostHeaders(t *testing.T) {
+	tests := map[string]struct {
+		request  *dag.HeadersPolicy
+		response *dag.HeadersPolicy
+		want     *envoy_route_v3.VirtualHost
+	}{
+		"nil headers policies": {
+			want: &envoy_route_v3.VirtualHost{
+				Name:    "www.example.com",
+				Domains: []string{"www.example.com"},
+			},
+		},
+		"request and response headers policies": {
+			request: &dag.HeadersPolicy{
+				Set:    map[string]string{"X-Foo": "bar"},
+				Remove: []string{"X-Internal"},
+			},
+			response: &dag.HeadersPolicy{
+				Set:    map[string]string{"Strict-Transport-Security": "max-age=31536000"},
+				Remove: []string{"Server"},
+			},
+			want: &envoy_route_v3.VirtualHost{
+				Name:    "www.example.com",
+				Domains: []string{"www.example.com"},
+				RequestHeadersToAdd: []*envoy_core_v3.HeaderValueOption{{
+					Header: &envoy_core_v3.HeaderValue{
+						Key:   "X-Foo",
+						Value: "bar",
+					},
+					Append: protobuf.Bool(false),
+				}},
+				RequestHeadersToRemove: []string{"X-Internal"},
+				ResponseHeadersToAdd: []*envoy_core_v3.HeaderValueOption{{
+					Header: &envoy_core_v3.HeaderValue{
+						Key:   "Strict-Transport-Security",
+						Value: "max-age=31536000",
+					},
+					Append: protobuf.Bool(false),
+				}},
+				ResponseHeadersToRemove: []string{"Server"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := VirtualHostHeaders(VirtualHost("www.example.com"), tc.request, tc.response)
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
+
 func TestCORSPolicy(t *testing.T) {
 	tests := map[string]struct {
 		cp   *dag.CORSPolicy
@@ -1071,6 +1311,25 @@ func TestRouteMatch(t *testing.T) {
 				}},
 			},
 		},
+		"regex match": {
+			route: &dag.Route{
+				HeaderMatchConditions: []dag.HeaderMatchCondition{{
+					Name:      "x-header",
+					Value:     "11.[22].*33.44",
+					MatchType: "regex",
+					Invert:    true,
+				}},
+			},
+			want: &envoy_route_v3.RouteMatch{
+				Headers: []*envoy_route_v3.HeaderMatcher{{
+					Name:        "x-header",
+					InvertMatch: true,
+					HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_SafeRegexMatch{
+						SafeRegexMatch: SafeRegexMatch("11.[22].*33.44"),
+					},
+				}},
+			},
+		},
 		"path prefix string prefix": {
 			route: &dag.Route{
 				PathMatchCondition: &dag.PrefixMatchCondition{
@@ -1137,6 +1396,61 @@ func TestRouteMatch(t *testing.T) {
 				},
 			},
 		},
+		"query parameter matches": {
+			route: &dag.Route{
+				PathMatchCondition: &dag.PrefixMatchCondition{
+					Prefix: "/foo",
+				},
+				QueryParamMatchConditions: []dag.QueryParamMatchCondition{{
+					Name:      "exact",
+					Value:     "v1",
+					MatchType: dag.QueryParamMatchTypeExact,
+				}, {
+					Name:      "prefix",
+					Value:     "v",
+					MatchType: dag.QueryParamMatchTypePrefix,
+				}, {
+					Name:      "regex",
+					Value:     "v[0-9]+",
+					MatchType: dag.QueryParamMatchTypeRegex,
+				}, {
+					Name:      "present",
+					MatchType: dag.QueryParamMatchTypePresent,
+				}},
+			},
+			want: &envoy_route_v3.RouteMatch{
+				PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{
+					Prefix: "/foo",
+				},
+				QueryParameters: []*envoy_route_v3.QueryParameterMatcher{{
+					Name: "exact",
+					QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_Exact{Exact: "v1"},
+						},
+					},
+				}, {
+					Name: "prefix",
+					QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_Prefix{Prefix: "v"},
+						},
+					},
+				}, {
+					Name: "regex",
+					QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_SafeRegex{SafeRegex: SafeRegexMatch("v[0-9]+")},
+						},
+					},
+				}, {
+					Name: "present",
+					QueryParameterMatchSpecifier: &envoy_route_v3.QueryParameterMatcher_PresentMatch{
+						PresentMatch: true,
+					},
+				}},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -1147,4 +1461,77 @@ func TestRouteMatch(t *testing.T) {
 	}
 }
 
+func TestRouteRedirect(t *testing.T) {
+	tests := map[string]struct {
+		redirect *dag.Redirect
+		want     *envoy_route_v3.Route_Redirect
+	}{
+		"hostname and status code": {
+			redirect: &dag.Redirect{
+				Hostname:   "envoyproxy.io",
+				StatusCode: 302,
+			},
+			want: &envoy_route_v3.Route_Redirect{
+				Redirect: &envoy_route_v3.RedirectAction{
+					HostRedirect: "envoyproxy.io",
+					ResponseCode: envoy_route_v3.RedirectAction_FOUND,
+				},
+			},
+		},
+		"scheme, port and path": {
+			redirect: &dag.Redirect{
+				Scheme:     "https",
+				PortNumber: 8443,
+				Path:       "/new",
+				StatusCode: 308,
+			},
+			want: &envoy_route_v3.Route_Redirect{
+				Redirect: &envoy_route_v3.RedirectAction{
+					SchemeRewriteSpecifier: &envoy_route_v3.RedirectAction_SchemeRedirect{
+						SchemeRedirect: "https",
+					},
+					PortRedirect: 8443,
+					PathRewriteSpecifier: &envoy_route_v3.RedirectAction_PathRedirect{
+						PathRedirect: "/new",
+					},
+					ResponseCode: envoy_route_v3.RedirectAction_PERMANENT_REDIRECT,
+				},
+			},
+		},
+		"prefix": {
+			redirect: &dag.Redirect{
+				Prefix:     "/blog",
+				StatusCode: 307,
+			},
+			want: &envoy_route_v3.Route_Redirect{
+				Redirect: &envoy_route_v3.RedirectAction{
+					PathRewriteSpecifier: &envoy_route_v3.RedirectAction_PrefixRewrite{
+						PrefixRewrite: "/blog",
+					},
+					ResponseCode: envoy_route_v3.RedirectAction_TEMPORARY_REDIRECT,
+				},
+			},
+		},
+		"moved permanently": {
+			redirect: &dag.Redirect{
+				Hostname:   "envoyproxy.io",
+				StatusCode: 301,
+			},
+			want: &envoy_route_v3.Route_Redirect{
+				Redirect: &envoy_route_v3.RedirectAction{
+					HostRedirect: "envoyproxy.io",
+					ResponseCode: envoy_route_v3.RedirectAction_MOVED_PERMANENTLY,
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := RouteRedirect(tc.redirect)
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
+
 func virtualhosts(v ...*envoy_route_v3.VirtualHost) []*envoy_route_v3.VirtualHost { return v }
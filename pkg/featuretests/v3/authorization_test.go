@@ -22,16 +22,21 @@ import (
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/featuretests"
 	"github.com/projectcontour/contour/internal/fixture"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
 )
 
 const defaultResponseTimeout = time.Minute * 60
@@ -224,6 +229,47 @@ func authzFallbackIncompat(t *testing.T, rh cache.ResourceEventHandler, c *Conto
 	}).Status(p).HasError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures", "Spec.Virtualhost.TLS fallback & client authorization are incompatible")
 }
 
+func authzBasicAuthDisabled(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	rh.OnAdd(&corev1.Secret{
+		ObjectMeta: fixture.ObjectMeta("htpasswd"),
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			dag.BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="),
+		},
+	})
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN("echo.projectcontour.io").
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "extension",
+			},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				AuthPolicy: &contour_api_v1.AuthorizationPolicy{
+					Disabled: true,
+				},
+				BasicAuth: &contour_api_v1.BasicAuthPolicy{
+					SecretName: "htpasswd",
+				},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	// Disabling the authorization server of a route would
+	// disable basic authentication too.
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl:   listenerType,
+		Resources: resources(t, staticListener()),
+	}).Status(p).HasError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+		"route.basicAuth cannot be combined with a disabled authPolicy; disable route.basicAuth or enable route.authPolicy")
+}
+
 func authzOverrideDisabled(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
 	const enabled = "enabled.projectcontour.io"
 	const disabled = "disabled.projectcontour.io"
@@ -340,6 +386,93 @@ func authzOverrideDisabled(t *testing.T, rh cache.ResourceEventHandler, c *Conto
 	})
 }
 
+func authzRedirectRouteOverride(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "echo.projectcontour.io"
+
+	rh.OnAdd(fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "extension",
+			},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/old")),
+				RequestRedirectPolicy: &contour_api_v1.HTTPRequestRedirectPolicy{
+					Hostname: pointer.StringPtr("envoyproxy.io"),
+				},
+				AuthPolicy: &contour_api_v1.AuthorizationPolicy{Disabled: true},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/healthz")),
+				DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+					StatusCode: 200,
+					Body:       "ok",
+				},
+				AuthPolicy: &contour_api_v1.AuthorizationPolicy{
+					Context: map[string]string{"route": "healthz"},
+				},
+			}},
+		}),
+	)
+
+	// Authorization policy overrides apply to redirect and
+	// direct response routes just like forwarding routes.
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration(
+				path.Join("https", fqdn),
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match: routePrefix("/old"),
+						Action: envoy_v3.RouteRedirect(&dag.Redirect{
+							Hostname:   "envoyproxy.io",
+							StatusCode: 302,
+						}),
+						TypedPerFilterConfig: withFilterConfig("envoy.filters.http.ext_authz",
+							&envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute{
+								Override: &envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute_Disabled{
+									Disabled: true,
+								},
+							}),
+					},
+					&envoy_route_v3.Route{
+						Match: routePrefix("/healthz"),
+						Action: envoy_v3.RouteDirectResponse(&dag.DirectResponse{
+							StatusCode: 200,
+							Body:       "ok",
+						}),
+						TypedPerFilterConfig: withFilterConfig("envoy.filters.http.ext_authz",
+							&envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute{
+								Override: &envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute_CheckSettings{
+									CheckSettings: &envoy_config_filter_http_ext_authz_v3.CheckSettings{
+										ContextExtensions: map[string]string{"route": "healthz"},
+									},
+								},
+							}),
+					},
+				),
+			),
+			envoy_v3.RouteConfiguration(
+				"ingress_http",
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/old"),
+						Action: withRedirect(),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/healthz"),
+						Action: withRedirect(),
+					},
+				),
+			),
+		),
+	})
+}
+
 func authzMergeRouteContext(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
 	const fqdn = "echo.projectcontour.io"
 
@@ -513,15 +646,367 @@ func authzInvalidReference(t *testing.T, rh cache.ResourceEventHandler, c *Conto
 	}).Status(invalid).IsValid()
 }
 
+func authzRouteOverride(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "echo.projectcontour.io"
+
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("auth/opa"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "oidc-server", Port: 8081},
+			},
+		},
+	})
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "extension",
+			},
+			AuthPolicy: &contour_api_v1.AuthorizationPolicy{
+				Context: map[string]string{
+					"root-element": "root",
+				},
+			},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/api")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				Authorization: &contour_api_v1.AuthorizationServer{
+					ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+						Namespace: "auth",
+						Name:      "opa",
+					},
+					ResponseTimeout: "5s",
+				},
+				AuthPolicy: &contour_api_v1.AuthorizationPolicy{
+					Context: map[string]string{
+						"api-element": "api",
+					},
+				},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	vhostAuthz := &dag.ExternalAuthorization{
+		AuthorizationService:         &dag.ExtensionCluster{Name: "extension/auth/extension"},
+		AuthorizationResponseTimeout: timeout.DurationSetting(defaultResponseTimeout),
+		IncludeClientCertificate:     true,
+	}
+	routeAuthz := &dag.ExternalAuthorization{
+		AuthorizationService:         &dag.ExtensionCluster{Name: "extension/auth/opa"},
+		AuthorizationResponseTimeout: timeout.DurationSetting(5 * time.Second),
+		IncludeClientCertificate:     true,
+	}
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls(fqdn,
+						&corev1.Secret{
+							ObjectMeta: fixture.ObjectMeta("certificate"),
+							Type:       "kubernetes.io/tls",
+							Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+						},
+						envoy_v3.HTTPConnectionManagerBuilder().
+							AddFilter(envoy_v3.FilterMisdirectedRequests(fqdn)).
+							DefaultFilters().
+							AddFilters(envoy_v3.FilterExternalAuthzServers(vhostAuthz, []*dag.ExternalAuthorization{routeAuthz})...).
+							RouteConfigName(path.Join("https", fqdn)).
+							MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+							AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+							Get(),
+						nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	}).Status(p).IsValid()
+
+	// The route that overrides the authorization server
+	// does not inherit the context of the virtual host.
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration(
+				path.Join("https", fqdn),
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match:    routePrefix("/api"),
+						Action:   routeCluster("default/app-server/80/da39a3ee5e"),
+						Metadata: envoy_v3.RouteExternalAuthorization(nil, routeAuthz),
+						TypedPerFilterConfig: withFilterConfig("envoy.filters.http.ext_authz",
+							&envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute{
+								Override: &envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute_CheckSettings{
+									CheckSettings: &envoy_config_filter_http_ext_authz_v3.CheckSettings{
+										ContextExtensions: map[string]string{"api-element": "api"},
+									},
+								},
+							}),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/app-server/80/da39a3ee5e"),
+						TypedPerFilterConfig: withFilterConfig("envoy.filters.http.ext_authz",
+							&envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute{
+								Override: &envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute_CheckSettings{
+									CheckSettings: &envoy_config_filter_http_ext_authz_v3.CheckSettings{
+										ContextExtensions: map[string]string{"root-element": "root"},
+									},
+								},
+							}),
+					},
+				),
+			),
+			envoy_v3.RouteConfiguration(
+				"ingress_http",
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/api"),
+						Action: withRedirect(),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: withRedirect(),
+					},
+				),
+			),
+		),
+	}).Status(p).IsValid()
+
+	invalid := p.DeepCopy()
+	invalid.Spec.Routes[1].Authorization.ExtensionServiceRef.Name = "missing"
+	rh.OnUpdate(p, invalid)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl:   listenerType,
+		Resources: resources(t, staticListener()),
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeAuthError, "ExtensionServiceNotFound", `route.authorization.ServiceRef extension service "auth/missing" not found`)
+}
+
+func authzRequestOptions(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "echo.projectcontour.io"
+
+	includeCertificate := false
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "extension",
+			},
+			WithRequestBody: &contour_api_v1.AuthorizationServerBufferSettings{
+				MaxRequestBytes: 8192,
+				PackAsBytes:     true,
+			},
+			StatusOnDeny:             401,
+			IncludeClientCertificate: &includeCertificate,
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	authz := &dag.ExternalAuthorization{
+		AuthorizationService:         &dag.ExtensionCluster{Name: "extension/auth/extension"},
+		AuthorizationResponseTimeout: timeout.DurationSetting(defaultResponseTimeout),
+		WithRequestBody: &dag.AuthorizationBufferSettings{
+			MaxRequestBytes: 8192,
+			PackAsBytes:     true,
+		},
+		StatusOnDeny: 401,
+	}
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls(fqdn,
+						&corev1.Secret{
+							ObjectMeta: fixture.ObjectMeta("certificate"),
+							Type:       "kubernetes.io/tls",
+							Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+						},
+						envoy_v3.HTTPConnectionManagerBuilder().
+							AddFilter(envoy_v3.FilterMisdirectedRequests(fqdn)).
+							DefaultFilters().
+							AddFilter(&http.HttpFilter{
+								Name: "envoy.filters.http.ext_authz",
+								ConfigType: &http.HttpFilter_TypedConfig{
+									TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_http_ext_authz_v3.ExtAuthz{
+										Services:        grpcCluster("extension/auth/extension"),
+										ClearRouteCache: true,
+										WithRequestBody: &envoy_config_filter_http_ext_authz_v3.BufferSettings{
+											MaxRequestBytes: 8192,
+											PackAsBytes:     true,
+										},
+										// Errors have their own status, so that they can be
+										// told apart from denials by the local reply config.
+										StatusOnError: &envoy_type.HttpStatus{
+											Code: envoy_type.StatusCode_NetworkAuthenticationRequired,
+										},
+										TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+									}),
+								},
+							}).
+							LocalReplyConfig(envoy_v3.ExternalAuthzLocalReplyConfig(authz, nil)).
+							RouteConfigName(path.Join("https", fqdn)).
+							MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+							AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+							Get(),
+						nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	}).Status(p).IsValid()
+
+	// The gRPC check protocol can't restrict headers.
+	invalid := p.DeepCopy()
+	invalid.Spec.VirtualHost.Authorization.AllowedRequestHeaders = []string{"Authorization"}
+	rh.OnUpdate(p, invalid)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl:   listenerType,
+		Resources: resources(t, staticListener()),
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeAuthError, "AuthAllowedHeadersNotSupported", `Spec.Virtualhost.Authorization.allowedRequestHeaders is not supported by gRPC authorization servers`)
+}
+
+func authzHTTPService(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "echo.projectcontour.io"
+
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("auth/forward"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "oidc-server", Port: 8081},
+			},
+			Protocol: pointer.StringPtr("http/1.1"),
+		},
+	})
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "forward",
+			},
+			HTTPService: &contour_api_v1.AuthorizationHTTPService{
+				PathPrefix: "/verify",
+			},
+			AllowedRequestHeaders:  []string{"Cookie"},
+			AllowedUpstreamHeaders: []string{"X-Auth-User"},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	authz := &dag.ExternalAuthorization{
+		AuthorizationService: &dag.ExtensionCluster{
+			Name:     "extension/auth/forward",
+			Protocol: "http/1.1",
+		},
+		HTTPService: &dag.AuthorizationHTTPService{
+			PathPrefix: "/verify",
+		},
+		AllowedRequestHeaders:    []string{"Cookie"},
+		AllowedUpstreamHeaders:   []string{"X-Auth-User"},
+		IncludeClientCertificate: true,
+	}
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls(fqdn,
+						&corev1.Secret{
+							ObjectMeta: fixture.ObjectMeta("certificate"),
+							Type:       "kubernetes.io/tls",
+							Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+						},
+						envoy_v3.HTTPConnectionManagerBuilder().
+							AddFilter(envoy_v3.FilterMisdirectedRequests(fqdn)).
+							DefaultFilters().
+							AddFilter(envoy_v3.FilterExternalAuthz(authz)).
+							RouteConfigName(path.Join("https", fqdn)).
+							MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+							AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+							Get(),
+						nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	}).Status(p).IsValid()
+
+	// An http/1.1 extension service can't be used with the gRPC check protocol.
+	invalid := p.DeepCopy()
+	invalid.Spec.VirtualHost.Authorization.HTTPService = nil
+	invalid.Spec.VirtualHost.Authorization.AllowedRequestHeaders = nil
+	invalid.Spec.VirtualHost.Authorization.AllowedUpstreamHeaders = nil
+	rh.OnUpdate(p, invalid)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl:   listenerType,
+		Resources: resources(t, staticListener()),
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeAuthError, "AuthProtocolNotSupported", `Spec.Virtualhost.Authorization.ServiceRef extension service "auth/forward" uses the http/1.1 protocol, which requires an httpService`)
+}
+
 func TestAuthorization(t *testing.T) {
 	subtests := map[string]func(*testing.T, cache.ResourceEventHandler, *Contour){
 		"MissingExtension":       authzInvalidReference,
 		"MergeRouteContext":      authzMergeRouteContext,
 		"OverrideDisabled":       authzOverrideDisabled,
+		"RedirectRouteOverride":  authzRedirectRouteOverride,
 		"FallbackIncompat":       authzFallbackIncompat,
+		"BasicAuthDisabled":      authzBasicAuthDisabled,
 		"FailOpen":               authzFailOpen,
 		"ResponseTimeout":        authzResponseTimeout,
 		"InvalidResponseTimeout": authzInvalidResponseTimeout,
+		"RouteOverride":          authzRouteOverride,
+		"RequestOptions":         authzRequestOptions,
+		"HTTPService":            authzHTTPService,
 	}
 
 	for n, f := range subtests {
@@ -0,0 +1,177 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"path"
+	"testing"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestBasicAuth(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	const fqdn = "basic.example.com"
+
+	cert := &v1.Secret{
+		ObjectMeta: fixture.ObjectMeta("certificate"),
+		Type:       "kubernetes.io/tls",
+		Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(cert)
+	rh.OnAdd(&v1.Secret{
+		ObjectMeta: fixture.ObjectMeta("htpasswd"),
+		Type:       v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			dag.BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="),
+		},
+	})
+	rh.OnAdd(fixture.NewService("s1").WithPorts(v1.ServicePort{Port: 80}))
+
+	p := fixture.NewProxy("basic").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services:   []contour_api_v1.Service{{Name: "s1", Port: 80}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/public")),
+				Services:   []contour_api_v1.Service{{Name: "s1", Port: 80}},
+				BasicAuth: &contour_api_v1.BasicAuthPolicy{
+					Disabled: true,
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/admin")),
+				Services:   []contour_api_v1.Service{{Name: "s1", Port: 80}},
+				BasicAuth: &contour_api_v1.BasicAuthPolicy{
+					SecretName: "htpasswd",
+					Realm:      "admin",
+				},
+			}},
+		})
+	p.Spec.VirtualHost.BasicAuth = &contour_api_v1.BasicAuthPolicy{
+		SecretName: "htpasswd",
+	}
+	rh.OnAdd(p)
+
+	httpsListener := func(filters ...*http.HttpFilter) *envoy_listener_v3.Listener {
+		httpsFilter := envoy_v3.HTTPConnectionManagerBuilder().
+			AddFilter(envoy_v3.FilterMisdirectedRequests(fqdn)).
+			DefaultFilters().
+			AddFilters(filters...).
+			RouteConfigName(path.Join("https", fqdn)).
+			MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+			AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+			Get()
+
+		return &envoy_listener_v3.Listener{
+			Name:    "ingress_https",
+			Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+			ListenerFilters: envoy_v3.ListenerFilters(
+				envoy_v3.TLSInspector(),
+			),
+			FilterChains: []*envoy_listener_v3.FilterChain{
+				filterchaintls(fqdn, cert, httpsFilter, nil, "h2", "http/1.1"),
+			},
+			SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+		}
+	}
+
+	// Only the secure listener authenticates requests.
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			httpsListener(envoy_v3.FilterBasicAuth()...),
+			staticListener(),
+		),
+	}).Status(p).IsValid()
+
+	secret := types.NamespacedName{Namespace: "default", Name: "htpasswd"}
+
+	// The routes reference the Secret instead of carrying the
+	// credentials.
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("https/"+fqdn,
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/public"),
+						Action: routeCluster("default/s1/80/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match:    routePrefix("/admin"),
+						Action:   routeCluster("default/s1/80/da39a3ee5e"),
+						Metadata: envoy_v3.RouteBasicAuth(&dag.BasicAuth{Secret: secret, Realm: "admin"}),
+					},
+					&envoy_route_v3.Route{
+						Match:    routePrefix("/"),
+						Action:   routeCluster("default/s1/80/da39a3ee5e"),
+						Metadata: envoy_v3.RouteBasicAuth(&dag.BasicAuth{Secret: secret, Realm: fqdn}),
+					},
+				),
+			),
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost(fqdn,
+					upgradeHTTPS(routePrefix("/public")),
+					upgradeHTTPS(routePrefix("/admin")),
+					upgradeHTTPS(routePrefix("/")),
+				),
+			),
+		),
+	}).Status(p).IsValid()
+
+	// Removing all basic authentication policies removes the filters.
+	unauthenticated := p.DeepCopy()
+	unauthenticated.Spec.VirtualHost.BasicAuth = nil
+	unauthenticated.Spec.Routes = unauthenticated.Spec.Routes[:2]
+	rh.OnUpdate(p, unauthenticated)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			httpsListener(),
+			staticListener(),
+		),
+	}).Status(unauthenticated).IsValid()
+
+	// Basic authentication requires TLS.
+	insecure := p.DeepCopy()
+	insecure.Spec.VirtualHost.TLS = nil
+	rh.OnUpdate(unauthenticated, insecure)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			staticListener(),
+		),
+	}).Status(insecure).HasError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthRequiresTLS",
+		"Spec.VirtualHost.BasicAuth can only be defined for root HTTPProxies that terminate TLS and route HTTP requests")
+}
@@ -473,6 +473,87 @@ func TestClusterCircuitbreakerAnnotations(t *testing.T) {
 	})
 }
 
+func TestClusterCircuitBreakerPolicy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		Annotate("projectcontour.io/max-connections", "9000").
+		Annotate("projectcontour.io/max-retries", "7").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromString("8080")}),
+	)
+
+	rh.OnAdd(&contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "www.example.com"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/a",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 80,
+					CircuitBreakerPolicy: &contour_api_v1.CircuitBreakerPolicy{
+						MaxConnections: 100,
+						MaxRequests:    50,
+					},
+				}},
+			}, {
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/b",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 80,
+				}},
+			}},
+		},
+	})
+
+	// The policy overrides the annotations for the first route
+	// only, so two clusters are generated.
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			DefaultCluster(&envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/80/be62406531",
+				AltStatName:          "default_kuard_80",
+				ClusterDiscoveryType: envoy_v3.ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   envoy_v3.ConfigSource("contour"),
+					ServiceName: "default/kuard",
+				},
+				CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+					Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+						MaxConnections: protobuf.UInt32(100),
+						MaxRequests:    protobuf.UInt32(50),
+						MaxRetries:     protobuf.UInt32(7),
+					}},
+				},
+			}),
+			DefaultCluster(&envoy_cluster_v3.Cluster{
+				Name:                 "default/kuard/80/da39a3ee5e",
+				AltStatName:          "default_kuard_80",
+				ClusterDiscoveryType: envoy_v3.ClusterDiscoveryType(envoy_cluster_v3.Cluster_EDS),
+				EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+					EdsConfig:   envoy_v3.ConfigSource("contour"),
+					ServiceName: "default/kuard",
+				},
+				CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+					Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+						MaxConnections: protobuf.UInt32(9000),
+						MaxRetries:     protobuf.UInt32(7),
+					}},
+				},
+			}),
+		),
+		TypeUrl: clusterType,
+	})
+}
+
 // issue 581, different service parameters should generate
 // a single CDS entry if they differ only in weight.
 func TestClusterPerServiceParameters(t *testing.T) {
@@ -0,0 +1,166 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDirectResponsePolicy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	svc1 := fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)})
+	rh.OnAdd(svc1)
+
+	p1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: svc1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "example.com"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/healthz")),
+				DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+					StatusCode: 200,
+					Body:       "ok",
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []contour_api_v1.Service{{
+					Name: svc1.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+	rh.OnAdd(p1)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost(p1.Spec.VirtualHost.Fqdn,
+					&envoy_route_v3.Route{
+						Match: routePrefix("/healthz"),
+						Action: envoy_v3.RouteDirectResponse(&dag.DirectResponse{
+							StatusCode: 200,
+							Body:       "ok",
+						}),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+}
+
+func TestDirectResponsePolicyBodyFromConfigMap(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	p1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "example.com"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				DirectResponsePolicy: &contour_api_v1.HTTPDirectResponsePolicy{
+					StatusCode: 503,
+					BodyFromConfigMap: &contour_api_v1.ConfigMapKeyReference{
+						Name: "maintenance",
+						Key:  "body",
+					},
+				},
+			}},
+		},
+	}
+	rh.OnAdd(p1)
+
+	// The ConfigMap does not exist yet, so the route is invalid.
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	})
+
+	cm1 := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "maintenance",
+			Namespace: "default",
+		},
+		Data: map[string]string{
+			"body": "down for maintenance",
+		},
+	}
+	rh.OnAdd(cm1)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost(p1.Spec.VirtualHost.Fqdn,
+					&envoy_route_v3.Route{
+						Match: routePrefix("/"),
+						Action: envoy_v3.RouteDirectResponse(&dag.DirectResponse{
+							StatusCode: 503,
+							Body:       "down for maintenance",
+						}),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+
+	// Updating the ConfigMap updates the response body.
+	cm2 := cm1.DeepCopy()
+	cm2.Data["body"] = "back soon"
+	rh.OnUpdate(cm1, cm2)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost(p1.Spec.VirtualHost.Fqdn,
+					&envoy_route_v3.Route{
+						Match: routePrefix("/"),
+						Action: envoy_v3.RouteDirectResponse(&dag.DirectResponse{
+							StatusCode: 503,
+							Body:       "back soon",
+						}),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+}
@@ -23,6 +23,7 @@ import (
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/featuretests"
 	"github.com/projectcontour/contour/internal/fixture"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -107,4 +108,87 @@ func TestDownstreamTLSCertificateValidation(t *testing.T) {
 		TypeUrl: listenerType,
 	}).Status(proxy).IsValid()
 
+	clientCRLSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "clientCRLSecret",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			dag.CRLKey: []byte(fixture.CRL),
+		},
+	}
+	rh.OnAdd(clientCRLSecret)
+
+	proxyWithOptions := proxy.DeepCopy()
+	proxyWithOptions.Spec.VirtualHost.TLS.ClientValidation = &contour_api_v1.DownstreamValidation{
+		CACertificate:             clientCASecret.Name,
+		CertificateRevocationList: clientCRLSecret.Name,
+		OptionalClientCertificate: true,
+		SubjectAltNames:           []string{"client.example.com"},
+		Subjects:                  []string{"CN=client.example.com"},
+		ForwardClientCertificate: &contour_api_v1.ClientCertificateDetails{
+			Subject: true,
+			DNS:     true,
+		},
+	}
+	rh.OnUpdate(proxy, proxyWithOptions)
+
+	peerValidationContext := &dag.PeerValidationContext{
+		CACertificate: &dag.Secret{
+			Object: clientCASecret,
+		},
+		CRL: &dag.Secret{
+			Object: clientCRLSecret,
+		},
+		OptionalClientCertificate: true,
+		SubjectAltNames:           []string{"client.example.com"},
+		Subjects:                  []string{"CN=client.example.com"},
+		ForwardClientCertificate: &dag.ClientCertificateDetails{
+			Subject: true,
+			DNS:     true,
+		},
+	}
+
+	ingressHTTPS = &envoy_listener_v3.Listener{
+		Name:    "ingress_https",
+		Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+		ListenerFilters: envoy_v3.ListenerFilters(
+			envoy_v3.TLSInspector(),
+		),
+		FilterChains: appendFilterChains(
+			filterchaintls("example.com", serverTLSSecret,
+				envoy_v3.HTTPConnectionManagerBuilder().
+					AddFilter(envoy_v3.FilterMisdirectedRequests("example.com")).
+					DefaultFilters().
+					AddFilter(envoy_v3.FilterClientCertificateSubjects(peerValidationContext)).
+					RouteConfigName("https/example.com").
+					MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+					AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+					ForwardClientCertificate(peerValidationContext.ForwardClientCertificate).
+					Get(),
+				peerValidationContext,
+				"h2", "http/1.1",
+			),
+		),
+		SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+	}
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			defaultHTTPListener(),
+			ingressHTTPS,
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(proxyWithOptions).IsValid()
+
+	proxyWithInvalidSubject := proxy.DeepCopy()
+	proxyWithInvalidSubject.Spec.VirtualHost.TLS.ClientValidation = &contour_api_v1.DownstreamValidation{
+		CACertificate: clientCASecret.Name,
+		Subjects:      []string{"CN=cliënt.example.com"},
+	}
+	rh.OnUpdate(proxyWithOptions, proxyWithInvalidSubject)
+
+	c.Status(proxyWithInvalidSubject).HasError(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+		`Spec.VirtualHost.TLS client validation is invalid: subject "CN=cliënt.example.com" must only contain printable ASCII characters`)
 }
@@ -29,6 +29,7 @@ import (
 	envoy_tcp_proxy_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	envoy_extensions_upstream_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/any"
@@ -222,6 +223,14 @@ func withPrefixRewrite(route *envoy_route_v3.Route_Route, replacement string) *e
 	return route
 }
 
+func withRegexRewrite(route *envoy_route_v3.Route_Route, pattern, substitution string) *envoy_route_v3.Route_Route {
+	route.Route.RegexRewrite = &matcher.RegexMatchAndSubstitute{
+		Pattern:      envoy_v3.SafeRegexMatch(pattern),
+		Substitution: substitution,
+	}
+	return route
+}
+
 func withRetryPolicy(route *envoy_route_v3.Route_Route, retryOn string, numRetries uint32, perTryTimeout time.Duration) *envoy_route_v3.Route_Route {
 	route.Route.RetryPolicy = &envoy_route_v3.RetryPolicy{
 		RetryOn: retryOn,
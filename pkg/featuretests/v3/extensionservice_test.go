@@ -24,10 +24,12 @@ import (
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/contour"
 	"github.com/projectcontour/contour/internal/dag"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/featuretests"
 	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/protobuf"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/pointer"
@@ -364,3 +366,47 @@ func TestExtensionService(t *testing.T) {
 		})
 	}
 }
+
+func TestExtensionServiceCircuitBreakerDefaults(t *testing.T) {
+	rh, c, done := setup(t, func(eh *contour.EventHandler) {
+		eh.Builder.Processors = []dag.Processor{
+			&dag.ExtensionServiceProcessor{
+				FieldLogger: fixture.NewTestLogger(t),
+				CircuitBreakerDefaults: &dag.CircuitBreakerPolicy{
+					MaxConnections: 100,
+					MaxRetries:     5,
+				},
+			},
+			&dag.ListenerProcessor{},
+		}
+	})
+	defer done()
+
+	rh.OnAdd(fixture.NewService("ns/svc1").WithPorts(corev1.ServicePort{Port: 8081}))
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("ns/ext"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "svc1", Port: 8081},
+			},
+			Protocol: pointer.StringPtr("h2c"),
+		},
+	})
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			DefaultCluster(
+				h2cCluster(cluster("extension/ns/ext", "extension/ns/ext", "extension_ns_ext")),
+				&envoy_cluster_v3.Cluster{
+					CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+						Thresholds: []*envoy_cluster_v3.CircuitBreakers_Thresholds{{
+							MaxConnections: protobuf.UInt32(100),
+							MaxRetries:     protobuf.UInt32(5),
+						}},
+					},
+				},
+			),
+		),
+	})
+}
@@ -0,0 +1,147 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_fault_common_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/common/fault/v3"
+	envoy_fault_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/fault/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/protobuf"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestFaultInjectionPolicy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("s1").WithPorts(v1.ServicePort{Port: 80}))
+	rh.OnAdd(fixture.NewService("s2").WithPorts(v1.ServicePort{Port: 80}))
+
+	p := fixture.NewProxy("faults").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "faults.example.com"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/delay")),
+				Services: []contour_api_v1.Service{{
+					Name: "s1",
+					Port: 80,
+				}},
+				FaultInjectionPolicy: &contour_api_v1.FaultInjectionPolicy{
+					Delay: &contour_api_v1.FaultDelay{
+						Duration:   "2s",
+						Percentage: 10,
+					},
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/abort")),
+				Services: []contour_api_v1.Service{{
+					Name: "s2",
+					Port: 80,
+				}},
+				FaultInjectionPolicy: &contour_api_v1.FaultInjectionPolicy{
+					Abort: &contour_api_v1.FaultAbort{
+						StatusCode: 503,
+					},
+					Headers: []contour_api_v1.HeaderMatchCondition{{
+						Name:  "X-Chaos",
+						Exact: "true",
+					}},
+				},
+			}},
+		},
+	)
+	rh.OnAdd(p)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("faults.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/delay"),
+						Action: routeCluster("default/s1/80/da39a3ee5e"),
+						TypedPerFilterConfig: withFilterConfig("envoy.filters.http.fault",
+							&envoy_fault_v3.HTTPFault{
+								Delay: &envoy_fault_common_v3.FaultDelay{
+									FaultDelaySecifier: &envoy_fault_common_v3.FaultDelay_FixedDelay{
+										FixedDelay: protobuf.Duration(2 * time.Second),
+									},
+									Percentage: &envoy_type_v3.FractionalPercent{
+										Numerator:   10,
+										Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+									},
+								},
+							}),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/abort"),
+						Action: routeCluster("default/s2/80/da39a3ee5e"),
+						TypedPerFilterConfig: withFilterConfig("envoy.filters.http.fault",
+							&envoy_fault_v3.HTTPFault{
+								Abort: &envoy_fault_v3.FaultAbort{
+									ErrorType: &envoy_fault_v3.FaultAbort_HttpStatus{
+										HttpStatus: 503,
+									},
+									Percentage: &envoy_type_v3.FractionalPercent{
+										Numerator:   100,
+										Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+									},
+								},
+								Headers: []*envoy_route_v3.HeaderMatcher{{
+									Name: "X-Chaos",
+									HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_ExactMatch{
+										ExactMatch: "true",
+									},
+								}},
+							}),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p).IsValid()
+
+	invalid := fixture.NewProxy("faults").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "faults.example.com"},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "s1",
+					Port: 80,
+				}},
+				FaultInjectionPolicy: &contour_api_v1.FaultInjectionPolicy{
+					Delay: &contour_api_v1.FaultDelay{
+						Duration: "-1s",
+					},
+				},
+			}},
+		},
+	)
+	rh.OnUpdate(p, invalid)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeRouteError, "FaultInjectionPolicyNotValid",
+		`route.faultInjectionPolicy is invalid: delay duration "-1s" must be greater than zero`)
+}
@@ -219,3 +219,103 @@ func TestHeaderPolicy_ReplaceHeader_HTTProxy(t *testing.T) {
 		TypeUrl: clusterType,
 	})
 }
+
+func TestHeaderPolicy_VirtualHost_HTTPProxy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("svc1").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}),
+	)
+
+	p1 := fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "hello.world",
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Remove: []string{"x-internal"},
+				},
+				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "x-frame-options",
+						Value: "DENY",
+					}},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "svc1",
+					Port: 80,
+				}},
+				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "x-route",
+						Value: "svc1",
+					}},
+				},
+			}},
+		})
+	rh.OnAdd(p1)
+
+	vhost := envoy_v3.VirtualHost("hello.world",
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/svc1/80/da39a3ee5e"),
+			ResponseHeadersToAdd: []*envoy_core_v3.HeaderValueOption{{
+				Header: &envoy_core_v3.HeaderValue{
+					Key:   "X-Route",
+					Value: "svc1",
+				},
+				Append: &wrappers.BoolValue{
+					Value: false,
+				},
+			}},
+		},
+	)
+	vhost.RequestHeadersToRemove = []string{"X-Internal"}
+	vhost.ResponseHeadersToAdd = []*envoy_core_v3.HeaderValueOption{{
+		Header: &envoy_core_v3.HeaderValue{
+			Key:   "X-Frame-Options",
+			Value: "DENY",
+		},
+		Append: &wrappers.BoolValue{
+			Value: false,
+		},
+	}}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http", vhost),
+		),
+		TypeUrl: routeType,
+	}).Status(p1).IsValid()
+
+	// Rewriting the Host header is not supported on the virtual host.
+	p2 := fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "hello.world",
+				RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "Host",
+						Value: "goodbye.planet",
+					}},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "svc1",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnUpdate(p1, p2)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(p2).HasError(contour_api_v1.ConditionTypeVirtualHostError, "RequestHeadersPolicyInvalid",
+		`Spec.VirtualHost.RequestHeadersPolicy: rewriting "Host" header is not supported`)
+}
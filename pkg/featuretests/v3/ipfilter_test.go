@@ -0,0 +1,167 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_config_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/protobuf"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestIPFilterPolicy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("s1").WithPorts(v1.ServicePort{Port: 80}))
+
+	ipRules := func(action envoy_config_rbac_v3.RBAC_Action, principals ...*envoy_config_rbac_v3.Principal) *envoy_rbac_v3.RBACPerRoute {
+		return &envoy_rbac_v3.RBACPerRoute{
+			Rbac: &envoy_rbac_v3.RBAC{
+				Rules: &envoy_config_rbac_v3.RBAC{
+					Action: action,
+					Policies: map[string]*envoy_config_rbac_v3.Policy{
+						"ip-rules": {
+							Permissions: []*envoy_config_rbac_v3.Permission{{
+								Rule: &envoy_config_rbac_v3.Permission_Any{Any: true},
+							}},
+							Principals: principals,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	p := fixture.NewProxy("ipfilter").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "ipfilter.example.com",
+				IPDenyFilterPolicy: []contour_api_v1.IPFilterPolicy{{
+					Source: contour_api_v1.IPFilterSourceRemote,
+					CIDR:   "192.168.1.1",
+				}},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []contour_api_v1.Service{{
+					Name: "s1",
+					Port: 80,
+				}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/admin")),
+				Services: []contour_api_v1.Service{{
+					Name: "s1",
+					Port: 80,
+				}},
+				IPAllowFilterPolicy: []contour_api_v1.IPFilterPolicy{{
+					Source: contour_api_v1.IPFilterSourcePeer,
+					CIDR:   "10.8.0.0/16",
+				}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/old")),
+				RequestRedirectPolicy: &contour_api_v1.HTTPRequestRedirectPolicy{
+					Hostname: pointer.StringPtr("envoyproxy.io"),
+				},
+				IPAllowFilterPolicy: []contour_api_v1.IPFilterPolicy{{
+					Source: contour_api_v1.IPFilterSourcePeer,
+					CIDR:   "10.8.0.0/16",
+				}},
+			}},
+		},
+	)
+	rh.OnAdd(p)
+
+	allowPeer := withFilterConfig("envoy.filters.http.rbac",
+		ipRules(envoy_config_rbac_v3.RBAC_ALLOW, &envoy_config_rbac_v3.Principal{
+			Identifier: &envoy_config_rbac_v3.Principal_DirectRemoteIp{
+				DirectRemoteIp: &envoy_core_v3.CidrRange{
+					AddressPrefix: "10.8.0.0",
+					PrefixLen:     protobuf.UInt32(16),
+				},
+			},
+		}))
+
+	vhost := envoy_v3.VirtualHost("ipfilter.example.com",
+		// Redirects are filtered too.
+		&envoy_route_v3.Route{
+			Match: routePrefix("/old"),
+			Action: envoy_v3.RouteRedirect(&dag.Redirect{
+				Hostname:   "envoyproxy.io",
+				StatusCode: 302,
+			}),
+			TypedPerFilterConfig: allowPeer,
+		},
+		&envoy_route_v3.Route{
+			Match:                routePrefix("/admin"),
+			Action:               routeCluster("default/s1/80/da39a3ee5e"),
+			TypedPerFilterConfig: allowPeer,
+		},
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/s1/80/da39a3ee5e"),
+		},
+	)
+	vhost.TypedPerFilterConfig = withFilterConfig("envoy.filters.http.rbac",
+		ipRules(envoy_config_rbac_v3.RBAC_DENY, &envoy_config_rbac_v3.Principal{
+			Identifier: &envoy_config_rbac_v3.Principal_RemoteIp{
+				RemoteIp: &envoy_core_v3.CidrRange{
+					AddressPrefix: "192.168.1.1",
+					PrefixLen:     protobuf.UInt32(32),
+				},
+			},
+		}))
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http", vhost),
+		),
+		TypeUrl: routeType,
+	}).Status(p).IsValid()
+
+	invalid := fixture.NewProxy("ipfilter").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "ipfilter.example.com"},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "s1",
+					Port: 80,
+				}},
+				IPAllowFilterPolicy: []contour_api_v1.IPFilterPolicy{{
+					Source: contour_api_v1.IPFilterSourceRemote,
+					CIDR:   "10.8.0.0/33",
+				}},
+			}},
+		},
+	)
+	rh.OnUpdate(p, invalid)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeIPFilterError, "PolicyDidNotParse",
+		`route.ipFilterPolicy is invalid: invalid CIDR "10.8.0.0/33"`)
+}
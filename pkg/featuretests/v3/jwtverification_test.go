@@ -0,0 +1,225 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_jwt_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestJWTVerification(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	const fqdn = "jwt.example.com"
+
+	sec := &v1.Secret{
+		ObjectMeta: fixture.ObjectMeta("certificate"),
+		Type:       "kubernetes.io/tls",
+		Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(sec)
+	rh.OnAdd(fixture.NewService("s1").WithPorts(v1.ServicePort{Port: 80}))
+
+	p := fixture.NewProxy("jwt").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services:   []contour_api_v1.Service{{Name: "s1", Port: 80}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/public")),
+				Services:   []contour_api_v1.Service{{Name: "s1", Port: 80}},
+				JWTVerificationPolicy: &contour_api_v1.JWTVerificationPolicy{
+					Disabled: true,
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/local")),
+				Services:   []contour_api_v1.Service{{Name: "s1", Port: 80}},
+				JWTVerificationPolicy: &contour_api_v1.JWTVerificationPolicy{
+					Require: "local",
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/old")),
+				RequestRedirectPolicy: &contour_api_v1.HTTPRequestRedirectPolicy{
+					Hostname: pointer.StringPtr("envoyproxy.io"),
+				},
+			}},
+		})
+	p.Spec.VirtualHost.JWTProviders = []contour_api_v1.JWTProvider{{
+		Name:      "remote",
+		Default:   true,
+		Issuer:    "https://issuer.example.com",
+		Audiences: []string{"example"},
+		RemoteJWKS: &contour_api_v1.RemoteJWKS{
+			URI: "https://jwks.example.com/keys.json",
+		},
+		ClaimsToHeaders: []contour_api_v1.JWTClaimToHeader{{
+			Claim:  "sub",
+			Header: "x-jwt-sub",
+		}},
+	}, {
+		Name:      "local",
+		LocalJWKS: `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+	}}
+	rh.OnAdd(p)
+
+	providers := []dag.JWTProvider{{
+		Name:      "remote",
+		Issuer:    "https://issuer.example.com",
+		Audiences: []string{"example"},
+		RemoteJWKS: &dag.RemoteJWKS{
+			URI:     "https://jwks.example.com/keys.json",
+			Timeout: time.Second,
+			Cluster: dag.DNSNameCluster{
+				Address: "jwks.example.com",
+				Scheme:  "https",
+				Port:    443,
+			},
+		},
+		ClaimsToHeaders: []dag.JWTClaimToHeader{{
+			Claim:  "sub",
+			Header: "X-Jwt-Sub",
+		}},
+	}, {
+		Name:      "local",
+		LocalJWKS: `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+	}}
+
+	httpsFilter := envoy_v3.HTTPConnectionManagerBuilder().
+		AddFilter(envoy_v3.FilterMisdirectedRequests(fqdn)).
+		DefaultFilters().
+		AddFilter(envoy_v3.FilterJWTAuthN(providers)).
+		RouteConfigName(path.Join("https", fqdn)).
+		MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+		AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+		Get()
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls(fqdn, sec, httpsFilter, nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener(),
+		),
+	}).Status(p).IsValid()
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("https/"+fqdn,
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/public"),
+						Action: routeCluster("default/s1/80/da39a3ee5e"),
+					},
+					// Redirects are verified too.
+					&envoy_route_v3.Route{
+						Match: routePrefix("/old"),
+						Action: envoy_v3.RouteRedirect(&dag.Redirect{
+							Hostname:   "envoyproxy.io",
+							StatusCode: 302,
+						}),
+						TypedPerFilterConfig: withFilterConfig("envoy.filters.http.jwt_authn",
+							&envoy_jwt_v3.PerRouteConfig{
+								RequirementSpecifier: &envoy_jwt_v3.PerRouteConfig_RequirementName{RequirementName: "remote"},
+							}),
+						RequestHeadersToAdd:    envoy_v3.JWTClaimHeaders(&providers[0]),
+						RequestHeadersToRemove: []string{"X-Jwt-Sub"},
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/local"),
+						Action: routeCluster("default/s1/80/da39a3ee5e"),
+						TypedPerFilterConfig: withFilterConfig("envoy.filters.http.jwt_authn",
+							&envoy_jwt_v3.PerRouteConfig{
+								RequirementSpecifier: &envoy_jwt_v3.PerRouteConfig_RequirementName{RequirementName: "local"},
+							}),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/s1/80/da39a3ee5e"),
+						TypedPerFilterConfig: withFilterConfig("envoy.filters.http.jwt_authn",
+							&envoy_jwt_v3.PerRouteConfig{
+								RequirementSpecifier: &envoy_jwt_v3.PerRouteConfig_RequirementName{RequirementName: "remote"},
+							}),
+						RequestHeadersToAdd:    envoy_v3.JWTClaimHeaders(&providers[0]),
+						RequestHeadersToRemove: []string{"X-Jwt-Sub"},
+					},
+				),
+			),
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost(fqdn,
+					upgradeHTTPS(routePrefix("/public")),
+					upgradeHTTPS(routePrefix("/old")),
+					upgradeHTTPS(routePrefix("/local")),
+					upgradeHTTPS(routePrefix("/")),
+				),
+			),
+		),
+	}).Status(p).IsValid()
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			cluster("default/s1/80/da39a3ee5e", "default/s1", "default_s1_80"),
+			DefaultCluster(&envoy_cluster_v3.Cluster{
+				Name:                 "dnsname/jwks.example.com/443/c3437dbc7c",
+				ClusterDiscoveryType: envoy_v3.ClusterDiscoveryType(envoy_cluster_v3.Cluster_STRICT_DNS),
+				LoadAssignment: envoy_v3.ClusterLoadAssignment("dnsname/jwks.example.com/443/c3437dbc7c",
+					envoy_v3.SocketAddress("jwks.example.com", 443)),
+				TransportSocket: envoy_v3.UpstreamTLSTransportSocket(
+					envoy_v3.UpstreamTLSContext(nil, "jwks.example.com", nil),
+				),
+			}),
+		),
+	})
+
+	invalid := p.DeepCopy()
+	invalid.Spec.Routes[2].JWTVerificationPolicy.Require = "missing"
+	rh.OnUpdate(p, invalid)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationPolicyNotValid",
+		`route.jwtVerificationPolicy is invalid: JWT provider "missing" is not defined on the root virtual host`)
+}
@@ -15,6 +15,7 @@ package v3
 
 import (
 	"testing"
+	"time"
 
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
@@ -22,6 +23,7 @@ import (
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/protobuf"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -152,6 +154,105 @@ func TestLoadBalancerPolicySessionAffinity(t *testing.T) {
 }
 
 // Request hash load balancing is only available in httpproxy.
+func TestLoadBalancerPolicySessionAffinityCookieOptions(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	s1 := fixture.NewService("app").WithPorts(
+		v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)})
+	rh.OnAdd(s1)
+
+	proxy1 := fixture.NewProxy("simple").
+		WithFQDN("www.example.com").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/cart")),
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "Cookie",
+					CookieHashOptions: &contour_api_v1.CookieHashOptions{
+						CookieName: "cart-session",
+						TTL:        "24h",
+						Path:       "/cart",
+					},
+				},
+				Services: []contour_api_v1.Service{{
+					Name: s1.Name,
+					Port: 80,
+				}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/checkout")),
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "RequestHash",
+					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
+						{
+							Terminal: true,
+							CookieHashOptions: &contour_api_v1.CookieHashOptions{
+								CookieName: "cart-session",
+							},
+						},
+						{
+							HeaderHashOptions: &contour_api_v1.HeaderHashOptions{
+								HeaderName: "X-Some-Header",
+							},
+						},
+					},
+				},
+				Services: []contour_api_v1.Service{{
+					Name: s1.Name,
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy1)
+
+	cartRoute := routeCluster("default/app/80/e4f81994fe")
+	cartRoute.Route.HashPolicy = []*envoy_route_v3.RouteAction_HashPolicy{{
+		PolicySpecifier: &envoy_route_v3.RouteAction_HashPolicy_Cookie_{
+			Cookie: &envoy_route_v3.RouteAction_HashPolicy_Cookie{
+				Name: "cart-session",
+				Ttl:  protobuf.Duration(24 * time.Hour),
+				Path: "/cart",
+			},
+		},
+	}}
+
+	checkoutRoute := routeCluster("default/app/80/1a2ffc1fef")
+	checkoutRoute.Route.HashPolicy = []*envoy_route_v3.RouteAction_HashPolicy{{
+		Terminal: true,
+		PolicySpecifier: &envoy_route_v3.RouteAction_HashPolicy_Cookie_{
+			Cookie: &envoy_route_v3.RouteAction_HashPolicy_Cookie{
+				Name: "cart-session",
+				Ttl:  protobuf.Duration(0),
+				Path: "/",
+			},
+		},
+	}, {
+		PolicySpecifier: &envoy_route_v3.RouteAction_HashPolicy_Header_{
+			Header: &envoy_route_v3.RouteAction_HashPolicy_Header{
+				HeaderName: "X-Some-Header",
+			},
+		},
+	}}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/checkout"),
+						Action: checkoutRoute,
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/cart"),
+						Action: cartRoute,
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+}
+
 func TestLoadBalancerPolicyRequestHashHeader(t *testing.T) {
 	rh, c, done := setup(t)
 	defer done()
@@ -224,3 +325,76 @@ func TestLoadBalancerPolicyRequestHashHeader(t *testing.T) {
 		TypeUrl: routeType,
 	})
 }
+
+func TestLoadBalancerPolicyRequestHashSourceIPAndQueryParameter(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	s1 := fixture.NewService("app").WithPorts(
+		v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)})
+	rh.OnAdd(s1)
+
+	proxy1 := fixture.NewProxy("simple").
+		WithFQDN("www.example.com").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/cart")),
+				LoadBalancerPolicy: &contour_api_v1.LoadBalancerPolicy{
+					Strategy: "RequestHash",
+					RequestHashPolicies: []contour_api_v1.RequestHashPolicy{
+						{
+							Terminal: true,
+							QueryParameterHashOptions: &contour_api_v1.QueryParameterHashOptions{
+								ParameterName: "tenant",
+							},
+						},
+						{
+							HashSourceIP: true,
+						},
+					},
+				},
+				Services: []contour_api_v1.Service{{
+					Name: s1.Name,
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy1)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match: routePrefix("/cart"),
+						Action: &envoy_route_v3.Route_Route{
+							Route: &envoy_route_v3.RouteAction{
+								ClusterSpecifier: &envoy_route_v3.RouteAction_Cluster{
+									Cluster: "default/app/80/1a2ffc1fef",
+								},
+								HashPolicy: []*envoy_route_v3.RouteAction_HashPolicy{
+									{
+										Terminal: true,
+										PolicySpecifier: &envoy_route_v3.RouteAction_HashPolicy_QueryParameter_{
+											QueryParameter: &envoy_route_v3.RouteAction_HashPolicy_QueryParameter{
+												Name: "tenant",
+											},
+										},
+									},
+									{
+										PolicySpecifier: &envoy_route_v3.RouteAction_HashPolicy_ConnectionProperties_{
+											ConnectionProperties: &envoy_route_v3.RouteAction_HashPolicy_ConnectionProperties{
+												SourceIp: true,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+}
@@ -16,8 +16,10 @@ package v3
 import (
 	"testing"
 
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/contour"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
@@ -84,3 +86,83 @@ func TestMirrorPolicy(t *testing.T) {
 		TypeUrl: clusterType,
 	})
 }
+
+func TestMirrorPolicyMultiplePartialMirrors(t *testing.T) {
+	rh, c, done := setup(t, func(reh *contour.EventHandler) {})
+	defer done()
+
+	svc1 := fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)})
+	svc2 := fixture.NewService("mirror").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)})
+	svc3 := fixture.NewService("canary").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)})
+	rh.OnAdd(svc1)
+	rh.OnAdd(svc2)
+	rh.OnAdd(svc3)
+
+	p1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: svc1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{Fqdn: "example.com"},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []contour_api_v1.Service{{
+					Name: svc1.Name,
+					Port: 8080,
+				}, {
+					Name:   svc2.Name,
+					Port:   8080,
+					Mirror: true,
+				}, {
+					Name:          svc3.Name,
+					Port:          8080,
+					Mirror:        true,
+					MirrorPercent: 5,
+				}},
+			}},
+		},
+	}
+	rh.OnAdd(p1)
+
+	route := routeCluster("default/kuard/8080/da39a3ee5e")
+	route.Route.RequestMirrorPolicies = []*envoy_route_v3.RouteAction_RequestMirrorPolicy{{
+		Cluster: "default/mirror/8080/da39a3ee5e",
+	}, {
+		Cluster: "default/canary/8080/da39a3ee5e",
+		RuntimeFraction: &envoy_core_v3.RuntimeFractionalPercent{
+			DefaultValue: &envoy_type_v3.FractionalPercent{
+				Numerator:   5,
+				Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+			},
+		},
+	}}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost(p1.Spec.VirtualHost.Fqdn,
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: route,
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+
+	// assert that there are three clusters in CDS, one for the route
+	// service and one for each mirror service.
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			cluster("default/canary/8080/da39a3ee5e", "default/canary", "default_canary_8080"),
+			cluster("default/kuard/8080/da39a3ee5e", "default/kuard", "default_kuard_8080"),
+			cluster("default/mirror/8080/da39a3ee5e", "default/mirror", "default_mirror_8080"),
+		),
+		TypeUrl: clusterType,
+	})
+}
@@ -526,6 +526,113 @@ func artifactoryDocker(t *testing.T) {
 	})
 }
 
+func regexRewriteMultiInclude(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	vhost1 := fixture.NewProxy("host1").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "host1.projectcontour.io",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name:       "app",
+				Namespace:  "default",
+				Conditions: matchconditions(prefixMatchCondition("/v1")),
+			}},
+		})
+
+	vhost2 := fixture.NewProxy("host2").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "host2.projectcontour.io",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name:       "app",
+				Namespace:  "default",
+				Conditions: matchconditions(prefixMatchCondition("/v2")),
+			}},
+		})
+
+	healthz := "/healthz"
+	app := fixture.NewProxy("app").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				PathRewritePolicy: &contour_api_v1.PathRewritePolicy{
+					RegexRewrite: &contour_api_v1.RegexRewrite{
+						Pattern:      "^/users/([0-9]+)$",
+						Substitution: "/accounts/\\1",
+					},
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/status")),
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				PathRewritePolicy: &contour_api_v1.PathRewritePolicy{
+					ReplaceFullPath: &healthz,
+				},
+			}},
+		})
+
+	rh.OnAdd(vhost1)
+	rh.OnAdd(vhost2)
+	rh.OnAdd(app)
+
+	// Anchored patterns are relative to, and preserve, the include prefix.
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("host1.projectcontour.io",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/v1/status"),
+						Action: withRegexRewrite(routeCluster("default/kuard/8080/da39a3ee5e"), "^.*$", "/healthz"),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/v1"),
+						Action: withRegexRewrite(routeCluster("default/kuard/8080/da39a3ee5e"), "^/v1/users/([0-9]+)$", "/v1/accounts/\\1"),
+					},
+				),
+				envoy_v3.VirtualHost("host2.projectcontour.io",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/v2/status"),
+						Action: withRegexRewrite(routeCluster("default/kuard/8080/da39a3ee5e"), "^.*$", "/healthz"),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/v2"),
+						Action: withRegexRewrite(routeCluster("default/kuard/8080/da39a3ee5e"), "^/v2/users/([0-9]+)$", "/v2/accounts/\\1"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+
+	// Combining rewrite options is rejected.
+	app = update(rh, app,
+		func(app *contour_api_v1.HTTPProxy) {
+			app.Spec.Routes[1].PathRewritePolicy.ReplacePrefix = []contour_api_v1.ReplacePrefix{
+				{Replacement: "/"},
+			}
+		})
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(app).HasError(contour_api_v1.ConditionTypeRouteError, "PathRewritePolicyNotValid",
+		"route.pathRewritePolicy is invalid: only one of replacePrefix, regexRewrite or replaceFullPath may be specified")
+}
+
 func TestHTTPProxyPathPrefix(t *testing.T) {
 	subtests := []struct {
 		Name string
@@ -535,6 +642,7 @@ func TestHTTPProxyPathPrefix(t *testing.T) {
 		{Name: "MultiInclude", Func: multiInclude},
 		{Name: "ReplaceWithSlash", Func: replaceWithSlash},
 		{Name: "ArtifactoryDocker", Func: artifactoryDocker},
+		{Name: "RegexRewriteMultiInclude", Func: regexRewriteMultiInclude},
 	}
 
 	for _, s := range subtests {
@@ -145,5 +145,16 @@ dsipT/w+MFInBLLiJcqLAfh0647vhjCFNGG4MZ6YGVwiIdvjZ1dH2Ujsdo8e0+Wi
 8cnx8JvuAJdr5HzMI6fvnMDzjzAskMgYUNhOUhM2g223JuoyyLY2/DL7dOYkFeSn
 b5qYn0JNERfPYdLwXNV1HCM9
 -----END PRIVATE KEY-----
+`
+
+	// CRL is a certificate revocation list for a test CA that revokes
+	// the certificate with serial number 2.
+	CRL = `-----BEGIN X509 CRL-----
+MIHiMIGIAgEBMAoGCCqGSM49BAMCMCAxHjAcBgNVBAMTFWNsaWVudC1jYS5leGFt
+cGxlLmNvbRcNMjEwMTAxMDAwMDAwWhgPMjEyMTAxMDEwMDAwMDBaMBQwEgIBAhcN
+MjEwMTAxMDAwMDAwWqAfMB0wDwYDVR0jBAgwBoAEAQIDBDAKBgNVHRQEAwIBATAK
+BggqhkjOPQQDAgNJADBGAiEA5yhMW/nrCgC26lJ+EcHC2e1RvuMjExIlDG2sQtb3
+0lgCIQDexI5Csd7kxf+dZRwBDpMxi9q8AB00SOWj38FQpznMng==
+-----END X509 CRL-----
 `
 )
@@ -17,6 +17,7 @@ import (
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
 	networking_v1 "k8s.io/api/networking/v1"
 	networking_v1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -94,6 +95,15 @@ func SecretsResources() []schema.GroupVersionResource {
 	}
 }
 
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+// ConfigMapsResources ...
+func ConfigMapsResources() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{
+		corev1.SchemeGroupVersion.WithResource("configmaps"),
+	}
+}
+
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
 
 // EndpointsResources ...
@@ -103,6 +113,24 @@ func EndpointsResources() []schema.GroupVersionResource {
 	}
 }
 
+// +kubebuilder:rbac:groups="discovery.k8s.io",resources=endpointslices,verbs=get;list;watch
+
+// EndpointSlicesResources ...
+func EndpointSlicesResources() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{
+		discovery_v1.SchemeGroupVersion.WithResource("endpointslices"),
+	}
+}
+
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+// NodesResources ...
+func NodesResources() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{
+		corev1.SchemeGroupVersion.WithResource("nodes"),
+	}
+}
+
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
 
 // ServicesResources ...
@@ -20,6 +20,7 @@ import (
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
 	networking_v1 "k8s.io/api/networking/v1"
 	"k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -33,6 +34,7 @@ func TestKindOf(t *testing.T) {
 		{"Secret", &v1.Secret{}},
 		{"Service", &v1.Service{}},
 		{"Endpoints", &v1.Endpoints{}},
+		{"EndpointSlice", &discovery_v1.EndpointSlice{}},
 		{"Pod", &v1.Pod{}},
 		{"Ingress", &v1beta1.Ingress{}},
 		{"Ingress", &networking_v1.Ingress{}},
@@ -60,6 +62,7 @@ func TestVersionOf(t *testing.T) {
 		{"v1", &v1.Secret{}},
 		{"v1", &v1.Service{}},
 		{"v1", &v1.Endpoints{}},
+		{"discovery.k8s.io/v1", &discovery_v1.EndpointSlice{}},
 		{"networking.k8s.io/v1beta1", &v1beta1.Ingress{}},
 		{"projectcontour.io/v1", &contour_api_v1.HTTPProxy{}},
 		{"projectcontour.io/v1", &contour_api_v1.TLSCertificateDelegation{}},
@@ -74,14 +74,26 @@ func (s headerMatchConditionSorter) Less(i, j int) bool {
 				return compareValue(s[i], s[j])
 			case dag.HeaderMatchTypeContains:
 				return true
+			case dag.HeaderMatchTypeRegex:
+				return true
 			case dag.HeaderMatchTypePresent:
 				return true
 			}
 		case dag.HeaderMatchTypeContains:
-			// Contains matches sort ahead of Present matches.
+			// Contains matches sort ahead of Regex and Present matches.
 			switch s[j].MatchType {
 			case dag.HeaderMatchTypeContains:
 				return compareValue(s[i], s[j])
+			case dag.HeaderMatchTypeRegex:
+				return true
+			case dag.HeaderMatchTypePresent:
+				return true
+			}
+		case dag.HeaderMatchTypeRegex:
+			// Regex matches sort ahead of Present matches.
+			switch s[j].MatchType {
+			case dag.HeaderMatchTypeRegex:
+				return compareValue(s[i], s[j])
 			case dag.HeaderMatchTypePresent:
 				return true
 			}
@@ -96,8 +108,40 @@ func (s headerMatchConditionSorter) Less(i, j int) bool {
 	}
 }
 
+// queryParamMatchTypeRank orders query parameter match types from most to
+// least specific. Exact matches sort first, followed by prefix, regex and
+// then present matches.
+var queryParamMatchTypeRank = map[string]int{
+	dag.QueryParamMatchTypeExact:   0,
+	dag.QueryParamMatchTypePrefix:  1,
+	dag.QueryParamMatchTypeRegex:   2,
+	dag.QueryParamMatchTypePresent: 3,
+}
+
+// Sorts QueryParamMatchCondition objects, first by the query parameter
+// name, then by their matcher conditions type, then by their value.
+type queryParamMatchConditionSorter []dag.QueryParamMatchCondition
+
+func (s queryParamMatchConditionSorter) Len() int      { return len(s) }
+func (s queryParamMatchConditionSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s queryParamMatchConditionSorter) Less(i, j int) bool {
+	switch strings.Compare(s[i].Name, s[j].Name) {
+	case -1:
+		return true
+	case 1:
+		return false
+	}
+
+	if queryParamMatchTypeRank[s[i].MatchType] != queryParamMatchTypeRank[s[j].MatchType] {
+		return queryParamMatchTypeRank[s[i].MatchType] < queryParamMatchTypeRank[s[j].MatchType]
+	}
+
+	return s[i].Value < s[j].Value
+}
+
 // longestRouteByHeaderConditions compares the HeaderMatchCondition slices for
-// lhs and rhs and returns true if lhs is longer.
+// lhs and rhs and returns true if lhs is longer. If the HeaderMatchCondition
+// slices are equivalent, the QueryParamMatchCondition slices are compared.
 func longestRouteByHeaderConditions(lhs, rhs *dag.Route) bool {
 	if len(lhs.HeaderMatchConditions) == len(rhs.HeaderMatchConditions) {
 		pair := make([]dag.HeaderMatchCondition, 2)
@@ -109,17 +153,45 @@ func longestRouteByHeaderConditions(lhs, rhs *dag.Route) bool {
 			if headerMatchConditionSorter(pair).Less(0, 1) {
 				return true
 			}
+			if headerMatchConditionSorter(pair).Less(1, 0) {
+				return false
+			}
 		}
+
+		return longestRouteByQueryParamConditions(lhs, rhs)
 	}
 
 	return len(lhs.HeaderMatchConditions) > len(rhs.HeaderMatchConditions)
 }
 
+// longestRouteByQueryParamConditions compares the QueryParamMatchCondition
+// slices for lhs and rhs and returns true if lhs is longer.
+func longestRouteByQueryParamConditions(lhs, rhs *dag.Route) bool {
+	if len(lhs.QueryParamMatchConditions) == len(rhs.QueryParamMatchConditions) {
+		pair := make([]dag.QueryParamMatchCondition, 2)
+
+		for i := 0; i < len(lhs.QueryParamMatchConditions); i++ {
+			pair[0] = lhs.QueryParamMatchConditions[i]
+			pair[1] = rhs.QueryParamMatchConditions[i]
+
+			if queryParamMatchConditionSorter(pair).Less(0, 1) {
+				return true
+			}
+			if queryParamMatchConditionSorter(pair).Less(1, 0) {
+				return false
+			}
+		}
+	}
+
+	return len(lhs.QueryParamMatchConditions) > len(rhs.QueryParamMatchConditions)
+}
+
 // Sorts the given Route slice in place. Routes are ordered first by
 // type (exact sorts before regex, sorts before prefix) and then
 // longest path match value, then by the length of the HeaderMatch
-// slice (if any). The HeaderMatch slice is also ordered by the matching
-// header name.
+// slice (if any), then by the length of the QueryParamMatch slice (if any).
+// The HeaderMatch and QueryParamMatch slices are also ordered by the
+// matching header or query parameter name.
 type routeSorter []*dag.Route
 
 func (s routeSorter) Len() int      { return len(s) }
@@ -275,6 +347,8 @@ func For(v interface{}) sort.Interface {
 		return routeSorter(v)
 	case []dag.HeaderMatchCondition:
 		return headerMatchConditionSorter(v)
+	case []dag.QueryParamMatchCondition:
+		return queryParamMatchConditionSorter(v)
 	case []*envoy_cluster_v3.Cluster:
 		return clusterSorter(v)
 	case []*envoy_endpoint_v3.ClusterLoadAssignment:
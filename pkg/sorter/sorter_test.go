@@ -134,6 +134,14 @@ func containsHeader(name string, value string) dag.HeaderMatchCondition {
 	}
 }
 
+func regexHeader(name string, value string) dag.HeaderMatchCondition {
+	return dag.HeaderMatchCondition{
+		Name:      name,
+		MatchType: dag.HeaderMatchTypeRegex,
+		Value:     value,
+	}
+}
+
 func presentHeader(name string) dag.HeaderMatchCondition {
 	return dag.HeaderMatchCondition{
 		Name:      name,
@@ -141,6 +149,14 @@ func presentHeader(name string) dag.HeaderMatchCondition {
 	}
 }
 
+func queryParam(name string, matchType string, value string) dag.QueryParamMatchCondition {
+	return dag.QueryParamMatchCondition{
+		Name:      name,
+		MatchType: matchType,
+		Value:     value,
+	}
+}
+
 func TestSortRoutesPathMatch(t *testing.T) {
 	want := []*dag.Route{
 		// Note that exact matches sort before regex matches.
@@ -275,6 +291,50 @@ func TestSortRoutesLongestHeaders(t *testing.T) {
 	assert.Equal(t, want, have)
 }
 
+func TestSortRoutesLongestQueryParams(t *testing.T) {
+	want := []*dag.Route{
+		{
+			PathMatchCondition: matchPrefixString("/path"),
+			HeaderMatchConditions: []dag.HeaderMatchCondition{
+				presentHeader("header-name"),
+			},
+		},
+		{
+			PathMatchCondition: matchPrefixString("/path"),
+			QueryParamMatchConditions: []dag.QueryParamMatchCondition{
+				queryParam("api-version", dag.QueryParamMatchTypeExact, "v1"),
+				queryParam("debug", dag.QueryParamMatchTypePresent, ""),
+			},
+		},
+		{
+			PathMatchCondition: matchPrefixString("/path"),
+			QueryParamMatchConditions: []dag.QueryParamMatchCondition{
+				queryParam("api-version", dag.QueryParamMatchTypeExact, "v1"),
+			},
+		},
+		{
+			PathMatchCondition: matchPrefixString("/path"),
+			QueryParamMatchConditions: []dag.QueryParamMatchCondition{
+				queryParam("api-version", dag.QueryParamMatchTypePrefix, "v"),
+			},
+		},
+		{
+			PathMatchCondition: matchPrefixString("/path"),
+			QueryParamMatchConditions: []dag.QueryParamMatchCondition{
+				queryParam("api-version", dag.QueryParamMatchTypePresent, ""),
+			},
+		},
+		{
+			PathMatchCondition: matchPrefixString("/path"),
+		},
+	}
+
+	have := shuffleRoutes(want)
+
+	sort.Stable(For(have))
+	assert.Equal(t, want, have)
+}
+
 func TestSortSecrets(t *testing.T) {
 	want := []*envoy_tls_v3.Secret{
 		{Name: "first"},
@@ -294,18 +354,21 @@ func TestSortHeaderMatchConditions(t *testing.T) {
 	want := []dag.HeaderMatchCondition{
 		// Note that if the header names are the same, we
 		// order by the type, "exact" sorts before "contains"
-		// which sorts before "present" in terms of specificity.
+		// which sorts before "regex" which sorts before
+		// "present" in terms of specificity.
 		presentHeader("ashort"),
 		exactHeader("header-name", "anything"),
 		containsHeader("header-name", "something"),
+		regexHeader("header-name", "some.*"),
 		presentHeader("header-name"),
 		exactHeader("long-header-name", "long-header-value"),
 	}
 
 	have := []dag.HeaderMatchCondition{
+		want[5],
 		want[4],
-		want[3],
 		want[0],
+		want[3],
 		want[2],
 		want[1],
 	}
@@ -366,6 +429,32 @@ func TestSortHeaderMatchConditionsValue(t *testing.T) {
 	assert.Equal(t, want, have)
 }
 
+func TestSortQueryParamMatchConditions(t *testing.T) {
+	// If the query parameter names are the same, we order by the
+	// type, "exact" sorts before "prefix", which sorts before
+	// "regex", which sorts before "present".
+	want := []dag.QueryParamMatchCondition{
+		queryParam("a", dag.QueryParamMatchTypePresent, ""),
+		queryParam("param", dag.QueryParamMatchTypeExact, "a"),
+		queryParam("param", dag.QueryParamMatchTypeExact, "b"),
+		queryParam("param", dag.QueryParamMatchTypePrefix, "a"),
+		queryParam("param", dag.QueryParamMatchTypeRegex, "a.*"),
+		queryParam("param", dag.QueryParamMatchTypePresent, ""),
+	}
+
+	have := []dag.QueryParamMatchCondition{
+		want[5],
+		want[3],
+		want[0],
+		want[2],
+		want[4],
+		want[1],
+	}
+
+	sort.Stable(For(have))
+	assert.Equal(t, want, have)
+}
+
 func TestSortClusters(t *testing.T) {
 	want := []*envoy_cluster_v3.Cluster{
 		{Name: "first"},
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strconv"
 
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_service_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/service/cluster/v3"
 	envoy_service_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	envoy_service_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
@@ -37,6 +38,16 @@ type grpcStream interface {
 	Recv() (*envoy_service_discovery_v3.DiscoveryRequest, error)
 }
 
+// nodeResource is implemented by resources whose contents depend on
+// the Envoy node that requests them.
+type nodeResource interface {
+	// ContentsFor returns the contents of the resource for the node.
+	ContentsFor(node *envoy_core_v3.Node) []proto.Message
+
+	// QueryFor returns the named contents of the resource for the node.
+	QueryFor(node *envoy_core_v3.Node, names []string) []proto.Message
+}
+
 // NewContourServer creates an internally implemented Server that streams the
 // provided set of Resource objects. The returned Server implements the xDS
 // State of the World (SotW) variant.
@@ -93,6 +104,10 @@ func (s *contourServer) stream(st grpcStream) error {
 	last := -1
 	ctx := st.Context()
 
+	// Envoy only needs to identify its node in the
+	// first request of the stream.
+	var node *envoy_core_v3.Node
+
 	// now stick in this loop until the client disconnects.
 	for {
 		// first we wait for the request from Envoy, this is part of
@@ -105,6 +120,10 @@ func (s *contourServer) stream(st grpcStream) error {
 		// Note: redeclare log in this scope so the next time around the loop all is forgotten.
 		log := logDiscoveryRequestDetails(log, req)
 
+		if req.Node != nil {
+			node = req.Node
+		}
+
 		// From the request we derive the resource to stream which have
 		// been registered according to the typeURL.
 		r, ok := s.resources[req.GetTypeUrl()]
@@ -122,11 +141,16 @@ func (s *contourServer) stream(st grpcStream) error {
 			// so we're going to be sending an update that is a no-op. See #426
 
 			var resources []proto.Message
-			switch len(req.ResourceNames) {
-			case 0:
+			nr, ok := r.(nodeResource)
+			switch {
+			case len(req.ResourceNames) == 0 && ok:
+				resources = nr.ContentsFor(node)
+			case len(req.ResourceNames) == 0:
 				// no resource hints supplied, return the full
 				// contents of the resource
 				resources = r.Contents()
+			case ok:
+				resources = nr.QueryFor(node, req.ResourceNames)
 			default:
 				// resource hints supplied, return exactly those
 				resources = r.Query(req.ResourceNames)
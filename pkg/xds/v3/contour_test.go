@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"testing"
 
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	envoy_service_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	"github.com/golang/protobuf/proto"
@@ -151,6 +152,64 @@ func TestXDSHandlerStream(t *testing.T) {
 	}
 }
 
+func TestXDSHandlerStreamNode(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(ioutil.Discard)
+
+	// The node is only sent in the first request, but it is
+	// remembered for the rest of the stream.
+	requests := []*envoy_service_discovery_v3.DiscoveryRequest{{
+		TypeUrl: "io.projectcontour.potato",
+		Node:    &envoy_core_v3.Node{Id: "envoy-1"},
+	}, {
+		TypeUrl: "io.projectcontour.potato",
+	}, {
+		TypeUrl:       "io.projectcontour.potato",
+		ResourceNames: []string{"potato"},
+	}}
+
+	var nodes []string
+	xh := contourServer{
+		FieldLogger: log,
+		resources: map[string]xds.Resource{
+			"io.projectcontour.potato": &mockNodeResource{
+				mockResource: mockResource{
+					register: func(ch chan int, i int) {
+						ch <- i + 1
+					},
+					typeurl: func() string { return "io.projectcontour.potato" },
+				},
+				contentsFor: func(node *envoy_core_v3.Node) []proto.Message {
+					nodes = append(nodes, node.GetId())
+					return []proto.Message{new(envoy_endpoint_v3.ClusterLoadAssignment)}
+				},
+				queryFor: func(node *envoy_core_v3.Node, names []string) []proto.Message {
+					nodes = append(nodes, node.GetId())
+					return []proto.Message{new(envoy_endpoint_v3.ClusterLoadAssignment)}
+				},
+			},
+		},
+	}
+
+	stream := &mockStream{
+		context: context.Background,
+		recv: func() (*envoy_service_discovery_v3.DiscoveryRequest, error) {
+			if len(requests) == 0 {
+				return nil, io.EOF
+			}
+			req := requests[0]
+			requests = requests[1:]
+			return req, nil
+		},
+		send: func(resp *envoy_service_discovery_v3.DiscoveryResponse) error {
+			return nil
+		},
+	}
+
+	assert.Equal(t, io.EOF, xh.stream(stream))
+	assert.Equal(t, []string{"envoy-1", "envoy-1", "envoy-1"}, nodes)
+}
+
 type mockStream struct {
 	context func() context.Context
 	send    func(*envoy_service_discovery_v3.DiscoveryResponse) error
@@ -174,3 +233,16 @@ func (m *mockResource) Contents() []proto.Message                       { return
 func (m *mockResource) Query(names []string) []proto.Message            { return m.query(names) }
 func (m *mockResource) Register(ch chan int, last int, hints ...string) { m.register(ch, last) }
 func (m *mockResource) TypeURL() string                                 { return m.typeurl() }
+
+type mockNodeResource struct {
+	mockResource
+	contentsFor func(*envoy_core_v3.Node) []proto.Message
+	queryFor    func(*envoy_core_v3.Node, []string) []proto.Message
+}
+
+func (m *mockNodeResource) ContentsFor(node *envoy_core_v3.Node) []proto.Message {
+	return m.contentsFor(node)
+}
+func (m *mockNodeResource) QueryFor(node *envoy_core_v3.Node, names []string) []proto.Message {
+	return m.queryFor(node, names)
+}
@@ -106,6 +106,11 @@ func (v *clusterVisitor) visit(vertex dag.Vertex) {
 		if _, ok := v.clusters[name]; !ok {
 			v.clusters[name] = envoy_v3.ExtensionCluster(cluster)
 		}
+	case *dag.DNSNameCluster:
+		name := envoy.DNSNameClustername(cluster)
+		if _, ok := v.clusters[name]; !ok {
+			v.clusters[name] = envoy_v3.DNSNameCluster(cluster)
+		}
 	}
 
 	// recurse into children of v
@@ -0,0 +1,773 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	resource "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/golang/protobuf/proto"
+	"github.com/projectcontour/contour/pkg/contour"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/envoy"
+	envoy_v3 "github.com/projectcontour/contour/pkg/envoy/v3"
+	"github.com/projectcontour/contour/pkg/protobuf"
+	"github.com/projectcontour/contour/pkg/sorter"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+// RecalculateEndpointSlices generates a slice of LocalityEndpoints
+// resources by matching the given service port to the given EndpointSlices,
+// which must all belong to the same Service. Endpoints are grouped into
+// a LocalityEndpoints for each locality, sorted by region and zone. The
+// zone of an endpoint is taken from the EndpointSlice, and the region,
+// or a missing zone, from the given localities of the Kubernetes nodes
+// that endpoints run on. Endpoints without a region or zone are grouped
+// into a LocalityEndpoints with no locality.
+//
+// Ready endpoints are always used. If a port has no ready endpoints,
+// endpoints that are terminating but still serving are used instead so
+// that in-flight traffic is not dropped during a rollout.
+func RecalculateEndpointSlices(port v1.ServicePort, slices map[string]*discovery_v1.EndpointSlice, nodes map[string]*envoy_core_v3.Locality) []*LocalityEndpoints {
+	if len(slices) == 0 {
+		return nil
+	}
+
+	type address struct {
+		ip   string
+		port int32
+	}
+
+	type locality struct {
+		region string
+		zone   string
+	}
+
+	// Both maps hold the locality of each address.
+	ready := map[address]locality{}
+	terminating := map[address]locality{}
+
+	for _, s := range slices {
+		switch s.AddressType {
+		case discovery_v1.AddressTypeIPv4, discovery_v1.AddressTypeIPv6:
+		default:
+			// NOTE: we only support IP addresses.
+			continue
+		}
+
+		for _, p := range s.Ports {
+			if p.Port == nil {
+				continue
+			}
+
+			protocol := v1.ProtocolTCP
+			if p.Protocol != nil {
+				protocol = *p.Protocol
+			}
+			if port.Protocol != protocol && protocol != v1.ProtocolTCP {
+				// NOTE: we only support "TCP", which is the default.
+				continue
+			}
+
+			// If the port isn't named, it must be the
+			// only Service port, so it's a match by
+			// definition. Otherwise, only take endpoint
+			// ports that match the service port name.
+			if port.Name != "" && (p.Name == nil || port.Name != *p.Name) {
+				continue
+			}
+
+			for _, ep := range s.Endpoints {
+				// All the addresses of an endpoint are fungible,
+				// so only the first is used.
+				if len(ep.Addresses) == 0 {
+					continue
+				}
+
+				var loc locality
+				if ep.NodeName != nil {
+					if n := nodes[*ep.NodeName]; n != nil {
+						loc = locality{region: n.Region, zone: n.Zone}
+					}
+				}
+				if ep.Zone != nil {
+					loc.zone = *ep.Zone
+				}
+
+				addr := address{ip: ep.Addresses[0], port: *p.Port}
+				switch {
+				case endpointReady(ep.Conditions):
+					ready[addr] = loc
+				case endpointServing(ep.Conditions) && endpointTerminating(ep.Conditions):
+					terminating[addr] = loc
+				}
+			}
+		}
+	}
+
+	addresses := ready
+	if len(addresses) == 0 {
+		addresses = terminating
+	}
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	grouped := map[locality][]address{}
+	for a, loc := range addresses {
+		grouped[loc] = append(grouped[loc], a)
+	}
+
+	keys := make([]locality, 0, len(grouped))
+	for loc := range grouped {
+		keys = append(keys, loc)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].region == keys[j].region {
+			return keys[i].zone < keys[j].zone
+		}
+		return keys[i].region < keys[j].region
+	})
+
+	localities := make([]*LocalityEndpoints, 0, len(keys))
+	for _, loc := range keys {
+		sorted := grouped[loc]
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].ip == sorted[j].ip {
+				return sorted[i].port < sorted[j].port
+			}
+			return sorted[i].ip < sorted[j].ip
+		})
+
+		locality := &LocalityEndpoints{}
+		if loc.region != "" || loc.zone != "" {
+			locality.Locality = &envoy_core_v3.Locality{Region: loc.region, Zone: loc.zone}
+		}
+		for _, a := range sorted {
+			locality.LbEndpoints = append(locality.LbEndpoints, envoy_v3.LBEndpoint(envoy_v3.SocketAddress(a.ip, int(a.port))))
+		}
+
+		localities = append(localities, locality)
+	}
+
+	return localities
+}
+
+// nodeLocality returns the locality of a Kubernetes node from its
+// topology labels, or nil if it has none.
+func nodeLocality(node *v1.Node) *envoy_core_v3.Locality {
+	region := node.Labels[v1.LabelTopologyRegion]
+	zone := node.Labels[v1.LabelTopologyZone]
+	if region == "" && zone == "" {
+		return nil
+	}
+	return &envoy_core_v3.Locality{Region: region, Zone: zone}
+}
+
+// endpointReady returns whether the endpoint is ready. An unknown
+// state is interpreted as ready.
+func endpointReady(c discovery_v1.EndpointConditions) bool {
+	return c.Ready == nil || *c.Ready
+}
+
+// endpointServing returns whether the endpoint is serving. An unknown
+// state is interpreted as the ready state.
+func endpointServing(c discovery_v1.EndpointConditions) bool {
+	if c.Serving == nil {
+		return endpointReady(c)
+	}
+	return *c.Serving
+}
+
+// endpointTerminating returns whether the endpoint is terminating. An
+// unknown state is interpreted as not terminating.
+func endpointTerminating(c discovery_v1.EndpointConditions) bool {
+	return c.Terminating != nil && *c.Terminating
+}
+
+// EndpointSliceCache is a cache of EndpointSlice and ServiceCluster objects.
+type EndpointSliceCache struct {
+	mu sync.Mutex // Protects all fields.
+
+	// Slice of stale clusters. A stale cluster is one that
+	// needs to be recalculated. Clusters can be added to the stale
+	// slice due to changes in EndpointSlices or due to a DAG rebuild.
+	stale []*dag.ServiceCluster
+
+	// Index of ServiceClusters. ServiceClusters are indexed
+	// by the name of their Kubernetes Services. This makes it
+	// easy to determine which EndpointSlices affect which ServiceCluster.
+	services map[types.NamespacedName][]*dag.ServiceCluster
+
+	// Cache of endpoint slices, indexed by the name of their
+	// Service and then by their own name.
+	endpointSlices map[types.NamespacedName]map[string]*discovery_v1.EndpointSlice
+
+	// All the ServiceClusters, which become stale when the
+	// locality of a Kubernetes node changes.
+	clusters []*dag.ServiceCluster
+
+	// Names of the ServiceClusters that use locality failover.
+	localityFailover map[string]bool
+
+	// Localities of Kubernetes nodes, indexed by node name.
+	nodes map[string]*envoy_core_v3.Locality
+}
+
+// Recalculate regenerates all the ClusterLoadAssignments from the
+// cached EndpointSlices and stale ServiceClusters. A ClusterLoadAssignment
+// will be generated for every stale ServerCluster, however, if there
+// are no endpoints for the Services in the ServiceCluster, the
+// ClusterLoadAssignment will be empty.
+func (c *EndpointSliceCache) Recalculate() map[string]*envoy_endpoint_v3.ClusterLoadAssignment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	assignments := map[string]*envoy_endpoint_v3.ClusterLoadAssignment{}
+	for _, cluster := range c.stale {
+		// Clusters can be in the stale list multiple times;
+		// skip to avoid duplicate recalculations.
+		if _, ok := assignments[cluster.ClusterName]; ok {
+			continue
+		}
+
+		cla := envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: cluster.ClusterName,
+			Endpoints:   nil,
+			Policy:      nil,
+		}
+
+		// Look up each service, and if we have endpoints for that service,
+		// attach them as new LocalityEndpoints resources.
+		var services []weightedLocalities
+		for _, w := range cluster.Services {
+			n := types.NamespacedName{Namespace: w.ServiceNamespace, Name: w.ServiceName}
+			localities := RecalculateEndpointSlices(w.ServicePort, c.endpointSlices[n], c.nodes)
+			services = append(services, weightedLocalities{weight: w.Weight, localities: localities})
+			cla.Endpoints = append(cla.Endpoints, localities...)
+		}
+
+		divideWeights(services)
+
+		assignments[cla.ClusterName] = &cla
+	}
+
+	c.stale = nil
+	return assignments
+}
+
+// weightedLocalities holds the localities of the endpoints of a
+// service, and the load balancing weight of the service.
+type weightedLocalities struct {
+	weight     uint32
+	localities []*LocalityEndpoints
+}
+
+// maxWeightScale limits how much divideWeights scales weights up.
+const maxWeightScale = 1 << 16
+
+// divideWeights sets the load balancing weights of the localities of
+// each service so that they add up to the weight of the service, in
+// proportion to their number of endpoints. Envoy compares the weights
+// of all the localities of a priority, so setting the weight of the
+// service on each of its localities would skew the weights towards
+// services that are spread over more localities. The weights are
+// scaled to keep them integral, which does not change the proportion
+// of traffic that each locality receives.
+func divideWeights(services []weightedLocalities) {
+	endpoints := func(localities []*LocalityEndpoints) uint64 {
+		var n uint64
+		for _, l := range localities {
+			n += uint64(len(l.LbEndpoints))
+		}
+		return n
+	}
+
+	// Scale by the least common multiple of the number of endpoints
+	// of each service, so that dividing the weights is exact, unless
+	// that would be too large, in which case it is approximate.
+	scale := uint64(1)
+	for _, s := range services {
+		if n := endpoints(s.localities); n > 0 {
+			scale = scale / gcd(scale, n) * n
+			if scale > maxWeightScale {
+				scale = maxWeightScale
+				break
+			}
+		}
+	}
+
+	weights := map[*LocalityEndpoints]uint64{}
+	var divisor uint64
+	for _, s := range services {
+		n := endpoints(s.localities)
+		for _, l := range s.localities {
+			w := uint64(s.weight) * uint64(len(l.LbEndpoints)) * scale / n
+			if w == 0 && s.weight > 0 {
+				w = 1
+			}
+			weights[l] = w
+			divisor = gcd(divisor, w)
+		}
+	}
+
+	for l, w := range weights {
+		if divisor > 0 {
+			w /= divisor
+		}
+		if w > math.MaxUint32 {
+			w = math.MaxUint32
+		}
+
+		// Users are allowed to set the load balancing weight to 0,
+		// which we reflect to Envoy as nil in order to assign no
+		// load to that locality.
+		l.LoadBalancingWeight = protobuf.UInt32OrNil(uint32(w))
+	}
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// prioritize returns a copy of cla in which the priority of each
+// locality is set by its distance from the given local locality.
+// Endpoints in the local zone have the highest priority, then those
+// in the local region, then all the others, so that Envoy only fails
+// over to more distant endpoints when the nearer ones are unhealthy.
+// Envoy requires priorities to start at zero and not skip any levels,
+// so the priorities that are in use are renumbered to be contiguous.
+func prioritize(cla *envoy_endpoint_v3.ClusterLoadAssignment, local *envoy_core_v3.Locality) *envoy_endpoint_v3.ClusterLoadAssignment {
+	distance := func(l *envoy_core_v3.Locality) uint32 {
+		switch {
+		case l == nil || l.Region != local.Region:
+			return 2
+		case l.Zone == "" || l.Zone != local.Zone:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	used := map[uint32]bool{}
+	for _, l := range cla.Endpoints {
+		used[distance(l.Locality)] = true
+	}
+
+	levels := make([]uint32, 0, len(used))
+	for p := range used {
+		levels = append(levels, p)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+
+	contiguous := make(map[uint32]uint32, len(levels))
+	for i, p := range levels {
+		contiguous[p] = uint32(i)
+	}
+
+	prioritized := proto.Clone(cla).(*envoy_endpoint_v3.ClusterLoadAssignment)
+	for _, l := range prioritized.Endpoints {
+		l.Priority = contiguous[distance(l.Locality)]
+	}
+
+	return prioritized
+}
+
+// SetClusters replaces the cache of ServiceCluster resources. All
+// the added clusters will be marked stale.
+func (c *EndpointSliceCache) SetClusters(clusters []*dag.ServiceCluster) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	serviceIndex, err := indexServiceClusters(clusters)
+	if err != nil {
+		return err
+	}
+
+	localityFailover := map[string]bool{}
+	for _, cluster := range clusters {
+		if cluster.LocalityFailover {
+			localityFailover[cluster.ClusterName] = true
+		}
+	}
+
+	c.stale = clusters
+	c.clusters = clusters
+	c.services = serviceIndex
+	c.localityFailover = localityFailover
+
+	return nil
+}
+
+// UpdateNode caches the locality of the Kubernetes node. If the
+// locality changed, all the ServiceClusters become stale, and true
+// is returned.
+func (c *EndpointSliceCache) UpdateNode(node *v1.Node) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locality := nodeLocality(node)
+	if proto.Equal(locality, c.nodes[node.Name]) {
+		return false
+	}
+
+	if locality == nil {
+		delete(c.nodes, node.Name)
+	} else {
+		c.nodes[node.Name] = locality
+	}
+	c.stale = append(c.stale, c.clusters...)
+
+	return true
+}
+
+// DeleteNode deletes the locality of the Kubernetes node from the
+// cache. If it had a locality, all the ServiceClusters become stale,
+// and true is returned.
+func (c *EndpointSliceCache) DeleteNode(node *v1.Node) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.nodes[node.Name]; !ok {
+		return false
+	}
+
+	delete(c.nodes, node.Name)
+	c.stale = append(c.stale, c.clusters...)
+
+	return true
+}
+
+// envoyLocality returns the locality of the Envoy node, and the names
+// of the ServiceClusters that use locality failover. The locality is
+// the one that Envoy reports, or if it reports none, that of the
+// Kubernetes node named in its metadata. It is nil if it is unknown.
+func (c *EndpointSliceCache) envoyLocality(node *envoy_core_v3.Node) (*envoy_core_v3.Locality, map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if l := node.GetLocality(); l.GetRegion() != "" || l.GetZone() != "" {
+		return l, c.localityFailover
+	}
+
+	name := node.GetMetadata().GetFields()[envoy.KubernetesNodeMetadataKey].GetStringValue()
+	return c.nodes[name], c.localityFailover
+}
+
+// UpdateEndpointSlice adds es to the cache, or replaces it if it is
+// already cached. Any ServiceClusters that are backed by the Service
+// that es belongs to become stale. EndpointSlices that are not
+// owned by a Service are ignored.
+func (c *EndpointSliceCache) UpdateEndpointSlice(es *discovery_v1.EndpointSlice) {
+	name, ok := endpointSliceServiceName(es)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	slices := c.endpointSlices[name]
+	if slices == nil {
+		slices = map[string]*discovery_v1.EndpointSlice{}
+		c.endpointSlices[name] = slices
+	}
+	slices[es.Name] = es.DeepCopy()
+
+	// If any service clusters include this endpoint slice, mark
+	// them all as stale.
+	if affected := c.services[name]; len(affected) > 0 {
+		c.stale = append(c.stale, affected...)
+	}
+}
+
+// DeleteEndpointSlice deletes es from the cache. Any ServiceClusters
+// that are backed by the Service that es belongs to become stale.
+func (c *EndpointSliceCache) DeleteEndpointSlice(es *discovery_v1.EndpointSlice) {
+	name, ok := endpointSliceServiceName(es)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if slices := c.endpointSlices[name]; slices != nil {
+		delete(slices, es.Name)
+		if len(slices) == 0 {
+			delete(c.endpointSlices, name)
+		}
+	}
+
+	// If any service clusters include this endpoint slice, mark
+	// them all as stale.
+	if affected := c.services[name]; len(affected) > 0 {
+		c.stale = append(c.stale, affected...)
+	}
+}
+
+// endpointSliceServiceName returns the name of the Service that owns
+// the EndpointSlice, taken from its kubernetes.io/service-name label.
+func endpointSliceServiceName(es *discovery_v1.EndpointSlice) (types.NamespacedName, bool) {
+	svc := es.Labels[discovery_v1.LabelServiceName]
+	if svc == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: es.Namespace, Name: svc}, true
+}
+
+// NewEndpointSliceTranslator allocates a new endpoint slice translator.
+func NewEndpointSliceTranslator(log logrus.FieldLogger) *EndpointSliceTranslator {
+	return &EndpointSliceTranslator{
+		Cond:        contour.Cond{},
+		FieldLogger: log,
+		entries:     map[string]*envoy_endpoint_v3.ClusterLoadAssignment{},
+		cache: EndpointSliceCache{
+			stale:            nil,
+			services:         map[types.NamespacedName][]*dag.ServiceCluster{},
+			endpointSlices:   map[types.NamespacedName]map[string]*discovery_v1.EndpointSlice{},
+			localityFailover: map[string]bool{},
+			nodes:            map[string]*envoy_core_v3.Locality{},
+		},
+	}
+}
+
+// A EndpointSliceTranslator translates Kubernetes EndpointSlice objects
+// into Envoy ClusterLoadAssignment resources. The EndpointSlices of each
+// Service are merged, so xDS clients see the same resources as they
+// would from an EndpointsTranslator. Kubernetes Node objects supply the
+// localities of the endpoints, and of the Envoys that request them.
+type EndpointSliceTranslator struct {
+	// Observer notifies when the endpoints cache has been updated.
+	Observer contour.Observer
+
+	contour.Cond
+	logrus.FieldLogger
+
+	cache EndpointSliceCache
+
+	mu      sync.Mutex // Protects entries.
+	entries map[string]*envoy_endpoint_v3.ClusterLoadAssignment
+}
+
+// Merge combines the given entries with the existing entries in the
+// EndpointSliceTranslator. If the same key exists in both maps, an
+// existing entry is replaced.
+func (e *EndpointSliceTranslator) Merge(entries map[string]*envoy_endpoint_v3.ClusterLoadAssignment) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for k, v := range entries {
+		e.entries[k] = v
+	}
+}
+
+// OnChange observes DAG rebuild events.
+func (e *EndpointSliceTranslator) OnChange(d *dag.DAG) {
+	// Collect all the service clusters from the DAG.
+	clusters := serviceClusters(d, e.FieldLogger)
+
+	// Update the cache with the new clusters.
+	if err := e.cache.SetClusters(clusters); err != nil {
+		e.WithError(err).Error("failed to cache service clusters")
+	}
+
+	// Since we reset the cluster cache above, all the load
+	// assignments will be recalculated and we can just set the
+	// entries rather than merging them.
+	entries := e.cache.Recalculate()
+
+	// Only update and notify if entries has changed.
+	changed := false
+
+	e.mu.Lock()
+	if !equal(e.entries, entries) {
+		e.entries = entries
+		changed = true
+	}
+	e.mu.Unlock()
+
+	if changed {
+		e.Debug("cluster load assignments changed, notifying waiters")
+		e.Notify()
+	} else {
+		e.Debug("cluster load assignments did not change")
+	}
+}
+
+func (e *EndpointSliceTranslator) OnAdd(obj interface{}) {
+	switch obj := obj.(type) {
+	case *discovery_v1.EndpointSlice:
+		e.cache.UpdateEndpointSlice(obj)
+		e.Merge(e.cache.Recalculate())
+		e.Notify()
+		if e.Observer != nil {
+			e.Observer.Refresh()
+		}
+	case *v1.Node:
+		if e.cache.UpdateNode(obj) {
+			e.Merge(e.cache.Recalculate())
+			e.Notify()
+			if e.Observer != nil {
+				e.Observer.Refresh()
+			}
+		}
+	default:
+		e.Errorf("OnAdd unexpected type %T: %#v", obj, obj)
+	}
+}
+
+func (e *EndpointSliceTranslator) OnUpdate(oldObj, newObj interface{}) {
+	switch newObj := newObj.(type) {
+	case *discovery_v1.EndpointSlice:
+		oldObj, ok := oldObj.(*discovery_v1.EndpointSlice)
+		if !ok {
+			e.Errorf("OnUpdate endpointslice %#v received invalid oldObj %T; %#v", newObj, oldObj, oldObj)
+			return
+		}
+
+		// Skip computation if either old and new endpoint
+		// slices are equal (thus also handling nil).
+		if oldObj == newObj {
+			return
+		}
+
+		// If there are no endpoints in this object, and the old
+		// object also had zero endpoints, ignore this update
+		// to avoid sending a noop notification to watchers.
+		if len(oldObj.Endpoints) == 0 && len(newObj.Endpoints) == 0 {
+			return
+		}
+
+		e.cache.UpdateEndpointSlice(newObj)
+		e.Merge(e.cache.Recalculate())
+		e.Notify()
+		if e.Observer != nil {
+			e.Observer.Refresh()
+		}
+	case *v1.Node:
+		// Nodes are updated often, but their
+		// locality rarely changes.
+		if e.cache.UpdateNode(newObj) {
+			e.Merge(e.cache.Recalculate())
+			e.Notify()
+			if e.Observer != nil {
+				e.Observer.Refresh()
+			}
+		}
+	default:
+		e.Errorf("OnUpdate unexpected type %T: %#v", newObj, newObj)
+	}
+}
+
+func (e *EndpointSliceTranslator) OnDelete(obj interface{}) {
+	switch obj := obj.(type) {
+	case *discovery_v1.EndpointSlice:
+		e.cache.DeleteEndpointSlice(obj)
+		e.Merge(e.cache.Recalculate())
+		e.Notify()
+		if e.Observer != nil {
+			e.Observer.Refresh()
+		}
+	case *v1.Node:
+		if e.cache.DeleteNode(obj) {
+			e.Merge(e.cache.Recalculate())
+			e.Notify()
+			if e.Observer != nil {
+				e.Observer.Refresh()
+			}
+		}
+	case cache.DeletedFinalStateUnknown:
+		e.OnDelete(obj.Obj) // recurse into ourselves with the tombstoned value
+	default:
+		e.Errorf("OnDelete unexpected type %T: %#v", obj, obj)
+	}
+}
+
+// Contents returns a copy of the contents of the cache.
+func (e *EndpointSliceTranslator) Contents() []proto.Message {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	values := make([]*envoy_endpoint_v3.ClusterLoadAssignment, 0, len(e.entries))
+	for _, v := range e.entries {
+		values = append(values, v)
+	}
+
+	sort.Stable(sorter.For(values))
+	return protobuf.AsMessages(values)
+}
+
+func (e *EndpointSliceTranslator) Query(names []string) []proto.Message {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	values := make([]*envoy_endpoint_v3.ClusterLoadAssignment, 0, len(names))
+	for _, n := range names {
+		v, ok := e.entries[n]
+		if !ok {
+			e.Debugf("no cache entry for %q", n)
+			v = &envoy_endpoint_v3.ClusterLoadAssignment{
+				ClusterName: n,
+			}
+		}
+		values = append(values, v)
+	}
+
+	sort.Stable(sorter.For(values))
+	return protobuf.AsMessages(values)
+}
+
+// ContentsFor returns a copy of the contents of the cache, in which
+// the endpoints of the clusters that use locality failover are
+// prioritized by their distance from the given Envoy node.
+func (e *EndpointSliceTranslator) ContentsFor(node *envoy_core_v3.Node) []proto.Message {
+	return e.prioritizeFor(node, e.Contents())
+}
+
+// QueryFor is like Query, but the endpoints of the clusters that use
+// locality failover are prioritized by their distance from the given
+// Envoy node.
+func (e *EndpointSliceTranslator) QueryFor(node *envoy_core_v3.Node, names []string) []proto.Message {
+	return e.prioritizeFor(node, e.Query(names))
+}
+
+func (e *EndpointSliceTranslator) prioritizeFor(node *envoy_core_v3.Node, resources []proto.Message) []proto.Message {
+	local, localityFailover := e.cache.envoyLocality(node)
+	if local == nil {
+		return resources
+	}
+
+	for i, r := range resources {
+		if cla := r.(*envoy_endpoint_v3.ClusterLoadAssignment); localityFailover[cla.ClusterName] {
+			resources[i] = prioritize(cla, local)
+		}
+	}
+
+	return resources
+}
+
+func (*EndpointSliceTranslator) TypeURL() string { return resource.EndpointType }
@@ -0,0 +1,644 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	"github.com/golang/protobuf/proto"
+	_struct "github.com/golang/protobuf/ptypes/struct"
+	"github.com/projectcontour/contour/pkg/dag"
+	"github.com/projectcontour/contour/pkg/envoy"
+	envoy_v3 "github.com/projectcontour/contour/pkg/envoy/v3"
+	"github.com/projectcontour/contour/pkg/fixture"
+	"github.com/projectcontour/contour/pkg/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestRecalculateEndpointSlices(t *testing.T) {
+	tests := map[string]struct {
+		port   v1.ServicePort
+		slices []*discovery_v1.EndpointSlice
+		nodes  map[string]*envoy_core_v3.Locality
+		want   []*LocalityEndpoints
+	}{
+		"no slices": {
+			port: v1.ServicePort{},
+			want: nil,
+		},
+		"ready endpoints": {
+			port: v1.ServicePort{},
+			slices: []*discovery_v1.EndpointSlice{
+				endpointSlice("default", "simple-abc", "simple",
+					slicePorts(slicePort("", 8080)),
+					sliceEndpoint("10.0.0.2", ready),
+					sliceEndpoint("10.0.0.1", unknown),
+				),
+			},
+			want: []*LocalityEndpoints{
+				localityEndpoints("",
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.1", 8080)),
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.2", 8080)),
+				),
+			},
+		},
+		"not ready endpoints are skipped": {
+			port: v1.ServicePort{},
+			slices: []*discovery_v1.EndpointSlice{
+				endpointSlice("default", "simple-abc", "simple",
+					slicePorts(slicePort("", 8080)),
+					sliceEndpoint("10.0.0.1", ready),
+					sliceEndpoint("10.0.0.2", notReady),
+					sliceEndpoint("10.0.0.3", terminating),
+				),
+			},
+			want: []*LocalityEndpoints{
+				localityEndpoints("",
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.1", 8080)),
+				),
+			},
+		},
+		"serving terminating endpoints are used when none are ready": {
+			port: v1.ServicePort{},
+			slices: []*discovery_v1.EndpointSlice{
+				endpointSlice("default", "simple-abc", "simple",
+					slicePorts(slicePort("", 8080)),
+					sliceEndpoint("10.0.0.1", notReady),
+					sliceEndpoint("10.0.0.2", terminating),
+					sliceEndpoint("10.0.0.3", discovery_v1.EndpointConditions{
+						Ready:       pointer.BoolPtr(false),
+						Serving:     pointer.BoolPtr(false),
+						Terminating: pointer.BoolPtr(true),
+					}),
+				),
+			},
+			want: []*LocalityEndpoints{
+				localityEndpoints("",
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.2", 8080)),
+				),
+			},
+		},
+		"slices are merged and deduplicated": {
+			port: v1.ServicePort{},
+			slices: []*discovery_v1.EndpointSlice{
+				endpointSlice("default", "simple-abc", "simple",
+					slicePorts(slicePort("", 8080)),
+					sliceEndpoint("10.0.0.3", ready),
+					sliceEndpoint("10.0.0.1", ready),
+				),
+				endpointSlice("default", "simple-def", "simple",
+					slicePorts(slicePort("", 8080)),
+					sliceEndpoint("10.0.0.2", ready),
+					sliceEndpoint("10.0.0.1", ready),
+				),
+			},
+			want: []*LocalityEndpoints{
+				localityEndpoints("",
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.1", 8080)),
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.2", 8080)),
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.3", 8080)),
+				),
+			},
+		},
+		"named port": {
+			port: v1.ServicePort{Name: "https"},
+			slices: []*discovery_v1.EndpointSlice{
+				endpointSlice("default", "simple-abc", "simple",
+					slicePorts(slicePort("http", 8080), slicePort("https", 8443)),
+					sliceEndpoint("10.0.0.1", ready),
+				),
+			},
+			want: []*LocalityEndpoints{
+				localityEndpoints("",
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.1", 8443)),
+				),
+			},
+		},
+		"endpoints are grouped by zone": {
+			port: v1.ServicePort{},
+			slices: []*discovery_v1.EndpointSlice{
+				endpointSlice("default", "simple-abc", "simple",
+					slicePorts(slicePort("", 8080)),
+					zonedEndpoint("10.0.0.4", "zone-b", ready),
+					zonedEndpoint("10.0.0.3", "zone-a", ready),
+					zonedEndpoint("10.0.0.2", "zone-b", ready),
+					sliceEndpoint("10.0.0.1", ready),
+				),
+			},
+			want: []*LocalityEndpoints{
+				localityEndpoints("",
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.1", 8080)),
+				),
+				localityEndpoints("zone-a",
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.3", 8080)),
+				),
+				localityEndpoints("zone-b",
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.2", 8080)),
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.4", 8080)),
+				),
+			},
+		},
+		"endpoints are grouped by the region and zone of their node": {
+			port: v1.ServicePort{},
+			slices: []*discovery_v1.EndpointSlice{
+				endpointSlice("default", "simple-abc", "simple",
+					slicePorts(slicePort("", 8080)),
+					nodeEndpoint(sliceEndpoint("10.0.0.1", ready), "node-1"),
+					nodeEndpoint(zonedEndpoint("10.0.0.2", "zone-b", ready), "node-1"),
+					nodeEndpoint(sliceEndpoint("10.0.0.3", ready), "node-2"),
+					nodeEndpoint(sliceEndpoint("10.0.0.4", ready), "node-3"),
+				),
+			},
+			nodes: map[string]*envoy_core_v3.Locality{
+				"node-1": {Region: "region-1", Zone: "zone-a"},
+				"node-2": {Region: "region-2"},
+			},
+			want: []*LocalityEndpoints{
+				localityEndpoints("",
+					envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.4", 8080)),
+				),
+				{
+					Locality:    &envoy_core_v3.Locality{Region: "region-1", Zone: "zone-a"},
+					LbEndpoints: []*LoadBalancingEndpoint{envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.1", 8080))},
+				},
+				{
+					Locality:    &envoy_core_v3.Locality{Region: "region-1", Zone: "zone-b"},
+					LbEndpoints: []*LoadBalancingEndpoint{envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.2", 8080))},
+				},
+				{
+					Locality:    &envoy_core_v3.Locality{Region: "region-2"},
+					LbEndpoints: []*LoadBalancingEndpoint{envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.3", 8080))},
+				},
+			},
+		},
+		"fqdn slices are skipped": {
+			port: v1.ServicePort{},
+			slices: []*discovery_v1.EndpointSlice{
+				func() *discovery_v1.EndpointSlice {
+					es := endpointSlice("default", "simple-abc", "simple",
+						slicePorts(slicePort("", 8080)),
+						sliceEndpoint("example.com", ready),
+					)
+					es.AddressType = discovery_v1.AddressTypeFQDN
+					return es
+				}(),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			slices := map[string]*discovery_v1.EndpointSlice{}
+			for _, s := range tc.slices {
+				slices[s.Name] = s
+			}
+
+			got := RecalculateEndpointSlices(tc.port, slices, tc.nodes)
+			assert.Equal(t, len(tc.want), len(got))
+			for i := range tc.want {
+				protobuf.ExpectEqual(t, tc.want[i], got[i])
+			}
+		})
+	}
+}
+
+func TestEndpointSliceTranslatorQuery(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+	et.entries = clusterloadassignments(
+		envoy_v3.ClusterLoadAssignment("default/httpbin-org",
+			envoy_v3.SocketAddress("10.10.10.10", 80),
+		),
+	)
+
+	want := []proto.Message{
+		envoy_v3.ClusterLoadAssignment("default/httpbin-org",
+			envoy_v3.SocketAddress("10.10.10.10", 80),
+		),
+		envoy_v3.ClusterLoadAssignment("default/kuard/8080"),
+	}
+
+	protobuf.ExpectEqual(t, want, et.Query([]string{"default/kuard/8080", "default/httpbin-org"}))
+}
+
+func TestEndpointSliceTranslatorAddRemoveEndpointSlices(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+
+	require.NoError(t, et.cache.SetClusters([]*dag.ServiceCluster{
+		{
+			ClusterName: "default/simple",
+			Services: []dag.WeightedService{{
+				Weight:           1,
+				ServiceName:      "simple",
+				ServiceNamespace: "default",
+				ServicePort:      v1.ServicePort{},
+			}},
+		},
+	}))
+
+	es1 := endpointSlice("default", "simple-abc", "simple",
+		slicePorts(slicePort("", 8080)),
+		sliceEndpoint("192.168.183.24", ready),
+	)
+	es2 := endpointSlice("default", "simple-def", "simple",
+		slicePorts(slicePort("", 8080)),
+		sliceEndpoint("192.168.183.25", ready),
+	)
+	et.OnAdd(es1)
+	et.OnAdd(es2)
+
+	// Assert the endpoints of both slices were added.
+	want := []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints: envoy_v3.WeightedEndpoints(1,
+				envoy_v3.SocketAddress("192.168.183.24", 8080),
+				envoy_v3.SocketAddress("192.168.183.25", 8080),
+			),
+		},
+	}
+
+	protobuf.RequireEqual(t, want, et.Contents())
+
+	// es3 is the same as es1, but the endpoint is terminating.
+	es3 := endpointSlice("default", "simple-abc", "simple",
+		slicePorts(slicePort("", 8080)),
+		sliceEndpoint("192.168.183.24", terminating),
+	)
+	et.OnUpdate(es1, es3)
+
+	// Assert the terminating endpoint was removed, since
+	// there is still a ready endpoint.
+	want = []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.25", 8080)),
+		},
+	}
+
+	protobuf.RequireEqual(t, want, et.Contents())
+
+	et.OnDelete(es2)
+
+	// Assert the terminating endpoint is used once it is
+	// the only one left.
+	want = []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: "default/simple",
+			Endpoints:   envoy_v3.WeightedEndpoints(1, envoy_v3.SocketAddress("192.168.183.24", 8080)),
+		},
+	}
+
+	protobuf.RequireEqual(t, want, et.Contents())
+
+	et.OnDelete(es3)
+
+	// Assert all the endpoints are removed.
+	want = []proto.Message{
+		&envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: "default/simple"},
+	}
+
+	protobuf.RequireEqual(t, want, et.Contents())
+}
+
+func TestEndpointSliceTranslatorIgnoresUnownedSlices(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+
+	es := endpointSlice("default", "simple-abc", "",
+		slicePorts(slicePort("", 8080)),
+		sliceEndpoint("192.168.183.24", ready),
+	)
+	et.OnAdd(es)
+
+	assert.Empty(t, et.cache.endpointSlices)
+}
+
+func TestEndpointSliceTranslatorLocalityFailover(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+
+	require.NoError(t, et.cache.SetClusters([]*dag.ServiceCluster{
+		{
+			ClusterName: "default/simple",
+			Services: []dag.WeightedService{{
+				Weight:           1,
+				ServiceName:      "simple",
+				ServiceNamespace: "default",
+				ServicePort:      v1.ServicePort{},
+			}},
+			LocalityFailover: true,
+		},
+		{
+			ClusterName: "default/other",
+			Services: []dag.WeightedService{{
+				Weight:           1,
+				ServiceName:      "other",
+				ServiceNamespace: "default",
+				ServicePort:      v1.ServicePort{},
+			}},
+		},
+	}))
+
+	et.OnAdd(node("node-a", "region-1", "zone-a"))
+	et.OnAdd(node("node-b", "region-1", "zone-b"))
+	et.OnAdd(node("node-c", "region-2", "zone-c"))
+
+	for _, service := range []string{"simple", "other"} {
+		et.OnAdd(endpointSlice("default", service+"-abc", service,
+			slicePorts(slicePort("", 8080)),
+			nodeEndpoint(sliceEndpoint("192.168.183.24", ready), "node-a"),
+			nodeEndpoint(sliceEndpoint("192.168.183.25", ready), "node-b"),
+			nodeEndpoint(sliceEndpoint("192.168.183.26", ready), "node-c"),
+			sliceEndpoint("192.168.183.27", ready),
+		))
+	}
+
+	// endpoints returns the ClusterLoadAssignment of the cluster, with
+	// each locality given the corresponding priority.
+	endpoints := func(cluster string, priorities ...uint32) *envoy_endpoint_v3.ClusterLoadAssignment {
+		localities := []struct {
+			locality *envoy_core_v3.Locality
+			address  string
+		}{
+			{nil, "192.168.183.27"},
+			{&envoy_core_v3.Locality{Region: "region-1", Zone: "zone-a"}, "192.168.183.24"},
+			{&envoy_core_v3.Locality{Region: "region-1", Zone: "zone-b"}, "192.168.183.25"},
+			{&envoy_core_v3.Locality{Region: "region-2", Zone: "zone-c"}, "192.168.183.26"},
+		}
+
+		cla := &envoy_endpoint_v3.ClusterLoadAssignment{ClusterName: cluster}
+		for i, l := range localities {
+			cla.Endpoints = append(cla.Endpoints, &envoy_endpoint_v3.LocalityLbEndpoints{
+				Locality:            l.locality,
+				LbEndpoints:         []*envoy_endpoint_v3.LbEndpoint{envoy_v3.LBEndpoint(envoy_v3.SocketAddress(l.address, 8080))},
+				LoadBalancingWeight: protobuf.UInt32(1),
+				Priority:            priorities[i],
+			})
+		}
+		return cla
+	}
+
+	// Assert that the weight of the service is divided equally between
+	// its localities, and that nothing is prioritized without a node.
+	protobuf.RequireEqual(t, []proto.Message{
+		endpoints("default/other", 0, 0, 0, 0),
+		endpoints("default/simple", 0, 0, 0, 0),
+	}, et.Contents())
+
+	// Assert that the endpoints are prioritized for the Kubernetes node
+	// named in the metadata of the Envoy node, and that the endpoints
+	// of clusters without locality failover are not.
+	envoyNode := &envoy_core_v3.Node{
+		Metadata: &_struct.Struct{
+			Fields: map[string]*_struct.Value{
+				envoy.KubernetesNodeMetadataKey: {Kind: &_struct.Value_StringValue{StringValue: "node-a"}},
+			},
+		},
+	}
+	protobuf.RequireEqual(t, []proto.Message{
+		endpoints("default/other", 0, 0, 0, 0),
+		endpoints("default/simple", 2, 0, 1, 2),
+	}, et.ContentsFor(envoyNode))
+
+	// Assert that an Envoy in a region prefers that region,
+	// and that unused priorities are skipped.
+	protobuf.RequireEqual(t, []proto.Message{
+		endpoints("default/simple", 1, 1, 1, 0),
+	}, et.QueryFor(&envoy_core_v3.Node{
+		Locality: &envoy_core_v3.Locality{Region: "region-2"},
+	}, []string{"default/simple"}))
+
+	// Assert that the locality reported by Envoy takes
+	// precedence over the locality of its Kubernetes node.
+	protobuf.RequireEqual(t, []proto.Message{
+		endpoints("default/simple", 1, 1, 1, 0),
+	}, et.QueryFor(&envoy_core_v3.Node{
+		Locality: &envoy_core_v3.Locality{Region: "region-2", Zone: "zone-c"},
+		Metadata: envoyNode.Metadata,
+	}, []string{"default/simple"}))
+
+	// Assert that nothing is prioritized for an unknown node.
+	protobuf.RequireEqual(t, []proto.Message{
+		endpoints("default/simple", 0, 0, 0, 0),
+	}, et.QueryFor(&envoy_core_v3.Node{Id: "unknown"}, []string{"default/simple"}))
+
+	// Assert that the cached resources were not modified.
+	protobuf.RequireEqual(t, []proto.Message{
+		endpoints("default/simple", 0, 0, 0, 0),
+	}, et.Query([]string{"default/simple"}))
+}
+
+func TestEndpointSliceTranslatorNodes(t *testing.T) {
+	et := NewEndpointSliceTranslator(fixture.NewTestLogger(t))
+
+	require.NoError(t, et.cache.SetClusters([]*dag.ServiceCluster{
+		{
+			ClusterName: "default/simple",
+			Services: []dag.WeightedService{{
+				Weight:           1,
+				ServiceName:      "simple",
+				ServiceNamespace: "default",
+				ServicePort:      v1.ServicePort{},
+			}},
+		},
+	}))
+
+	et.OnAdd(endpointSlice("default", "simple-abc", "simple",
+		slicePorts(slicePort("", 8080)),
+		nodeEndpoint(sliceEndpoint("192.168.183.24", ready), "node-a"),
+	))
+
+	locality := func(l *envoy_core_v3.Locality) []proto.Message {
+		return []proto.Message{
+			&envoy_endpoint_v3.ClusterLoadAssignment{
+				ClusterName: "default/simple",
+				Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{{
+					Locality:            l,
+					LbEndpoints:         []*envoy_endpoint_v3.LbEndpoint{envoy_v3.LBEndpoint(envoy_v3.SocketAddress("192.168.183.24", 8080))},
+					LoadBalancingWeight: protobuf.UInt32(1),
+				}},
+			},
+		}
+	}
+
+	n1 := node("node-a", "region-1", "zone-a")
+	et.OnAdd(n1)
+	protobuf.RequireEqual(t, locality(&envoy_core_v3.Locality{Region: "region-1", Zone: "zone-a"}), et.Contents())
+
+	// Assert that updates that don't change the
+	// locality of the node don't recalculate anything.
+	n2 := n1.DeepCopy()
+	n2.Status.Phase = v1.NodeRunning
+	assert.False(t, et.cache.UpdateNode(n2))
+
+	n3 := node("node-a", "region-1", "zone-b")
+	et.OnUpdate(n2, n3)
+	protobuf.RequireEqual(t, locality(&envoy_core_v3.Locality{Region: "region-1", Zone: "zone-b"}), et.Contents())
+
+	et.OnDelete(n3)
+	protobuf.RequireEqual(t, locality(nil), et.Contents())
+}
+
+func TestDivideWeights(t *testing.T) {
+	endpoints := func(n int) *LocalityEndpoints {
+		l := &LocalityEndpoints{}
+		for i := 0; i < n; i++ {
+			l.LbEndpoints = append(l.LbEndpoints, envoy_v3.LBEndpoint(envoy_v3.SocketAddress("10.0.0.1", 8080+i)))
+		}
+		return l
+	}
+
+	weights := func(services []weightedLocalities) [][]uint32 {
+		var got [][]uint32
+		for _, s := range services {
+			var w []uint32
+			for _, l := range s.localities {
+				w = append(w, l.LoadBalancingWeight.GetValue())
+			}
+			got = append(got, w)
+		}
+		return got
+	}
+
+	tests := map[string]struct {
+		services []weightedLocalities
+		want     [][]uint32
+	}{
+		"single locality": {
+			services: []weightedLocalities{
+				{weight: 5, localities: []*LocalityEndpoints{endpoints(2)}},
+			},
+			want: [][]uint32{{1}},
+		},
+		"localities divide the weight of their service": {
+			services: []weightedLocalities{
+				{weight: 1, localities: []*LocalityEndpoints{endpoints(1), endpoints(1)}},
+				{weight: 3, localities: []*LocalityEndpoints{endpoints(3)}},
+			},
+			want: [][]uint32{{1, 1}, {6}},
+		},
+		"localities are weighted by their endpoints": {
+			services: []weightedLocalities{
+				{weight: 1, localities: []*LocalityEndpoints{endpoints(1), endpoints(3)}},
+			},
+			want: [][]uint32{{1, 3}},
+		},
+		"zero weight": {
+			services: []weightedLocalities{
+				{weight: 0, localities: []*LocalityEndpoints{endpoints(1)}},
+				{weight: 1, localities: []*LocalityEndpoints{endpoints(1)}},
+			},
+			want: [][]uint32{{0}, {1}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			divideWeights(tc.services)
+			assert.Equal(t, tc.want, weights(tc.services))
+		})
+	}
+
+	// Assert that a zero weight is nil.
+	zero := []weightedLocalities{{weight: 0, localities: []*LocalityEndpoints{endpoints(1)}}}
+	divideWeights(zero)
+	assert.Nil(t, zero[0].localities[0].LoadBalancingWeight)
+}
+
+var (
+	ready       = discovery_v1.EndpointConditions{Ready: pointer.BoolPtr(true)}
+	unknown     = discovery_v1.EndpointConditions{}
+	notReady    = discovery_v1.EndpointConditions{Ready: pointer.BoolPtr(false)}
+	terminating = discovery_v1.EndpointConditions{
+		Ready:       pointer.BoolPtr(false),
+		Serving:     pointer.BoolPtr(true),
+		Terminating: pointer.BoolPtr(true),
+	}
+)
+
+func endpointSlice(ns, name, service string, ports []discovery_v1.EndpointPort, endpoints ...discovery_v1.Endpoint) *discovery_v1.EndpointSlice {
+	es := &discovery_v1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		AddressType: discovery_v1.AddressTypeIPv4,
+		Endpoints:   endpoints,
+		Ports:       ports,
+	}
+	if service != "" {
+		es.Labels = map[string]string{discovery_v1.LabelServiceName: service}
+	}
+	return es
+}
+
+func sliceEndpoint(address string, conditions discovery_v1.EndpointConditions) discovery_v1.Endpoint {
+	return discovery_v1.Endpoint{
+		Addresses:  []string{address},
+		Conditions: conditions,
+	}
+}
+
+func zonedEndpoint(address, zone string, conditions discovery_v1.EndpointConditions) discovery_v1.Endpoint {
+	ep := sliceEndpoint(address, conditions)
+	ep.Zone = pointer.StringPtr(zone)
+	return ep
+}
+
+func nodeEndpoint(ep discovery_v1.Endpoint, node string) discovery_v1.Endpoint {
+	ep.NodeName = pointer.StringPtr(node)
+	return ep
+}
+
+func node(name, region, zone string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				v1.LabelTopologyRegion: region,
+				v1.LabelTopologyZone:   zone,
+			},
+		},
+	}
+}
+
+func localityEndpoints(zone string, lb ...*LoadBalancingEndpoint) *LocalityEndpoints {
+	l := &LocalityEndpoints{LbEndpoints: lb}
+	if zone != "" {
+		l.Locality = &envoy_core_v3.Locality{Zone: zone}
+	}
+	return l
+}
+
+func slicePorts(eps ...discovery_v1.EndpointPort) []discovery_v1.EndpointPort {
+	return eps
+}
+
+func slicePort(name string, port int32) discovery_v1.EndpointPort {
+	return discovery_v1.EndpointPort{
+		Name:     pointer.StringPtr(name),
+		Protocol: protocolPtr(v1.ProtocolTCP),
+		Port:     pointer.Int32Ptr(port),
+	}
+}
+
+func protocolPtr(p v1.Protocol) *v1.Protocol {
+	return &p
+}
@@ -151,6 +151,20 @@ func (c *EndpointsCache) SetClusters(clusters []*dag.ServiceCluster) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	serviceIndex, err := indexServiceClusters(clusters)
+	if err != nil {
+		return err
+	}
+
+	c.stale = clusters
+	c.services = serviceIndex
+
+	return nil
+}
+
+// indexServiceClusters indexes the given ServiceClusters by the
+// names of their Kubernetes Services.
+func indexServiceClusters(clusters []*dag.ServiceCluster) (map[types.NamespacedName][]*dag.ServiceCluster, error) {
 	// Keep a local index to start with so that errors don't cause
 	// partial failure.
 	serviceIndex := map[types.NamespacedName][]*dag.ServiceCluster{}
@@ -158,7 +172,7 @@ func (c *EndpointsCache) SetClusters(clusters []*dag.ServiceCluster) error {
 	// Reindex the cluster so that we can find them by service name.
 	for _, cluster := range clusters {
 		if err := cluster.Validate(); err != nil {
-			return fmt.Errorf("invalid ServiceCluster %q: %w", cluster.ClusterName, err)
+			return nil, fmt.Errorf("invalid ServiceCluster %q: %w", cluster.ClusterName, err)
 		}
 
 		// Make sure service clusters with default weights are balanced.
@@ -180,10 +194,7 @@ func (c *EndpointsCache) SetClusters(clusters []*dag.ServiceCluster) error {
 		}
 	}
 
-	c.stale = clusters
-	c.services = serviceIndex
-
-	return nil
+	return serviceIndex, nil
 }
 
 // UpdateEndpoint adds ep to the cache, or replaces it if it is
@@ -262,28 +273,8 @@ func (e *EndpointsTranslator) Merge(entries map[string]*envoy_endpoint_v3.Cluste
 
 // OnChange observes DAG rebuild events.
 func (e *EndpointsTranslator) OnChange(d *dag.DAG) {
-	clusters := []*dag.ServiceCluster{}
-	names := map[string]bool{}
-
-	var visitor func(dag.Vertex)
-	visitor = func(vertex dag.Vertex) {
-		if svc, ok := vertex.(*dag.ServiceCluster); ok {
-			if err := svc.Validate(); err != nil {
-				e.WithError(err).Errorf("dropping invalid service cluster %q", svc.ClusterName)
-			} else if _, ok := names[svc.ClusterName]; ok {
-				e.Debugf("dropping service cluster with duplicate name %q", svc.ClusterName)
-			} else {
-				e.Debugf("added ServiceCluster %q from DAG", svc.ClusterName)
-				clusters = append(clusters, svc.DeepCopy())
-				names[svc.ClusterName] = true
-			}
-		}
-
-		vertex.Visit(visitor)
-	}
-
 	// Collect all the service clusters from the DAG.
-	d.Visit(visitor)
+	clusters := serviceClusters(d, e.FieldLogger)
 
 	// Update the cache with the new clusters.
 	if err := e.cache.SetClusters(clusters); err != nil {
@@ -315,6 +306,34 @@ func (e *EndpointsTranslator) OnChange(d *dag.DAG) {
 	}
 }
 
+// serviceClusters returns copies of the valid ServiceClusters in the
+// DAG, dropping any that have duplicate names.
+func serviceClusters(d *dag.DAG, log logrus.FieldLogger) []*dag.ServiceCluster {
+	clusters := []*dag.ServiceCluster{}
+	names := map[string]bool{}
+
+	var visitor func(dag.Vertex)
+	visitor = func(vertex dag.Vertex) {
+		if svc, ok := vertex.(*dag.ServiceCluster); ok {
+			if err := svc.Validate(); err != nil {
+				log.WithError(err).Errorf("dropping invalid service cluster %q", svc.ClusterName)
+			} else if _, ok := names[svc.ClusterName]; ok {
+				log.Debugf("dropping service cluster with duplicate name %q", svc.ClusterName)
+			} else {
+				log.Debugf("added ServiceCluster %q from DAG", svc.ClusterName)
+				clusters = append(clusters, svc.DeepCopy())
+				names[svc.ClusterName] = true
+			}
+		}
+
+		vertex.Visit(visitor)
+	}
+
+	d.Visit(visitor)
+
+	return clusters
+}
+
 // equal returns true if a and b are the same length, have the same set
 // of keys, and have proto-equivalent values for each key, or false otherwise.
 func equal(a, b map[string]*envoy_endpoint_v3.ClusterLoadAssignment) bool {
@@ -131,6 +131,10 @@ type ListenerConfig struct {
 	// right side of the x-forwarded-for HTTP header to trust.
 	XffNumTrustedHops uint32
 
+	// Compression configures the response compression filters for all
+	// Connection Managers.
+	Compression config.CompressionParameters
+
 	// ConnectionBalancer
 	// The validated value is 'exact'.
 	// If no configuration is specified, Envoy will not attempt to balance active connections between worker threads
@@ -367,6 +371,7 @@ func visitListeners(root dag.Vertex, lvc *ListenerConfig) map[string]*envoy_list
 		// Add a listener if there are vhosts bound to http.
 		cm := envoy_v3.HTTPConnectionManagerBuilder().
 			Codec(envoy_v3.CodecForVersions(lv.DefaultHTTPVersions...)).
+			Compression(lvc.Compression).
 			DefaultFilters().
 			RouteConfigName(httpListener.Name).
 			MetricsPrefix(httpListener.Name).
@@ -431,6 +436,20 @@ func envoyGlobalRateLimitConfig(config *RateLimitConfig) *envoy_v3.GlobalRateLim
 	}
 }
 
+// hasRoute returns true if any route of the virtual
+// host matches the predicate.
+func hasRoute(vh dag.Vertex, predicate func(*dag.Route) bool) bool {
+	var found bool
+
+	vh.Visit(func(v dag.Vertex) {
+		if route, ok := v.(*dag.Route); ok && predicate(route) {
+			found = true
+		}
+	})
+
+	return found
+}
+
 func proxyProtocol(useProxy bool) []*envoy_listener_v3.ListenerFilter {
 	if useProxy {
 		return envoy_v3.ListenerFilters(
@@ -463,14 +482,19 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 		var filters []*envoy_listener_v3.Filter
 
 		if vh.TCPProxy == nil {
-			var authFilter *http.HttpFilter
-
-			if vh.AuthorizationService != nil {
-				authFilter = envoy_v3.FilterExternalAuthz(
-					vh.AuthorizationService.Name,
-					vh.AuthorizationFailOpen,
-					vh.AuthorizationResponseTimeout,
-				)
+			var basicAuthFilters []*http.HttpFilter
+
+			// Collect the authorization servers that routes
+			// use instead of the server of the virtual host.
+			var routeAuthorization []*dag.ExternalAuthorization
+			vh.Visit(func(v dag.Vertex) {
+				if route, ok := v.(*dag.Route); ok && route.ExternalAuthorization != nil {
+					routeAuthorization = append(routeAuthorization, route.ExternalAuthorization)
+				}
+			})
+
+			if hasRoute(vh, func(r *dag.Route) bool { return r.BasicAuth != nil }) {
+				basicAuthFilters = envoy_v3.FilterBasicAuth()
 			}
 
 			// Create a uniquely named HTTP connection manager for
@@ -483,8 +507,13 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 			cm := envoy_v3.HTTPConnectionManagerBuilder().
 				Codec(envoy_v3.CodecForVersions(v.DefaultHTTPVersions...)).
 				AddFilter(envoy_v3.FilterMisdirectedRequests(vh.VirtualHost.Name)).
+				Compression(v.ListenerConfig.Compression).
 				DefaultFilters().
-				AddFilter(authFilter).
+				AddFilter(envoy_v3.FilterClientCertificateSubjects(vh.DownstreamValidation)).
+				AddFilter(envoy_v3.FilterJWTAuthN(vh.JWTProviders)).
+				AddFilters(basicAuthFilters...).
+				AddFilters(envoy_v3.FilterExternalAuthzServers(vh.ExternalAuthorization, routeAuthorization)...).
+				LocalReplyConfig(envoy_v3.ExternalAuthzLocalReplyConfig(vh.ExternalAuthorization, routeAuthorization)).
 				RouteConfigName(path.Join("https", vh.VirtualHost.Name)).
 				MetricsPrefix(vh.ListenerName).
 				AccessLoggers(v.ListenerConfig.newSecureAccessLog()).
@@ -496,6 +525,7 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 				ConnectionShutdownGracePeriod(v.ListenerConfig.ConnectionShutdownGracePeriod).
 				AllowChunkedLength(v.ListenerConfig.AllowChunkedLength).
 				NumTrustedHops(v.ListenerConfig.XffNumTrustedHops).
+				ForwardClientCertificate(vh.DownstreamValidation.GetForwardClientCertificate()).
 				AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(v.RateLimitConfig))).
 				Get()
 
@@ -548,6 +578,7 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 				alpnProtos...)
 
 			cm := envoy_v3.HTTPConnectionManagerBuilder().
+				Compression(v.ListenerConfig.Compression).
 				DefaultFilters().
 				RouteConfigName(ENVOY_FALLBACK_ROUTECONFIG).
 				MetricsPrefix(vh.ListenerName).
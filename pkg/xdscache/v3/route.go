@@ -144,30 +144,46 @@ func (v *routeVisitor) onVirtualHost(vh *dag.VirtualHost) {
 			}
 		}
 
-		if route.DirectResponse != nil {
-			return &envoy_route_v3.Route{
-				Match:  envoy_v3.RouteMatch(route),
-				Action: envoy_v3.RouteDirectResponse(route.DirectResponse),
-			}
-		}
-
 		rt := &envoy_route_v3.Route{
-			Match:  envoy_v3.RouteMatch(route),
-			Action: envoy_v3.RouteRoute(route),
-		}
-		if route.RequestHeadersPolicy != nil {
-			rt.RequestHeadersToAdd = append(envoy_v3.HeaderValueList(route.RequestHeadersPolicy.Set, false), envoy_v3.HeaderValueList(route.RequestHeadersPolicy.Add, true)...)
-			rt.RequestHeadersToRemove = route.RequestHeadersPolicy.Remove
+			Match: envoy_v3.RouteMatch(route),
 		}
-		if route.ResponseHeadersPolicy != nil {
-			rt.ResponseHeadersToAdd = envoy_v3.HeaderValueList(route.ResponseHeadersPolicy.Set, false)
-			rt.ResponseHeadersToRemove = route.ResponseHeadersPolicy.Remove
+
+		switch {
+		case route.DirectResponse != nil:
+			rt.Action = envoy_v3.RouteDirectResponse(route.DirectResponse)
+		case route.Redirect != nil:
+			rt.Action = envoy_v3.RouteRedirect(route.Redirect)
+		default:
+			rt.Action = envoy_v3.RouteRoute(route)
+
+			if route.RequestHeadersPolicy != nil {
+				rt.RequestHeadersToAdd = append(envoy_v3.HeaderValueList(route.RequestHeadersPolicy.Set, false), envoy_v3.HeaderValueList(route.RequestHeadersPolicy.Add, true)...)
+				rt.RequestHeadersToRemove = route.RequestHeadersPolicy.Remove
+			}
+			if route.ResponseHeadersPolicy != nil {
+				rt.ResponseHeadersToAdd = envoy_v3.HeaderValueList(route.ResponseHeadersPolicy.Set, false)
+				rt.ResponseHeadersToRemove = route.ResponseHeadersPolicy.Remove
+			}
+			if route.RateLimitPolicy != nil && route.RateLimitPolicy.Local != nil {
+				if rt.TypedPerFilterConfig == nil {
+					rt.TypedPerFilterConfig = map[string]*any.Any{}
+				}
+				rt.TypedPerFilterConfig["envoy.filters.http.local_ratelimit"] = envoy_v3.LocalRateLimitConfig(route.RateLimitPolicy.Local, "vhost."+vh.Name)
+			}
+			if route.FaultInjectionPolicy != nil {
+				if rt.TypedPerFilterConfig == nil {
+					rt.TypedPerFilterConfig = map[string]*any.Any{}
+				}
+				rt.TypedPerFilterConfig["envoy.filters.http.fault"] = envoy_v3.FaultInjectionConfig(route.FaultInjectionPolicy)
+			}
 		}
-		if route.RateLimitPolicy != nil && route.RateLimitPolicy.Local != nil {
+
+		// IP filter policies apply to every kind of route.
+		if len(route.IPFilterRules) > 0 {
 			if rt.TypedPerFilterConfig == nil {
 				rt.TypedPerFilterConfig = map[string]*any.Any{}
 			}
-			rt.TypedPerFilterConfig["envoy.filters.http.local_ratelimit"] = envoy_v3.LocalRateLimitConfig(route.RateLimitPolicy.Local, "vhost."+vh.Name)
+			rt.TypedPerFilterConfig["envoy.filters.http.rbac"] = envoy_v3.IPFilterConfig(route.IPFilterAllow, route.IPFilterRules)
 		}
 		return rt
 
@@ -193,35 +209,73 @@ func (v *routeVisitor) onSecureVirtualHost(svh *dag.SecureVirtualHost) {
 	}
 
 	toEnvoyRoute := func(route *dag.Route) *envoy_route_v3.Route {
-		if route.DirectResponse != nil {
-			return &envoy_route_v3.Route{
-				Match:  envoy_v3.RouteMatch(route),
-				Action: envoy_v3.RouteDirectResponse(route.DirectResponse),
-			}
-		}
+		metadata := envoy_v3.RouteExternalAuthorization(envoy_v3.RouteBasicAuth(route.BasicAuth), route.ExternalAuthorization)
 
 		rt := &envoy_route_v3.Route{
-			Match:  envoy_v3.RouteMatch(route),
-			Action: envoy_v3.RouteRoute(route),
+			Match:    envoy_v3.RouteMatch(route),
+			Metadata: metadata,
 		}
 
-		if route.RequestHeadersPolicy != nil {
-			rt.RequestHeadersToAdd = append(envoy_v3.HeaderValueList(route.RequestHeadersPolicy.Set, false), envoy_v3.HeaderValueList(route.RequestHeadersPolicy.Add, true)...)
-			rt.RequestHeadersToRemove = route.RequestHeadersPolicy.Remove
+		switch {
+		case route.DirectResponse != nil:
+			rt.Action = envoy_v3.RouteDirectResponse(route.DirectResponse)
+		case route.Redirect != nil:
+			rt.Action = envoy_v3.RouteRedirect(route.Redirect)
+		default:
+			rt.Action = envoy_v3.RouteRoute(route)
+
+			if route.RequestHeadersPolicy != nil {
+				rt.RequestHeadersToAdd = append(envoy_v3.HeaderValueList(route.RequestHeadersPolicy.Set, false), envoy_v3.HeaderValueList(route.RequestHeadersPolicy.Add, true)...)
+				rt.RequestHeadersToRemove = route.RequestHeadersPolicy.Remove
+			}
+			if route.ResponseHeadersPolicy != nil {
+				rt.ResponseHeadersToAdd = envoy_v3.HeaderValueList(route.ResponseHeadersPolicy.Set, false)
+				rt.ResponseHeadersToRemove = route.ResponseHeadersPolicy.Remove
+			}
+			if route.RateLimitPolicy != nil && route.RateLimitPolicy.Local != nil {
+				if rt.TypedPerFilterConfig == nil {
+					rt.TypedPerFilterConfig = map[string]*any.Any{}
+				}
+				rt.TypedPerFilterConfig["envoy.filters.http.local_ratelimit"] = envoy_v3.LocalRateLimitConfig(route.RateLimitPolicy.Local, "vhost."+svh.Name)
+			}
+			if route.FaultInjectionPolicy != nil {
+				if rt.TypedPerFilterConfig == nil {
+					rt.TypedPerFilterConfig = map[string]*any.Any{}
+				}
+				rt.TypedPerFilterConfig["envoy.filters.http.fault"] = envoy_v3.FaultInjectionConfig(route.FaultInjectionPolicy)
+			}
 		}
-		if route.ResponseHeadersPolicy != nil {
-			rt.ResponseHeadersToAdd = envoy_v3.HeaderValueList(route.ResponseHeadersPolicy.Set, false)
-			rt.ResponseHeadersToRemove = route.ResponseHeadersPolicy.Remove
+
+		// IP filter policies apply to every kind of route.
+		if len(route.IPFilterRules) > 0 {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig["envoy.filters.http.rbac"] = envoy_v3.IPFilterConfig(route.IPFilterAllow, route.IPFilterRules)
 		}
-		if route.RateLimitPolicy != nil && route.RateLimitPolicy.Local != nil {
+
+		// JWT verification applies to every kind of route, so that
+		// redirects and direct responses are not served unverified.
+		if len(route.JWTProvider) > 0 {
 			if rt.TypedPerFilterConfig == nil {
 				rt.TypedPerFilterConfig = map[string]*any.Any{}
 			}
-			rt.TypedPerFilterConfig["envoy.filters.http.local_ratelimit"] = envoy_v3.LocalRateLimitConfig(route.RateLimitPolicy.Local, "vhost."+svh.Name)
+			rt.TypedPerFilterConfig[envoy_v3.JWTAuthnFilterName] = envoy_v3.RouteJWTProvider(route.JWTProvider)
+
+			// Replace any client supplied values of the
+			// headers that verified claims are forwarded in.
+			for i := range svh.JWTProviders {
+				if provider := &svh.JWTProviders[i]; provider.Name == route.JWTProvider {
+					for _, c := range provider.ClaimsToHeaders {
+						rt.RequestHeadersToRemove = append(rt.RequestHeadersToRemove, c.Header)
+					}
+					rt.RequestHeadersToAdd = append(rt.RequestHeadersToAdd, envoy_v3.JWTClaimHeaders(provider)...)
+				}
+			}
 		}
 
-		// If authorization is enabled on this host, we may need to set per-route filter overrides.
-		if svh.AuthorizationService != nil {
+		// If authorization is enabled on this host or route, we may need to set per-route filter overrides.
+		if svh.ExternalAuthorization != nil || route.ExternalAuthorization != nil {
 			// Apply per-route authorization policy modifications.
 			if route.AuthDisabled {
 				if rt.TypedPerFilterConfig == nil {
@@ -285,8 +339,9 @@ func (v *routeVisitor) visit(vertex dag.Vertex) {
 
 // sortRoutes sorts the given Route slice in place. Routes are ordered
 // first by path match type, path match value via string comparison and
-// then by the length of the HeaderMatch slice (if any). The HeaderMatch
-// slice is also ordered by the matching header name.
+// then by the length of the HeaderMatch and QueryParamMatch slices (if any).
+// The HeaderMatch and QueryParamMatch slices are also ordered by the
+// matching header or query parameter name.
 // We sort dag.Route objects before converting to Envoy types to ensure
 // more accurate ordering of route matches. Contour route match types may
 // be implemented by Envoy route match types that change over time, or by
@@ -298,6 +353,7 @@ func (v *routeVisitor) visit(vertex dag.Vertex) {
 func sortRoutes(routes []*dag.Route) {
 	for _, r := range routes {
 		sort.Stable(sorter.For(r.HeaderMatchConditions))
+		sort.Stable(sorter.For(r.QueryParamMatchConditions))
 	}
 
 	sort.Stable(sorter.For(routes))
@@ -314,6 +370,7 @@ func toEnvoyVirtualHost(vh *dag.VirtualHost, routes []*dag.Route, toEnvoyRoute f
 	if vh.CORSPolicy != nil {
 		evh.Cors = envoy_v3.CORSPolicy(vh.CORSPolicy)
 	}
+	envoy_v3.VirtualHostHeaders(evh, vh.RequestHeadersPolicy, vh.ResponseHeadersPolicy)
 	if vh.RateLimitPolicy != nil && vh.RateLimitPolicy.Local != nil {
 		if evh.TypedPerFilterConfig == nil {
 			evh.TypedPerFilterConfig = map[string]*any.Any{}
@@ -325,5 +382,12 @@ func toEnvoyVirtualHost(vh *dag.VirtualHost, routes []*dag.Route, toEnvoyRoute f
 		evh.RateLimits = envoy_v3.GlobalRateLimits(vh.RateLimitPolicy.Global.Descriptors)
 	}
 
+	if len(vh.IPFilterRules) > 0 {
+		if evh.TypedPerFilterConfig == nil {
+			evh.TypedPerFilterConfig = map[string]*any.Any{}
+		}
+		evh.TypedPerFilterConfig["envoy.filters.http.rbac"] = envoy_v3.IPFilterConfig(vh.IPFilterAllow, vh.IPFilterRules)
+	}
+
 	return evh
 }